@@ -1,38 +1,71 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var jwtKey = []byte("klucz2137")
+// Daty w tokenach zapisywane są z dokładnością do milisekundy, tak jak daty
+// w dokumentach, aby token wydany tuż po resecie hasła można było odróżnić
+// od tokenów wydanych przed nim
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
 
 type Claims struct {
 	UserID string `json:"user_id"`
 	Role   string `json:"role"`
+	// Sesja (rodzina tokenów odświeżania), w której wydano token
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID, role string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateJWT wydaje token dostępu ważny przez jwt.token_ttl z unikalnym
+// identyfikatorem (jti), który pozwala unieważnić token przed wygaśnięciem.
+// Token jest podpisywany kluczem jwt.signing_key_file (z nagłówkiem kid),
+// a gdy klucz nie jest skonfigurowany – sekretem HS256.
+func GenerateJWT(userID, role, sessionID string) (string, error) {
+	now := time.Now()
 	claims := &Claims{
-		UserID: userID,
-		Role:   role,
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        primitive.NewObjectID().Hex(),
+			Issuer:    App.JWT.Issuer,
+			Audience:  jwt.ClaimStrings{App.JWT.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(App.JWT.TokenTTL.Std())),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
+
+	if key := jwtKeys.signing; key != nil {
+		token := jwt.NewWithClaims(key.method, claims)
+		token.Header["kid"] = key.id
+		return token.SignedString(key.private)
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	return token.SignedString([]byte(App.JWT.Secret))
 }
 
+// ValidateJWT sprawdza podpis, wystawcę (iss), odbiorcę (aud) i ważność tokenu.
+// Tokeny z nagłówkiem kid weryfikowane są odpowiadającym mu kluczem publicznym,
+// tokeny bez kid – sekretem HS256, o ile jest skonfigurowany.
 func ValidateJWT(tokenStr string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, verificationKey,
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodRS256.Alg(),
+			jwt.SigningMethodEdDSA.Alg(),
+			jwt.SigningMethodHS256.Alg(),
+		}),
+		jwt.WithIssuer(App.JWT.Issuer),
+		jwt.WithAudience(App.JWT.Audience),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -41,3 +74,24 @@ func ValidateJWT(tokenStr string) (*Claims, error) {
 	}
 	return nil, fmt.Errorf("token jest nieprawidłowy lub wygasł")
 }
+
+// verificationKey dobiera klucz do tokenu; algorytm z nagłówka musi zgadzać
+// się z typem klucza, aby klucza publicznego nie dało się użyć jako sekretu HMAC
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	if kid, ok := token.Header["kid"]; ok {
+		id, _ := kid.(string)
+		key, found := jwtKeys.verification[id]
+		if !found {
+			return nil, fmt.Errorf("nieznany klucz podpisu %q", id)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("algorytm %s nie pasuje do klucza %q", token.Method.Alg(), id)
+		}
+		return key.public, nil
+	}
+
+	if token.Method.Alg() != jwt.SigningMethodHS256.Alg() || App.JWT.Secret == "" {
+		return nil, errors.New("token bez identyfikatora klucza (kid)")
+	}
+	return []byte(App.JWT.Secret), nil
+}
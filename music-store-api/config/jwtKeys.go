@@ -0,0 +1,241 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Minimalny rozmiar klucza RSA (RS256)
+const minRSAKeyBits = 2048
+
+// jwtKey to klucz asymetryczny używany do podpisu lub weryfikacji tokenów
+type jwtKey struct {
+	// Identyfikator klucza (kid): odcisk klucza publicznego wg RFC 7638
+	id     string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+	// Klucz prywatny; nil dla kluczy służących tylko do weryfikacji
+	private crypto.Signer
+}
+
+// jwtKeySet zawiera klucz podpisu i wszystkie klucze akceptowane przy weryfikacji
+type jwtKeySet struct {
+	// Klucz podpisu; nil oznacza podpis HS256 sekretem jwt.secret
+	signing      *jwtKey
+	verification map[string]*jwtKey
+}
+
+// jwtKeys przechowuje klucze wczytane przez Load
+var jwtKeys = &jwtKeySet{verification: map[string]*jwtKey{}}
+
+// JWK to klucz publiczny w formacie JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`
+	Kid string `json:"kid" example:"Xw8Dj1m2h8RzJqvH0Q6C3bDhx2Qm5fVj1Zp0aT9LwY4"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	// Parametry klucza RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Parametry klucza Ed25519
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"`
+}
+
+// JWKSet to zbiór kluczy publikowany pod /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS zwraca klucze publiczne, którymi można zweryfikować tokeny
+// wydane przez API. Przy podpisie HS256 zbiór jest pusty.
+func PublicJWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(jwtKeys.verification))}
+	for _, key := range jwtKeys.verification {
+		set.Keys = append(set.Keys, key.jwk())
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// loadJWTKeys wczytuje klucz podpisu i dodatkowe klucze weryfikacji z plików PEM.
+// Klucz podpisu jest zawsze akceptowany także przy weryfikacji.
+func loadJWTKeys(cfg JWTConfig) (*jwtKeySet, error) {
+	set := &jwtKeySet{verification: map[string]*jwtKey{}}
+	var errs []error
+
+	if cfg.SigningKeyFile != "" {
+		key, err := readSigningKey(cfg.SigningKeyFile)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			set.signing = key
+			set.verification[key.id] = key
+		}
+	}
+
+	for _, path := range cfg.VerificationKeyFiles {
+		key, err := readVerificationKey(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := set.verification[key.id]; !ok {
+			set.verification[key.id] = key
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func readSigningKey(path string) (*jwtKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	private, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("niepoprawny klucz prywatny JWT w %s: %w", path, err)
+	}
+	key, err := newJWTKey(private.Public())
+	if err != nil {
+		return nil, fmt.Errorf("niepoprawny klucz prywatny JWT w %s: %w", path, err)
+	}
+	key.private = private
+	return key, nil
+}
+
+// readVerificationKey wczytuje klucz publiczny; plik z kluczem prywatnym
+// również jest akceptowany, używana jest wtedy tylko jego część publiczna
+func readVerificationKey(path string) (*jwtKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var public crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		var private crypto.Signer
+		if private, err = parsePrivateKey(block); err == nil {
+			public = private.Public()
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("niepoprawny klucz publiczny JWT w %s: %w", path, err)
+	}
+
+	key, err := newJWTKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("niepoprawny klucz publiczny JWT w %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("nie można odczytać klucza JWT %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("plik %s nie zawiera klucza w formacie PEM", path)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("nieobsługiwany typ klucza %T", key)
+		}
+		return signer, nil
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("nieobsługiwany blok PEM %q", block.Type)
+	}
+}
+
+// newJWTKey dobiera algorytm do typu klucza: RSA → RS256, Ed25519 → EdDSA
+func newJWTKey(public crypto.PublicKey) (*jwtKey, error) {
+	key := &jwtKey{public: public}
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("klucz RSA musi mieć co najmniej %d bitów", minRSAKeyBits)
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("nieobsługiwany typ klucza %T (dozwolone: RSA, Ed25519)", public)
+	}
+	key.id = key.thumbprint()
+	return key, nil
+}
+
+// thumbprint liczy odcisk klucza wg RFC 7638, dzięki czemu ten sam klucz ma
+// ten sam kid na każdej instancji API bez dodatkowej konfiguracji
+func (k *jwtKey) thumbprint() string {
+	var members any
+	jwk := k.jwk()
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (k *jwtKey) jwk() JWK {
+	jwk := JWK{Kid: k.id, Use: "sig", Alg: k.method.Alg()}
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
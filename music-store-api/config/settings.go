@@ -0,0 +1,404 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StorageMongo  = "mongo"
+	StorageMemory = "memory"
+)
+
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverFile = "file"
+)
+
+// Minimalna długość sekretu JWT (HS256)
+const minJWTSecretLength = 16
+
+// Config zawiera pełną konfigurację aplikacji
+type Config struct {
+	// Backend danych: "mongo" lub "memory"
+	Storage  string         `yaml:"storage" toml:"storage"`
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	JWT      JWTConfig      `yaml:"jwt" toml:"jwt"`
+	Security SecurityConfig `yaml:"security" toml:"security"`
+	Trash    TrashConfig    `yaml:"trash" toml:"trash"`
+	Mail     MailConfig     `yaml:"mail" toml:"mail"`
+}
+
+// ServerConfig opisuje ustawienia serwera HTTP
+type ServerConfig struct {
+	// Adres nasłuchiwania, np. ":25565"
+	ListenAddr   string   `yaml:"listen_addr" toml:"listen_addr"`
+	ReadTimeout  Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout  Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+}
+
+// DatabaseConfig opisuje połączenie z MongoDB
+type DatabaseConfig struct {
+	URI            string   `yaml:"uri" toml:"uri"`
+	Name           string   `yaml:"name" toml:"name"`
+	ConnectTimeout Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+	// Limit czasu pojedynczej operacji wykonywanej przez kontrolery
+	QueryTimeout Duration `yaml:"query_timeout" toml:"query_timeout"`
+}
+
+// JWTConfig opisuje wydawanie tokenów JWT
+type JWTConfig struct {
+	// Sekret HS256; wymagany tylko, gdy nie skonfigurowano klucza podpisu
+	Secret string `yaml:"secret" toml:"secret"`
+	// Plik PEM z kluczem prywatnym podpisu: RSA (RS256) lub Ed25519 (EdDSA)
+	SigningKeyFile string `yaml:"signing_key_file" toml:"signing_key_file"`
+	// Pliki PEM z dodatkowymi kluczami publicznymi akceptowanymi przy weryfikacji,
+	// np. poprzednim lub następnym kluczem podpisu podczas rotacji
+	VerificationKeyFiles []string `yaml:"verification_key_files" toml:"verification_key_files"`
+	// Wystawca (iss) i odbiorca (aud) tokenów
+	Issuer   string `yaml:"issuer" toml:"issuer"`
+	Audience string `yaml:"audience" toml:"audience"`
+	// Czas ważności tokenu dostępu
+	TokenTTL Duration `yaml:"token_ttl" toml:"token_ttl"`
+	// Czas ważności tokenu odświeżania, liczony od ostatniego odświeżenia
+	RefreshTokenTTL Duration `yaml:"refresh_token_ttl" toml:"refresh_token_ttl"`
+}
+
+// SecurityConfig opisuje ustawienia haszowania haseł i tokenów wysyłanych użytkownikom
+type SecurityConfig struct {
+	BcryptCost int `yaml:"bcrypt_cost" toml:"bcrypt_cost"`
+	// Czas ważności tokenu weryfikacji adresu e-mail po rejestracji
+	VerificationTokenTTL Duration `yaml:"verification_token_ttl" toml:"verification_token_ttl"`
+	// Czas ważności tokenu resetu hasła
+	PasswordResetTokenTTL Duration `yaml:"password_reset_token_ttl" toml:"password_reset_token_ttl"`
+}
+
+// TrashConfig opisuje trwałe usuwanie dokumentów z kosza
+type TrashConfig struct {
+	// Liczba dni przechowywania dokumentów w koszu; 0 wyłącza trwałe usuwanie
+	RetentionDays int `yaml:"retention_days" toml:"retention_days"`
+	// Odstęp między kolejnymi przebiegami czyszczenia kosza
+	PurgeInterval Duration `yaml:"purge_interval" toml:"purge_interval"`
+}
+
+// Retention zwraca czas przechowywania dokumentów w koszu
+func (t TrashConfig) Retention() time.Duration {
+	return time.Duration(t.RetentionDays) * 24 * time.Hour
+}
+
+// MailConfig opisuje wysyłkę wiadomości e-mail do użytkowników
+type MailConfig struct {
+	// Sposób wysyłki: "smtp" lub "file" (zapis do katalogu i logu, do pracy lokalnej)
+	Driver string `yaml:"driver" toml:"driver"`
+	// Adres nadawcy
+	From string `yaml:"from" toml:"from"`
+	// Publiczny adres API używany w linkach w wiadomościach
+	BaseURL string `yaml:"base_url" toml:"base_url"`
+	// Katalog wiadomości sterownika file; pusty – wiadomości trafiają tylko do logu
+	Dir  string     `yaml:"dir" toml:"dir"`
+	SMTP SMTPConfig `yaml:"smtp" toml:"smtp"`
+}
+
+// SMTPConfig opisuje serwer SMTP sterownika smtp
+type SMTPConfig struct {
+	Host string `yaml:"host" toml:"host"`
+	Port int    `yaml:"port" toml:"port"`
+	// Dane logowania; puste wyłączają uwierzytelnianie
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// Duration to time.Duration zapisywany tekstowo, np. "10s", "15m", "24h"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Std zwraca wartość jako time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// App przechowuje aktualną konfigurację aplikacji, ustawianą przez Load
+var App = Default()
+
+// Default zwraca konfigurację z wartościami domyślnymi (bez sekretów)
+func Default() *Config {
+	return &Config{
+		Storage: StorageMongo,
+		Server: ServerConfig{
+			ListenAddr:   ":25565",
+			ReadTimeout:  Duration(15 * time.Second),
+			WriteTimeout: Duration(15 * time.Second),
+			IdleTimeout:  Duration(60 * time.Second),
+		},
+		Database: DatabaseConfig{
+			Name:           "PAW-API-Database",
+			ConnectTimeout: Duration(10 * time.Second),
+			QueryTimeout:   Duration(10 * time.Second),
+		},
+		JWT: JWTConfig{
+			Issuer:          "music-store-api",
+			Audience:        "music-store-api",
+			TokenTTL:        Duration(15 * time.Minute),
+			RefreshTokenTTL: Duration(30 * 24 * time.Hour),
+		},
+		Security: SecurityConfig{
+			BcryptCost:            14,
+			VerificationTokenTTL:  Duration(48 * time.Hour),
+			PasswordResetTokenTTL: Duration(time.Hour),
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
+			PurgeInterval: Duration(time.Hour),
+		},
+		Mail: MailConfig{
+			Driver:  MailDriverFile,
+			From:    "no-reply@example.com",
+			BaseURL: "http://localhost:25565",
+			SMTP: SMTPConfig{
+				Port: 587,
+			},
+		},
+	}
+}
+
+// Load wczytuje konfigurację: wartości domyślne, następnie opcjonalny plik
+// YAML/TOML, a na końcu zmienne środowiskowe. Po poprawnej walidacji
+// konfiguracja jest zapisywana w App.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	keys, err := loadJWTKeys(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+
+	App = cfg
+	jwtKeys = keys
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("nie można odczytać pliku konfiguracji %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("nieobsługiwany format pliku konfiguracji %s (dozwolone: .yaml, .yml, .toml)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("błąd dekodowania pliku konfiguracji %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	envString(&c.Storage, "STORAGE_BACKEND")
+	envString(&c.Server.ListenAddr, "LISTEN_ADDR")
+	errs = append(errs,
+		envDuration(&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT"),
+		envDuration(&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT"),
+		envDuration(&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT"),
+	)
+
+	envString(&c.Database.URI, "MONGO_URI")
+	envString(&c.Database.Name, "MONGO_DATABASE")
+	errs = append(errs,
+		envDuration(&c.Database.ConnectTimeout, "MONGO_CONNECT_TIMEOUT"),
+		envDuration(&c.Database.QueryTimeout, "MONGO_QUERY_TIMEOUT"),
+	)
+
+	envString(&c.JWT.Secret, "JWT_SECRET")
+	envString(&c.JWT.SigningKeyFile, "JWT_SIGNING_KEY_FILE")
+	envList(&c.JWT.VerificationKeyFiles, "JWT_VERIFICATION_KEY_FILES")
+	envString(&c.JWT.Issuer, "JWT_ISSUER")
+	envString(&c.JWT.Audience, "JWT_AUDIENCE")
+	errs = append(errs,
+		envDuration(&c.JWT.TokenTTL, "JWT_TOKEN_TTL"),
+		envDuration(&c.JWT.RefreshTokenTTL, "JWT_REFRESH_TOKEN_TTL"),
+		envInt(&c.Security.BcryptCost, "BCRYPT_COST"),
+		envDuration(&c.Security.VerificationTokenTTL, "VERIFICATION_TOKEN_TTL"),
+		envDuration(&c.Security.PasswordResetTokenTTL, "PASSWORD_RESET_TOKEN_TTL"),
+		envInt(&c.Trash.RetentionDays, "TRASH_RETENTION_DAYS"),
+		envDuration(&c.Trash.PurgeInterval, "TRASH_PURGE_INTERVAL"),
+	)
+
+	envString(&c.Mail.Driver, "MAIL_DRIVER")
+	envString(&c.Mail.From, "MAIL_FROM")
+	envString(&c.Mail.BaseURL, "MAIL_BASE_URL")
+	envString(&c.Mail.Dir, "MAIL_DIR")
+	envString(&c.Mail.SMTP.Host, "SMTP_HOST")
+	envString(&c.Mail.SMTP.Username, "SMTP_USERNAME")
+	envString(&c.Mail.SMTP.Password, "SMTP_PASSWORD")
+	errs = append(errs, envInt(&c.Mail.SMTP.Port, "SMTP_PORT"))
+
+	return errors.Join(errs...)
+}
+
+// Validate sprawdza kompletność i poprawność konfiguracji
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Storage {
+	case StorageMongo:
+		if c.Database.URI == "" {
+			errs = append(errs, errors.New("brak adresu MongoDB (MONGO_URI lub database.uri)"))
+		}
+		if c.Database.Name == "" {
+			errs = append(errs, errors.New("brak nazwy bazy danych (MONGO_DATABASE lub database.name)"))
+		}
+	case StorageMemory:
+	default:
+		errs = append(errs, fmt.Errorf("nieznany backend danych %q (dozwolone: %s, %s)", c.Storage, StorageMongo, StorageMemory))
+	}
+
+	if c.JWT.Secret == "" && c.JWT.SigningKeyFile == "" {
+		errs = append(errs, errors.New("brak klucza podpisu JWT (JWT_SIGNING_KEY_FILE lub jwt.signing_key_file) ani sekretu (JWT_SECRET lub jwt.secret)"))
+	} else if c.JWT.Secret != "" && len(c.JWT.Secret) < minJWTSecretLength {
+		errs = append(errs, fmt.Errorf("sekret JWT musi mieć co najmniej %d znaków", minJWTSecretLength))
+	}
+	if c.JWT.Issuer == "" {
+		errs = append(errs, errors.New("brak wystawcy tokenów (JWT_ISSUER lub jwt.issuer)"))
+	}
+	if c.JWT.Audience == "" {
+		errs = append(errs, errors.New("brak odbiorcy tokenów (JWT_AUDIENCE lub jwt.audience)"))
+	}
+
+	if c.Server.ListenAddr == "" {
+		errs = append(errs, errors.New("brak adresu nasłuchiwania (LISTEN_ADDR lub server.listen_addr)"))
+	}
+	if c.Security.BcryptCost < bcrypt.MinCost || c.Security.BcryptCost > bcrypt.MaxCost {
+		errs = append(errs, fmt.Errorf("koszt bcrypt musi być w zakresie %d-%d", bcrypt.MinCost, bcrypt.MaxCost))
+	}
+	if c.Trash.RetentionDays < 0 {
+		errs = append(errs, errors.New("trash.retention_days nie może być ujemne"))
+	}
+
+	switch c.Mail.Driver {
+	case MailDriverSMTP:
+		if c.Mail.SMTP.Host == "" {
+			errs = append(errs, errors.New("brak serwera SMTP (SMTP_HOST lub mail.smtp.host)"))
+		}
+		if c.Mail.SMTP.Port < 1 || c.Mail.SMTP.Port > 65535 {
+			errs = append(errs, errors.New("mail.smtp.port musi być w zakresie 1-65535"))
+		}
+	case MailDriverFile:
+	default:
+		errs = append(errs, fmt.Errorf("nieznany sposób wysyłki poczty %q (dozwolone: %s, %s)", c.Mail.Driver, MailDriverSMTP, MailDriverFile))
+	}
+	if c.Mail.From == "" {
+		errs = append(errs, errors.New("brak adresu nadawcy poczty (MAIL_FROM lub mail.from)"))
+	}
+	if c.Mail.BaseURL == "" {
+		errs = append(errs, errors.New("brak publicznego adresu API (MAIL_BASE_URL lub mail.base_url)"))
+	}
+
+	durations := []struct {
+		name  string
+		value Duration
+	}{
+		{"jwt.token_ttl", c.JWT.TokenTTL},
+		{"jwt.refresh_token_ttl", c.JWT.RefreshTokenTTL},
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"database.connect_timeout", c.Database.ConnectTimeout},
+		{"database.query_timeout", c.Database.QueryTimeout},
+		{"trash.purge_interval", c.Trash.PurgeInterval},
+		{"security.verification_token_ttl", c.Security.VerificationTokenTTL},
+		{"security.password_reset_token_ttl", c.Security.PasswordResetTokenTTL},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s musi być dodatnie", d.name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func envString(target *string, name string) {
+	if value, ok := os.LookupEnv(name); ok {
+		*target = value
+	}
+}
+
+// envList wczytuje listę wartości rozdzielonych przecinkami
+func envList(target *[]string, name string) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return
+	}
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*target = list
+}
+
+func envDuration(target *Duration, name string) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	if err := target.UnmarshalText([]byte(value)); err != nil {
+		return fmt.Errorf("niepoprawna wartość %s=%q: %w", name, value, err)
+	}
+	return nil
+}
+
+func envInt(target *int, name string) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("niepoprawna wartość %s=%q: %w", name, value, err)
+	}
+	*target = parsed
+	return nil
+}
@@ -1,27 +1,27 @@
 package controllers
 
 import (
-	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
-	"strconv"
+	"regexp"
 	"strings"
 	"time"
 
-	"music-store-api/config"
 	"music-store-api/models"
+	"music-store-api/store"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// Kolekcja albumów z MongoDB
-var albumCollection *mongo.Collection
+// Repozytorium albumów
+var albumStore store.AlbumStore
 
-func InitAlbumCollection() {
-	albumCollection = config.DB.Collection("albums")
+// InitAlbumStore ustawia repozytorium albumów używane przez kontroler
+func InitAlbumStore(s store.AlbumStore) {
+	albumStore = s
 }
 
 // GetAlbums godoc
@@ -30,78 +30,120 @@ func InitAlbumCollection() {
 // @Tags Albums
 // @Accept json
 // @Produce json
-// @Param page query int false "Numer strony (domyślnie 1)"
-// @Param limit query int false "Liczba wyników na stronę (domyślnie 10)"
-// @Param artist query string false "Filtruj po wykonawcy (częściowa zgodność, bez wielkości liter)"
-// @Param genre query string false "Filtruj po gatunku muzycznym (częściowa zgodność, bez wielkości liter)"
-// @Param sort query string false "Sortowanie po polach (np. price,-title)"
-// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page, limit, total i data (lista albumów)"
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param artist query string false "Filtruj po wykonawcy (częściowa zgodność, bez wielkości liter; kilka wartości po przecinku)"
+// @Param genre query string false "Filtruj po gatunku muzycznym (częściowa zgodność, bez wielkości liter; kilka wartości po przecinku)"
+// @Param decade query string false "Filtruj po dekadzie wydania, np. 1980 (kilka wartości po przecinku)"
+// @Param price_range query string false "Filtruj po przedziale cenowym, np. 20-30 lub 50+ (kilka wartości po przecinku)"
+// @Param artist_id query string false "ID wykonawców (kilka wartości po przecinku)"
+// @Param match query string false "Sposób dopasowania artist i genre: partial (domyślnie, zawiera) lub exact (równa się)" Enums(partial, exact)
+// @Param min_price query number false "Minimalna cena (włącznie)"
+// @Param max_price query number false "Maksymalna cena (włącznie)"
+// @Param released_after query string false "Najwcześniejsza data wydania (włącznie), RRRR-MM-DD lub RFC 3339"
+// @Param released_before query string false "Najpóźniejsza data wydania (włącznie), RRRR-MM-DD lub RFC 3339"
+// @Param format query string false "Filtruj po formacie wersji: vinyl, cd, cassette, digital (kilka wartości po przecinku); z in_stock – dostępne w danym formacie"
+// @Param in_stock query bool false "Tylko albumy dostępne w magazynie"
+// @Param facets query bool false "Dołącz liczności albumów dla gatunków, wykonawców, dekad i przedziałów cenowych"
+// @Param sort query string false "Sortowanie po polach (np. price,-title); dozwolone pola: title, artist, genre, price, quantity, release_date, created_at, updated_at"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista albumów), next_cursor i prev_cursor (null, gdy strona nie istnieje) oraz opcjonalnie facets (models.AlbumFacets)"
+// @Failure 400 {object} models.ValidationErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /albums [get]
 func GetAlbums(c *gin.Context) {
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
-	}
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	if err != nil || limit < 1 {
-		limit = 10
-	}
-	artist := c.Query("artist")
-	genre := c.Query("genre")
-	sort := c.DefaultQuery("sort", "")
-
-	filter := bson.M{}
-	if artist != "" {
-		filter["artist"] = bson.M{"$regex": artist, "$options": "i"}
-	}
-	if genre != "" {
-		filter["genre"] = bson.M{"$regex": genre, "$options": "i"}
-	}
-
-	findOptions := options.Find()
-	if sort != "" {
-		sortFields := bson.D{}
-		for _, field := range strings.Split(sort, ",") {
-			direction := 1
-			if strings.HasPrefix(field, "-") {
-				direction = -1
-				field = field[1:]
-			}
-			sortFields = append(sortFields, bson.E{Key: field, Value: direction})
-		}
-		findOptions.SetSort(sortFields)
-	}
+	listAlbums(c, store.AlbumFilter{})
+}
 
-	findOptions.SetSkip(int64((page - 1) * limit))
-	findOptions.SetLimit(int64(limit))
+// listAlbums odsyła stronę albumów spełniających filtry z parametrów
+// zapytania, zawężonych do wykonawców z scope
+func listAlbums(c *gin.Context, scope store.AlbumFilter) {
+	errs := &validationError{}
+	filter := albumFilterFromQuery(c, errs)
+	if len(scope.ArtistIDs) > 0 {
+		filter.ArtistIDs = scope.ArtistIDs
+	}
+	lp := parseListQuery(c, albumSortFields, errs)
+	if err := errs.errOrNil(); err != nil {
+		respondError(c, err, "Błąd pobierania albumów")
+		return
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	cursor, err := albumCollection.Find(ctx, filter, findOptions)
+	response, err := fetchPage(lp,
+		func(opts store.ListOptions) ([]models.Album, error) { return albumStore.List(ctx, filter, opts) },
+		func() (int64, error) { return albumStore.Count(ctx, filter) })
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania albumów"})
 		return
 	}
-	defer cursor.Close(ctx)
+	if c.Query("facets") == "true" {
+		facets, err := albumStore.Facets(ctx, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd wyliczania facetów"})
+			return
+		}
+		response["facets"] = facets
+	}
 
-	var albums []models.Album
-	if err = cursor.All(ctx, &albums); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dekodowania albumów"})
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchAlbums godoc
+// @Summary Wyszukaj albumy
+// @Description Wyszukiwanie pełnotekstowe w tytule, wykonawcy, opisie i utworach albumów. Wielkość liter i znaki diakrytyczne są pomijane (np. "Łódź" znajduje "Lodz"). Wyniki sortowane są według trafności.
+// @Tags Albums
+// @Produce json
+// @Param q query string true "Szukana fraza"
+// @Param page query int false "Numer strony (domyślnie 1)"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10)"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: query, page, limit, total i data (lista albumów z polem score)"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /albums/search [get]
+func SearchAlbums(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parametr q jest wymagany"})
 		return
 	}
+	page, limit := parsePaging(c)
 
-	total, _ := albumCollection.CountDocuments(ctx, filter)
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	results, total, err := albumStore.Search(ctx, query, store.ListOptions{
+		Skip:  int64((page - 1) * limit),
+		Limit: int64(limit),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd wyszukiwania albumów"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
+		"query": query,
 		"page":  page,
 		"limit": limit,
 		"total": total,
-		"data":  albums,
+		"data":  results,
 	})
 }
 
+// albumSortFields określa pola, po których można sortować albumy (nazwa w API → pole w dokumencie)
+var albumSortFields = map[string]string{
+	"title":        "title",
+	"artist":       "artist",
+	"genre":        "genre",
+	"price":        "price",
+	"quantity":     "quantity",
+	"release_date": "release_date",
+	"created_at":   "created_at",
+	"updated_at":   "updated_at",
+}
+
 // GetAlbumByID godoc
 // @Summary Pobierz album po ID
 // @Description Zwraca szczegóły albumu na podstawie ID
@@ -109,7 +151,10 @@ func GetAlbums(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID albumu"
+// @Param If-None-Match header string false "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304"
 // @Success 200 {object} models.Album
+// @Header 200 {string} ETag "Wersja zasobu"
+// @Success 304 "Zasób nie zmienił się"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /albums/{id} [get]
@@ -122,29 +167,33 @@ func GetAlbumByID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	var album models.Album
-	err = albumCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&album)
-	if err != nil {
+	album, err := albumStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Album nie znaleziony"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania albumu"})
+		return
+	}
 
-	c.JSON(http.StatusOK, album)
+	respondVersioned(c, album.Version, album)
 }
 
 // CreateAlbum godoc
 // @Summary Dodaj nowy album
 // @Security BearerAuth
-// @Description Dodaje album do bazy danych
+// @Description Dodaje album do bazy danych. Album przypisywany jest do wykonawcy artist_id, a bez niego – do wykonawcy o nazwie lub aliasie z pola artist (brakujący wykonawca jest tworzony). Album bez listy variants otrzymuje jedną wersję CD z ceną price i liczbą sztuk quantity; cena i liczba sztuk albumu z wersjami wyliczane są z wersji.
 // @Tags Albums
 // @Accept json
 // @Produce json
 // @Param album body models.Album true "Album do dodania"
 // @Success 201 {object} models.Album
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Kod SKU używany przez inny album"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /albums [post]
 func CreateAlbum(c *gin.Context) {
@@ -154,15 +203,29 @@ func CreateAlbum(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
 		return
 	}
+	if err := prepareNewAlbum(&album); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	if err := linkAlbumArtist(ctx, &album); err != nil {
+		respondError(c, err, "Błąd tworzenia albumu")
+		return
+	}
 
 	album.ID = primitive.NewObjectID()
 	album.CreatedAt = time.Now()
 	album.UpdatedAt = time.Now()
+	album.Version = 1
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	_, err := albumCollection.InsertOne(ctx, album)
+	err := albumStore.Create(ctx, album)
+	if errors.Is(err, store.ErrDuplicate) {
+		respondError(c, errDuplicateSKU, "Błąd tworzenia albumu")
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd tworzenia albumu"})
 		return
@@ -181,6 +244,7 @@ func CreateAlbum(c *gin.Context) {
 // @Param albums body []models.Album true "Lista albumów do dodania"
 // @Success 201 {object} map[string]interface{} "Informacja o dodanych albumach i ich liczbie"
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Kod SKU używany przez inny album"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /albums/bulk [post]
 func CreateAlbumsBulk(c *gin.Context) {
@@ -191,19 +255,37 @@ func CreateAlbumsBulk(c *gin.Context) {
 		return
 	}
 
-	var docs []interface{}
-	now := time.Now()
-	for _, album := range albums {
-		album.ID = primitive.NewObjectID()
-		album.CreatedAt = now
-		album.UpdatedAt = now
-		docs = append(docs, album)
+	for i := range albums {
+		if err := prepareNewAlbum(&albums[i]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Album %d: %v", i+1, err)})
+			return
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	_, err := albumCollection.InsertMany(ctx, docs)
+	now := time.Now()
+	for i := range albums {
+		if err := linkAlbumArtist(ctx, &albums[i]); err != nil {
+			var reqErr *requestError
+			if errors.As(err, &reqErr) {
+				err = &requestError{reqErr.status, fmt.Sprintf("Album %d: %s", i+1, reqErr.message)}
+			}
+			respondError(c, err, "Błąd przy dodawaniu albumów")
+			return
+		}
+		albums[i].ID = primitive.NewObjectID()
+		albums[i].CreatedAt = now
+		albums[i].UpdatedAt = now
+		albums[i].Version = 1
+	}
+
+	err := albumStore.CreateMany(ctx, albums)
+	if errors.Is(err, store.ErrDuplicate) {
+		respondError(c, errDuplicateSKU, "Błąd przy dodawaniu albumów")
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd przy dodawaniu albumów"})
 		return
@@ -215,15 +297,22 @@ func CreateAlbumsBulk(c *gin.Context) {
 // UpdateAlbum godoc
 // @Summary Zaktualizuj album
 // @Security BearerAuth
-// @Description Aktualizuje dane albumu na podstawie ID
+// @Description Częściowo aktualizuje album. Treść application/merge-patch+json (lub application/json) zmienia tylko podane pola, a null usuwa pola opcjonalne (description, tracks, cover_url). Pole variants zastępuje całą listę wersji – wersje bez id zachowują ID istniejącej wersji o tym samym SKU. Pola price i quantity można zmieniać tylko w albumie z jedną wersją. Treść application/json-patch+json to lista operacji JSON Patch (RFC 6902).
 // @Tags Albums
 // @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
 // @Produce json
 // @Param id path string true "ID albumu"
-// @Param album body models.Album true "Zaktualizowane dane albumu"
-// @Success 200 {object} models.SuccessResponse
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
+// @Param album body models.Album true "Zmieniane pola albumu (merge patch) lub lista operacji JSON Patch"
+// @Success 200 {object} models.Album
+// @Header 200 {string} ETag "Nowa wersja zasobu"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /albums/{id} [patch]
 func UpdateAlbum(c *gin.Context) {
@@ -234,50 +323,271 @@ func UpdateAlbum(c *gin.Context) {
 		return
 	}
 
-	var album models.Album
-	if err := c.ShouldBindJSON(&album); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	current, err := albumStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Album nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania albumu"})
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	if err := checkIfMatch(c, current.Version); err != nil {
+		respondError(c, err, "Błąd aktualizacji albumu")
+		return
+	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"title":      album.Title,
-			"artist":     album.Artist,
-			"price":      album.Price,
-			"genre":      album.Genre,
-			"quantity":   album.Quantity,
-			"updated_at": time.Now(),
-		},
+	var album models.Album
+	changed, err := applyPatch(c, current, &album, albumPatchFields)
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji albumu")
+		return
+	}
+	if album.Price < 0 || album.Quantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cena i ilość albumu nie mogą być ujemne"})
+		return
+	}
+	if err := validateTracks(album.Tracks); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if containsField(changed, "price") || containsField(changed, "quantity") {
+		// Cena i liczba sztuk albumu z jedną wersją są skrótem do pól tej wersji
+		if containsField(changed, "variants") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Pól price i quantity nie można zmieniać razem z variants"})
+			return
+		}
+		if len(album.Variants) != 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cenę i liczbę sztuk albumu z kilkoma wersjami zmienia się w polu variants"})
+			return
+		}
+		album.Variants[0].Price = album.Price
+		album.Variants[0].Stock = album.Quantity
+		changed = withFields(changed, "variants")
+	}
+	if containsField(changed, "variants") {
+		prepareVariants(album.Variants, current.Variants)
+		if err := validateVariants(album.Variants); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if containsField(changed, "artist") || containsField(changed, "artist_id") {
+		// Zmiana samej nazwy wyszukuje wykonawcę od nowa
+		if !containsField(changed, "artist_id") {
+			album.ArtistID = primitive.NilObjectID
+		}
+		if err := linkAlbumArtist(ctx, &album); err != nil {
+			respondError(c, err, "Błąd aktualizacji albumu")
+			return
+		}
+		changed = withFields(changed, "artist", "artist_id")
+	}
+	if len(changed) == 0 {
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusOK, current)
+		return
 	}
 
-	result, err := albumCollection.UpdateByID(ctx, objID, update)
+	album.UpdatedAt = time.Now()
+	update, err := patchUpdate(album, changed, albumPatchFields)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji albumu"})
 		return
 	}
-	if result.MatchedCount == 0 {
+	update["updated_at"] = album.UpdatedAt
+
+	err = albumStore.Update(ctx, objID, current.Version, update)
+	if errors.Is(err, store.ErrConflict) {
+		respondError(c, versionConflict(c), "Błąd aktualizacji albumu")
+		return
+	}
+	if errors.Is(err, store.ErrDuplicate) {
+		respondError(c, errDuplicateSKU, "Błąd aktualizacji albumu")
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Album nie znaleziony"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji albumu"})
+		return
+	}
+
+	album.Version = current.Version + 1
+	album.TotalDuration = album.Tracks.TotalDuration()
+	album.Price = album.LowestPrice()
+	album.Quantity = album.TotalStock()
+	c.Header("ETag", etag(album.Version))
+	c.JSON(http.StatusOK, album)
+}
+
+// Pola albumu, które można zmienić przez PATCH (klucz JSON → pole w dokumencie)
+var albumPatchFields = map[string]patchField{
+	"title":        {bson: "title"},
+	"artist":       {bson: "artist"},
+	"artist_id":    {bson: "artist_id"},
+	"genre":        {bson: "genre"},
+	"description":  {bson: "description", optional: true},
+	"release_date": {bson: "release_date"},
+	"tracks":       {bson: "tracks", optional: true},
+	"variants":     {bson: "variants"},
+	"price":        {bson: "price"},
+	"quantity":     {bson: "quantity"},
+	"cover_url":    {bson: "cover_url", optional: true},
+}
+
+// errDuplicateSKU zwracany jest, gdy kod SKU wersji używa już inny album
+var errDuplicateSKU = &requestError{http.StatusConflict, "Kod SKU wersji jest już używany przez inny album"}
+
+// prepareNewAlbum sprawdza utwory nowego albumu i przygotowuje jego wersje.
+// Album bez wersji otrzymuje jedną wersję CD z ceną price i liczbą sztuk quantity.
+func prepareNewAlbum(album *models.Album) error {
+	if err := validateTracks(album.Tracks); err != nil {
+		return err
+	}
+	if len(album.Variants) == 0 {
+		album.Variants = []models.Variant{models.DefaultVariant(*album)}
+	}
+	// ID wersji nowego albumu nadaje serwer
+	for i := range album.Variants {
+		album.Variants[i].ID = primitive.NilObjectID
+	}
+	prepareVariants(album.Variants, nil)
+	return validateVariants(album.Variants)
+}
+
+// prepareVariants ujednolica zapis formatów, kodów SKU i EAN oraz nadaje ID
+// wersjom bez niego: ID wersji z existing o tym samym SKU (aby zamówienia
+// nadal wskazywały tę wersję) albo nowe ID. Wersje bez SKU otrzymują domyślny kod.
+func prepareVariants(variants, existing []models.Variant) {
+	for i := range variants {
+		variant := &variants[i]
+		variant.Format = strings.ToLower(strings.TrimSpace(variant.Format))
+		variant.SKU = strings.ToUpper(strings.TrimSpace(variant.SKU))
+		variant.EAN = strings.NewReplacer(" ", "", "-", "").Replace(variant.EAN)
+		if variant.ID.IsZero() {
+			variant.ID = primitive.NewObjectID()
+			for _, previous := range existing {
+				if variant.SKU != "" && previous.SKU == variant.SKU {
+					variant.ID = previous.ID
+				}
+			}
+		}
+		if variant.SKU == "" {
+			variant.SKU = models.DefaultSKU(variant.Format, variant.ID)
+		}
+	}
+}
+
+// Kod SKU: wielkie litery, cyfry i myślniki
+var skuPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9-]{0,39}$`)
+
+// validateVariants sprawdza wersje albumu: co najmniej jedna wersja, znany
+// format, poprawne SKU i EAN, nieujemne ceny, stany i wagi, brak wagi wersji
+// cyfrowej oraz brak powtórzeń SKU i ID
+func validateVariants(variants []models.Variant) error {
+	if len(variants) == 0 {
+		return errors.New("Album musi mieć co najmniej jedną wersję")
+	}
+	skus := map[string]bool{}
+	ids := map[primitive.ObjectID]bool{}
+	for i, variant := range variants {
+		n := i + 1
+		switch {
+		case !models.IsValidFormat(variant.Format):
+			return fmt.Errorf("Wersja %d: niepoprawny format %q (dozwolone: %s)", n, variant.Format, strings.Join(models.Formats, ", "))
+		case !skuPattern.MatchString(variant.SKU):
+			return fmt.Errorf("Wersja %d: kod SKU może zawierać tylko litery, cyfry i myślniki (do 40 znaków)", n)
+		case variant.EAN != "" && !isValidEAN13(variant.EAN):
+			return fmt.Errorf("Wersja %d: niepoprawny kod EAN-13 %q", n, variant.EAN)
+		case variant.Price < 0 || variant.Stock < 0:
+			return fmt.Errorf("Wersja %d: cena i liczba sztuk nie mogą być ujemne", n)
+		case variant.Weight < 0:
+			return fmt.Errorf("Wersja %d: waga nie może być ujemna", n)
+		case variant.Format == models.FormatDigital && variant.Weight != 0:
+			return fmt.Errorf("Wersja %d: wersja cyfrowa nie ma wagi", n)
+		case skus[variant.SKU]:
+			return fmt.Errorf("Wersja %d: kod SKU %s powtarza się w albumie", n, variant.SKU)
+		case ids[variant.ID]:
+			return fmt.Errorf("Wersja %d: ID %s powtarza się w albumie", n, variant.ID.Hex())
+		}
+		skus[variant.SKU] = true
+		ids[variant.ID] = true
+	}
+	return nil
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Album zaktualizowany"})
+// isValidEAN13 sprawdza długość i cyfrę kontrolną kodu EAN-13
+func isValidEAN13(code string) bool {
+	if len(code) != 13 {
+		return false
+	}
+	sum := 0
+	for i, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+		digit := int(r - '0')
+		if i == 12 {
+			return (sum+digit)%10 == 0
+		}
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return false
+}
+
+// Format kodu ISRC: kraj, kod rejestrującego, rok i numer nagrania
+var isrcPattern = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{3}[0-9]{7}$`)
+
+// validateTracks sprawdza utwory albumu: tytuł, dodatnie numery płyty
+// i pozycji bez powtórzeń, nieujemny czas trwania oraz format ISRC
+func validateTracks(tracks models.TrackList) error {
+	type slot struct{ disc, position int }
+	seen := map[slot]bool{}
+	for i, track := range tracks {
+		n := i + 1
+		switch {
+		case strings.TrimSpace(track.Title) == "":
+			return fmt.Errorf("Utwór %d: tytuł jest wymagany", n)
+		case track.Disc < 1 || track.Position < 1:
+			return fmt.Errorf("Utwór %d: numer płyty i pozycja muszą być dodatnie", n)
+		case track.Duration < 0:
+			return fmt.Errorf("Utwór %d: czas trwania nie może być ujemny", n)
+		case track.ISRC != "" && !isrcPattern.MatchString(track.ISRC):
+			return fmt.Errorf("Utwór %d: niepoprawny kod ISRC %q", n, track.ISRC)
+		case seen[slot{track.Disc, track.Position}]:
+			return fmt.Errorf("Utwór %d: pozycja %d na płycie %d jest już zajęta", n, track.Position, track.Disc)
+		}
+		seen[slot{track.Disc, track.Position}] = true
+	}
+	return nil
 }
 
 // DeleteAlbum godoc
 // @Summary Usuń album
 // @Security BearerAuth
-// @Description Usuwa album na podstawie ID
+// @Description Przenosi album do kosza (GET /trash/albums). Album znika z katalogu i można go przywrócić przez POST /albums/{id}/restore; po upływie trash.retention_days dni jest usuwany trwale wraz z okładką. Album występujący w otwartych zamówieniach nie jest usuwany (409 z listą zamówień), chyba że administrator przekaże force=true. Recenzje albumu trafiają razem z nim do kosza.
 // @Tags Albums
 // @Accept json
 // @Produce json
 // @Param id path string true "ID albumu"
+// @Param force query bool false "Usuń mimo otwartych zamówień (tylko admin)"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ReferenceConflictResponse
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /albums/{id} [delete]
 func DeleteAlbum(c *gin.Context) {
@@ -288,19 +598,39 @@ func DeleteAlbum(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	result, err := albumCollection.DeleteOne(ctx, bson.M{"_id": objID})
+	force, err := queryForce(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd usuwania albumu"})
+		respondError(c, err, "Błąd usuwania albumu")
 		return
 	}
 
-	if result.DeletedCount == 0 {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	version, err := ifMatchVersion(c, func() (int64, error) {
+		album, err := albumStore.GetByID(ctx, objID)
+		return album.Version, err
+	})
+	if err == nil {
+		err = checkReferences(ctx, albumIntegrityRules, objID, force)
+	}
+	if err == nil {
+		err = albumStore.Delete(ctx, objID, version)
+	}
+	if errors.Is(err, store.ErrConflict) {
+		err = versionConflict(c)
+	}
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Album nie znaleziony"})
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Album usunięty"})
+	if err != nil {
+		respondError(c, err, "Błąd usuwania albumu")
+		return
+	}
+	// Album jest już w koszu; błąd reguł spójności nie cofa jego usunięcia
+	if err := applyIntegrityRules(ctx, albumIntegrityRules, objID); err != nil {
+		log.Printf("Błąd stosowania reguł spójności dla albumu %s: %v", objID.Hex(), err)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Album przeniesiony do kosza"})
 }
@@ -0,0 +1,451 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var artistStore store.ArtistStore
+
+func InitArtistStore(s store.ArtistStore) {
+	artistStore = s
+}
+
+// errDuplicateArtist zwracany jest, gdy nazwa lub alias należy już do innego wykonawcy
+var errDuplicateArtist = &requestError{http.StatusConflict, "Wykonawca o tej nazwie lub aliasie już istnieje"}
+
+// GetArtists godoc
+// @Summary Pobierz listę wykonawców
+// @Tags Artists
+// @Produce json
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param name query string false "Fragment nazwy lub aliasu (bez wielkości liter)"
+// @Param country query string false "Kody kraju (kilka wartości po przecinku)"
+// @Param sort query string false "Sortowanie po polach (np. -formed_year); dozwolone pola: name (według sort_name), formed_year, created_at"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista wykonawców), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /artists [get]
+func GetArtists(c *gin.Context) {
+	errs := &validationError{}
+	filter := artistFilterFromQuery(c, errs)
+	lp := parseListQuery(c, artistSortFields, errs)
+	if err := errs.errOrNil(); err != nil {
+		respondError(c, err, "Błąd pobierania wykonawców")
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	response, err := fetchPage(lp,
+		func(opts store.ListOptions) ([]models.Artist, error) { return artistStore.List(ctx, filter, opts) },
+		func() (int64, error) { return artistStore.Count(ctx, filter) })
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania wykonawców"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Pola, po których można sortować wykonawców (nazwa w API → pole w dokumencie)
+var artistSortFields = map[string]string{
+	"name":        "sort_name",
+	"formed_year": "formed_year",
+	"created_at":  "created_at",
+}
+
+// GetArtistByID godoc
+// @Summary Pobierz wykonawcę po ID
+// @Tags Artists
+// @Produce json
+// @Param id path string true "ID wykonawcy"
+// @Param If-None-Match header string false "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304"
+// @Success 200 {object} models.Artist
+// @Header 200 {string} ETag "Wersja zasobu"
+// @Success 304 "Zasób nie zmienił się"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /artists/{id} [get]
+func GetArtistByID(c *gin.Context) {
+	idParam := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	artist, err := artistStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wykonawca nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania wykonawcy"})
+		return
+	}
+
+	respondVersioned(c, artist.Version, artist)
+}
+
+// GetArtistAlbums godoc
+// @Summary Pobierz albumy wykonawcy
+// @Description Zwraca albumy przypisane do wykonawcy; przyjmuje te same filtry, sortowanie i stronicowanie co GET /albums
+// @Tags Artists
+// @Produce json
+// @Param id path string true "ID wykonawcy"
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param sort query string false "Sortowanie po polach (np. release_date); dozwolone pola jak w GET /albums"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista albumów), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /artists/{id}/albums [get]
+func GetArtistAlbums(c *gin.Context) {
+	idParam := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	if _, err := artistStore.GetByID(ctx, objID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Wykonawca nie znaleziony"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania wykonawcy"})
+		return
+	}
+
+	listAlbums(c, store.AlbumFilter{ArtistIDs: []primitive.ObjectID{objID}})
+}
+
+// CreateArtist godoc
+// @Summary Dodaj wykonawcę
+// @Security BearerAuth
+// @Tags Artists
+// @Accept json
+// @Produce json
+// @Param artist body models.Artist true "Wykonawca do dodania"
+// @Success 201 {object} models.Artist
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /artists [post]
+func CreateArtist(c *gin.Context) {
+	var artist models.Artist
+	if err := c.ShouldBindJSON(&artist); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
+		return
+	}
+	normalizeArtist(&artist)
+	if err := validateArtist(artist); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	artist.ID = primitive.NewObjectID()
+	artist.CreatedAt = now
+	artist.UpdatedAt = now
+	artist.Version = 1
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	err := artistStore.Create(ctx, artist)
+	if errors.Is(err, store.ErrDuplicate) {
+		respondError(c, errDuplicateArtist, "Błąd tworzenia wykonawcy")
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd tworzenia wykonawcy"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, artist)
+}
+
+// UpdateArtist godoc
+// @Summary Zaktualizuj wykonawcę
+// @Security BearerAuth
+// @Description Częściowo aktualizuje wykonawcę (JSON Merge Patch lub JSON Patch, jak PATCH /albums/{id}). Zmiana nazwy jest przepisywana do pola artist albumów wykonawcy.
+// @Tags Artists
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "ID wykonawcy"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
+// @Param artist body models.Artist true "Zmieniane pola wykonawcy (merge patch) lub lista operacji JSON Patch"
+// @Success 200 {object} models.Artist
+// @Header 200 {string} ETag "Nowa wersja zasobu"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /artists/{id} [patch]
+func UpdateArtist(c *gin.Context) {
+	idParam := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	current, err := artistStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wykonawca nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania wykonawcy"})
+		return
+	}
+
+	if err := checkIfMatch(c, current.Version); err != nil {
+		respondError(c, err, "Błąd aktualizacji wykonawcy")
+		return
+	}
+
+	var artist models.Artist
+	changed, err := applyPatch(c, current, &artist, artistPatchFields)
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji wykonawcy")
+		return
+	}
+	normalizeArtist(&artist)
+	if err := validateArtist(artist); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(changed) == 0 {
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusOK, current)
+		return
+	}
+
+	artist.UpdatedAt = time.Now()
+	update, err := patchUpdate(artist, changed, artistPatchFields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji wykonawcy"})
+		return
+	}
+	update["updated_at"] = artist.UpdatedAt
+
+	err = artistStore.Update(ctx, objID, current.Version, update)
+	if errors.Is(err, store.ErrConflict) {
+		respondError(c, versionConflict(c), "Błąd aktualizacji wykonawcy")
+		return
+	}
+	if errors.Is(err, store.ErrDuplicate) {
+		respondError(c, errDuplicateArtist, "Błąd aktualizacji wykonawcy")
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wykonawca nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji wykonawcy"})
+		return
+	}
+
+	if artist.Name != current.Name {
+		if err := renameArtistAlbums(ctx, objID, artist.Name); err != nil {
+			log.Printf("Błąd zmiany nazwy wykonawcy %s w albumach: %v", objID.Hex(), err)
+		}
+	}
+
+	artist.Version = current.Version + 1
+	c.Header("ETag", etag(artist.Version))
+	c.JSON(http.StatusOK, artist)
+}
+
+// Pola wykonawcy, które można zmienić przez PATCH (klucz JSON → pole w dokumencie)
+var artistPatchFields = map[string]patchField{
+	"name":           {bson: "name"},
+	"sort_name":      {bson: "sort_name", optional: true},
+	"country":        {bson: "country", optional: true},
+	"bio":            {bson: "bio", optional: true},
+	"aliases":        {bson: "aliases", optional: true},
+	"formed_year":    {bson: "formed_year", optional: true},
+	"disbanded_year": {bson: "disbanded_year", optional: true},
+}
+
+// DeleteArtist godoc
+// @Summary Usuń wykonawcę
+// @Security BearerAuth
+// @Description Usuwa wykonawcę, który nie ma przypisanych albumów – również albumów w koszu, które można jeszcze przywrócić
+// @Tags Artists
+// @Produce json
+// @Param id path string true "ID wykonawcy"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ReferenceConflictResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /artists/{id} [delete]
+func DeleteArtist(c *gin.Context) {
+	idParam := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	version, err := ifMatchVersion(c, func() (int64, error) {
+		artist, err := artistStore.GetByID(ctx, objID)
+		return artist.Version, err
+	})
+	if err == nil {
+		err = checkReferences(ctx, artistIntegrityRules, objID, false)
+	}
+	if err == nil {
+		err = artistStore.Delete(ctx, objID, version)
+	}
+	if errors.Is(err, store.ErrConflict) {
+		err = versionConflict(c)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wykonawca nie znaleziony"})
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Błąd usuwania wykonawcy")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wykonawca usunięty"})
+}
+
+// normalizeArtist usuwa zbędne spacje, ujednolica kod kraju, pomija puste
+// i powtórzone aliasy oraz uzupełnia brakującą nazwę do sortowania
+func normalizeArtist(artist *models.Artist) {
+	artist.Name = strings.TrimSpace(artist.Name)
+	artist.SortName = strings.TrimSpace(artist.SortName)
+	if artist.SortName == "" {
+		artist.SortName = models.DefaultSortName(artist.Name)
+	}
+	artist.Country = strings.ToUpper(strings.TrimSpace(artist.Country))
+
+	var aliases []string
+	for _, alias := range artist.Aliases {
+		alias = strings.TrimSpace(alias)
+		if alias != "" && alias != artist.Name && !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	artist.Aliases = aliases
+}
+
+// validateArtist sprawdza nazwę, kod kraju i lata działalności wykonawcy
+func validateArtist(artist models.Artist) error {
+	currentYear := time.Now().Year()
+	switch {
+	case store.ArtistNameKey(artist.Name) == "":
+		return errors.New("Nazwa wykonawcy musi zawierać co najmniej jedną literę lub cyfrę")
+	case artist.Country != "" && !isCountryCode(artist.Country):
+		return errors.New("Kraj musi być dwuliterowym kodem ISO 3166-1, np. GB")
+	case artist.FormedYear != 0 && (artist.FormedYear < 1000 || artist.FormedYear > currentYear):
+		return fmt.Errorf("Rok założenia musi być z zakresu 1000-%d", currentYear)
+	case artist.DisbandedYear != 0 && (artist.DisbandedYear < 1000 || artist.DisbandedYear > currentYear):
+		return fmt.Errorf("Rok rozwiązania musi być z zakresu 1000-%d", currentYear)
+	case artist.FormedYear != 0 && artist.DisbandedYear != 0 && artist.DisbandedYear < artist.FormedYear:
+		return errors.New("Rok rozwiązania nie może być wcześniejszy niż rok założenia")
+	}
+	return nil
+}
+
+// isCountryCode sprawdza, czy wartość jest dwuliterowym kodem kraju (wielkie litery)
+func isCountryCode(value string) bool {
+	if len(value) != 2 {
+		return false
+	}
+	for _, r := range value {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// linkAlbumArtist przypisuje albumowi wykonawcę. Przy podanym artist_id
+// wykonawca musi istnieć; w przeciwnym razie wyszukiwany jest po nazwie lub
+// aliasie z pola artist i tworzony, gdy nie istnieje. Pole artist otrzymuje
+// nazwę wykonawcy.
+func linkAlbumArtist(ctx context.Context, album *models.Album) error {
+	if !album.ArtistID.IsZero() {
+		artist, err := artistStore.GetByID(ctx, album.ArtistID)
+		if errors.Is(err, store.ErrNotFound) {
+			return &requestError{http.StatusBadRequest, "Wykonawca o podanym artist_id nie istnieje"}
+		}
+		if err != nil {
+			return err
+		}
+		album.Artist = artist.Name
+		return nil
+	}
+
+	if store.ArtistNameKey(album.Artist) == "" {
+		return &requestError{http.StatusBadRequest, "Wykonawca albumu jest wymagany (artist lub artist_id)"}
+	}
+	artist, err := store.ResolveArtist(ctx, artistStore, album.Artist)
+	if err != nil {
+		return err
+	}
+	album.ArtistID = artist.ID
+	album.Artist = artist.Name
+	return nil
+}
+
+// renameArtistAlbums przepisuje nową nazwę wykonawcy do jego albumów
+func renameArtistAlbums(ctx context.Context, artistID primitive.ObjectID, name string) error {
+	albums, err := albumStore.List(ctx, store.AlbumFilter{ArtistIDs: []primitive.ObjectID{artistID}}, store.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, album := range albums {
+		err := albumStore.Update(ctx, album.ID, store.AnyVersion, bson.M{"artist": name})
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
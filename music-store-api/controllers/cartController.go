@@ -0,0 +1,462 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"music-store-api/models"
+	"music-store-api/store"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var cartStore store.CartStore
+
+func InitCartStore(s store.CartStore) {
+	cartStore = s
+}
+
+// GetCart godoc
+// @Summary Pobierz koszyk zalogowanego użytkownika
+// @Security BearerAuth
+// @Description Zwraca koszyk wyceniony według aktualnych cen wersji albumów wraz z ostrzeżeniami o brakach w magazynie.
+// @Tags Cart
+// @Produce json
+// @Success 200 {object} models.CartView
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /cart [get]
+func GetCart(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	cart, err := loadCart(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania koszyka"})
+		return
+	}
+
+	respondCart(c, ctx, cart)
+}
+
+// AddCartItem godoc
+// @Summary Dodaj album do koszyka
+// @Security BearerAuth
+// @Description Dodaje podaną liczbę sztuk wersji albumu do koszyka. Pole variant_id można pominąć, gdy album ma jedną wersję. Jeśli wersja jest już w koszyku, liczba sztuk jest zwiększana.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param item body models.CartItemRequest true "Album, wersja i liczba sztuk"
+// @Success 200 {object} models.CartView
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /cart/items [post]
+func AddCartItem(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	var req models.CartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.AlbumID.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane"})
+		return
+	}
+	if req.Quantity < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ilość musi być dodatnia"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	album, err := albumStore.GetByID(ctx, req.AlbumID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Album %s nie istnieje", req.AlbumID.Hex())})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania albumu"})
+		return
+	}
+	variant, err := selectVariant(album, req.VariantID)
+	if err != nil {
+		respondError(c, err, "Błąd dodawania do koszyka")
+		return
+	}
+
+	cart, err := loadCart(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania koszyka"})
+		return
+	}
+
+	added := false
+	for i := range cart.Items {
+		if cart.Items[i].AlbumID == req.AlbumID && cart.Items[i].VariantID == variant.ID {
+			cart.Items[i].Quantity += req.Quantity
+			added = true
+			break
+		}
+	}
+	if !added {
+		cart.Items = append(cart.Items, models.CartItem{AlbumID: req.AlbumID, VariantID: variant.ID, Quantity: req.Quantity})
+	}
+
+	if err := saveCart(ctx, &cart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd zapisu koszyka"})
+		return
+	}
+
+	respondCart(c, ctx, cart)
+}
+
+// UpdateCartItem godoc
+// @Summary Zmień liczbę sztuk albumu w koszyku
+// @Security BearerAuth
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param albumID path string true "ID albumu"
+// @Param variant_id query string false "ID wersji – wymagane, gdy album jest w koszyku w kilku wersjach"
+// @Param item body models.CartQuantityRequest true "Nowa liczba sztuk"
+// @Success 200 {object} models.CartView
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /cart/items/{albumID} [put]
+func UpdateCartItem(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	albumID, err := primitive.ObjectIDFromHex(c.Param("albumID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID albumu"})
+		return
+	}
+	variantID, err := queryVariantID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID wersji"})
+		return
+	}
+
+	var req models.CartQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane"})
+		return
+	}
+	if req.Quantity < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ilość musi być dodatnia"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	cart, err := loadCart(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania koszyka"})
+		return
+	}
+
+	index, err := cartItemIndex(cart, albumID, variantID)
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji koszyka")
+		return
+	}
+	cart.Items[index].Quantity = req.Quantity
+
+	if err := saveCart(ctx, &cart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd zapisu koszyka"})
+		return
+	}
+
+	respondCart(c, ctx, cart)
+}
+
+// RemoveCartItem godoc
+// @Summary Usuń album z koszyka
+// @Security BearerAuth
+// @Tags Cart
+// @Produce json
+// @Param albumID path string true "ID albumu"
+// @Param variant_id query string false "ID wersji – wymagane, gdy album jest w koszyku w kilku wersjach"
+// @Success 200 {object} models.CartView
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /cart/items/{albumID} [delete]
+func RemoveCartItem(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	albumID, err := primitive.ObjectIDFromHex(c.Param("albumID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID albumu"})
+		return
+	}
+	variantID, err := queryVariantID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID wersji"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	cart, err := loadCart(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania koszyka"})
+		return
+	}
+
+	index, err := cartItemIndex(cart, albumID, variantID)
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji koszyka")
+		return
+	}
+	cart.Items = append(cart.Items[:index], cart.Items[index+1:]...)
+
+	if err := saveCart(ctx, &cart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd zapisu koszyka"})
+		return
+	}
+
+	respondCart(c, ctx, cart)
+}
+
+// ClearCart godoc
+// @Summary Wyczyść koszyk
+// @Security BearerAuth
+// @Tags Cart
+// @Produce json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /cart [delete]
+func ClearCart(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	if err := cartStore.Delete(ctx, userID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd czyszczenia koszyka"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Koszyk wyczyszczony"})
+}
+
+// Checkout godoc
+// @Summary Złóż zamówienie z koszyka
+// @Security BearerAuth
+// @Description Tworzy zamówienie z pozycji koszyka według aktualnych cen i opróżnia koszyk. Bez danych wysyłki w żądaniu używane są dane zapisane w profilu użytkownika.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param checkout body models.CheckoutRequest false "Opcjonalne dane do wysyłki"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Niewystarczająca liczba sztuk"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /cart/checkout [post]
+func Checkout(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	var req models.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	cart, err := loadCart(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania koszyka"})
+		return
+	}
+	if len(cart.Items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Koszyk jest pusty"})
+		return
+	}
+
+	shipping := req.Shipping
+	if shipping == nil {
+		user, err := userStore.GetByID(ctx, userID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania użytkownika"})
+			return
+		}
+		shipping = user.ShippingDetails
+	}
+	if shipping == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brak danych wysyłki w żądaniu i w profilu użytkownika"})
+		return
+	}
+
+	requested := make([]models.OrderItemRequest, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		requested = append(requested, models.OrderItemRequest{AlbumID: item.AlbumID, VariantID: item.VariantID, Quantity: item.Quantity})
+	}
+
+	order, err := placeOrder(ctx, userID, requested, *shipping)
+	if err != nil {
+		respondError(c, err, "Błąd tworzenia zamówienia")
+		return
+	}
+
+	// Zamówienie zostało już złożone, więc błąd czyszczenia koszyka nie jest zwracany klientowi
+	if err := cartStore.Delete(ctx, userID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		c.Error(err)
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// loadCart zwraca koszyk użytkownika lub pusty koszyk, jeśli jeszcze go nie utworzono
+func loadCart(ctx context.Context, userID primitive.ObjectID) (models.Cart, error) {
+	cart, err := cartStore.Get(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return models.Cart{UserID: userID, Items: []models.CartItem{}}, nil
+	}
+	return cart, err
+}
+
+func saveCart(ctx context.Context, cart *models.Cart) error {
+	cart.UpdatedAt = time.Now()
+	return cartStore.Save(ctx, *cart)
+}
+
+// cartItemIndex zwraca indeks pozycji koszyka z albumem albumID w wersji
+// variantID; bez wersji – jedynej pozycji tego albumu
+func cartItemIndex(cart models.Cart, albumID, variantID primitive.ObjectID) (int, error) {
+	index := -1
+	for i, item := range cart.Items {
+		if item.AlbumID != albumID || (!variantID.IsZero() && item.VariantID != variantID) {
+			continue
+		}
+		if index >= 0 {
+			return -1, &requestError{http.StatusBadRequest, "Album jest w koszyku w kilku wersjach – podaj variant_id"}
+		}
+		index = i
+	}
+	if index < 0 {
+		return -1, &requestError{http.StatusNotFound, "Albumu nie ma w koszyku"}
+	}
+	return index, nil
+}
+
+// queryVariantID odczytuje opcjonalny parametr variant_id
+func queryVariantID(c *gin.Context) (primitive.ObjectID, error) {
+	value := c.Query("variant_id")
+	if value == "" {
+		return primitive.NilObjectID, nil
+	}
+	return primitive.ObjectIDFromHex(value)
+}
+
+func respondCart(c *gin.Context, ctx context.Context, cart models.Cart) {
+	view, err := priceCart(ctx, cart)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd wyceny koszyka"})
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// priceCart wycenia koszyk według aktualnych cen i stanów magazynowych wersji albumów.
+// Pozycje usuniętych lub niedostępnych albumów i wersji pozostają w koszyku z ostrzeżeniem.
+func priceCart(ctx context.Context, cart models.Cart) (models.CartView, error) {
+	view := models.CartView{
+		Items:       make([]models.CartLine, 0, len(cart.Items)),
+		CanCheckout: len(cart.Items) > 0,
+		Warnings:    []string{},
+	}
+	if !cart.UpdatedAt.IsZero() {
+		view.UpdatedAt = &cart.UpdatedAt
+	}
+
+	var total float64
+	for _, item := range cart.Items {
+		line := models.CartLine{AlbumID: item.AlbumID, VariantID: item.VariantID, Quantity: item.Quantity}
+		view.ItemCount += item.Quantity
+
+		album, err := albumStore.GetByID(ctx, item.AlbumID)
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			line.Warning = "Album nie jest już dostępny w sklepie"
+		case err != nil:
+			return models.CartView{}, err
+		default:
+			line.Title = album.Title
+			line.Artist = album.Artist
+			variant, err := selectVariant(album, item.VariantID)
+			if err != nil {
+				line.Warning = "Wybrana wersja albumu nie jest już dostępna"
+				break
+			}
+			line.VariantID = variant.ID
+			line.Format = variant.Format
+			line.SKU = variant.SKU
+			line.Price = variant.Price
+			line.LineTotal = roundPrice(variant.Price * float64(item.Quantity))
+			line.Available = variant.Stock
+			line.InStock = variant.Stock >= item.Quantity
+			switch {
+			case variant.Stock == 0:
+				line.Warning = "Album w tej wersji jest niedostępny w magazynie"
+			case !line.InStock:
+				line.Warning = fmt.Sprintf("Dostępnych jest tylko %d szt.", variant.Stock)
+			}
+			total += line.LineTotal
+		}
+
+		if line.Warning != "" {
+			view.CanCheckout = false
+			view.Warnings = append(view.Warnings, fmt.Sprintf("%s: %s", cartLineName(line), line.Warning))
+		}
+		view.Items = append(view.Items, line)
+	}
+
+	view.Total = roundPrice(total)
+	return view, nil
+}
+
+func cartLineName(line models.CartLine) string {
+	if line.Title == "" {
+		return line.AlbumID.Hex()
+	}
+	return fmt.Sprintf("%q", line.Title)
+}
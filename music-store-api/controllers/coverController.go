@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var coverStore store.CoverStore
+
+func InitCoverStore(s store.CoverStore) {
+	coverStore = s
+}
+
+const (
+	// Maksymalny rozmiar przesyłanego pliku okładki
+	maxCoverBytes = 5 << 20
+	// Maksymalna liczba pikseli obrazu (ochrona przed bardzo dużymi obrazami)
+	maxCoverPixels = 40_000_000
+	// Rozmiar oryginalnego pliku okładki
+	coverSizeOriginal = "original"
+	// Jakość JPEG miniatur
+	thumbnailQuality = 85
+	// Czas przechowywania okładki w pamięci podręcznej klienta
+	coverCacheControl = "public, max-age=3600"
+)
+
+// Dłuższy bok miniatur okładki w pikselach
+var coverThumbnailSizes = []int{150, 600}
+
+// Obsługiwane formaty okładek (typ wykryty z zawartości pliku)
+var coverContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// UploadAlbumCover godoc
+// @Summary Prześlij okładkę albumu
+// @Security BearerAuth
+// @Description Zapisuje okładkę (JPEG, PNG lub WebP, do 5 MB) w GridFS wraz z miniaturami 150 i 600 px i ustawia cover_url albumu
+// @Tags Albums
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "ID albumu"
+// @Param file formData file true "Plik obrazu okładki"
+// @Success 201 {object} map[string]interface{} "Adres okładki (cover_url) i dostępne rozmiary"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /albums/{id}/cover [post]
+func UploadAlbumCover(c *gin.Context) {
+	idParam := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	if _, err := albumStore.GetByID(ctx, objID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Album nie znaleziony"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania albumu"})
+		return
+	}
+
+	data, err := readCoverUpload(c)
+	if err != nil {
+		respondError(c, err, "Błąd odczytu pliku okładki")
+		return
+	}
+	img, contentType, err := decodeCover(data)
+	if err != nil {
+		respondError(c, err, "Błąd odczytu pliku okładki")
+		return
+	}
+
+	now := time.Now()
+	images := map[string]store.CoverImage{
+		coverSizeOriginal: {ContentType: contentType, Data: data, UploadedAt: now},
+	}
+	sizes := []string{coverSizeOriginal}
+	for _, side := range coverThumbnailSizes {
+		thumbnail, err := coverThumbnail(img, side)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd tworzenia miniatury okładki"})
+			return
+		}
+		size := strconv.Itoa(side)
+		images[size] = store.CoverImage{ContentType: "image/jpeg", Data: thumbnail, UploadedAt: now}
+		sizes = append(sizes, size)
+	}
+
+	for _, size := range sizes {
+		if err := coverStore.Save(ctx, objID, size, images[size]); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd zapisu okładki"})
+			return
+		}
+	}
+
+	coverURL := "/albums/" + objID.Hex() + "/cover"
+	err = albumStore.Update(ctx, objID, store.AnyVersion, bson.M{"cover_url": coverURL, "updated_at": now})
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Album nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji albumu"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"cover_url": coverURL, "sizes": sizes})
+}
+
+// GetAlbumCover godoc
+// @Summary Pobierz okładkę albumu
+// @Description Zwraca oryginalną okładkę albumu lub jej miniaturę. Odpowiedź zawiera nagłówki ETag, Last-Modified i Cache-Control; żądania warunkowe zwracają 304.
+// @Tags Albums
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/webp
+// @Param id path string true "ID albumu"
+// @Param size query string false "Rozmiar: original (domyślnie), 150 lub 600"
+// @Success 200 {file} file
+// @Success 304 "Okładka nie zmieniła się"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /albums/{id}/cover [get]
+func GetAlbumCover(c *gin.Context) {
+	idParam := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID"})
+		return
+	}
+
+	size := c.DefaultQuery("size", coverSizeOriginal)
+	if !isCoverSize(size) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Niepoprawny rozmiar okładki %q (dozwolone: original, 150, 600)", size)})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	cover, err := coverStore.Get(ctx, objID, size)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Okładka nie znaleziona"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania okładki"})
+		return
+	}
+
+	sum := sha256.Sum256(cover.Data)
+	c.Header("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	c.Header("Cache-Control", coverCacheControl)
+	c.Header("Content-Type", cover.ContentType)
+	// ServeContent obsługuje If-None-Match, If-Modified-Since i zakresy bajtów
+	http.ServeContent(c.Writer, c.Request, "", cover.UploadedAt, bytes.NewReader(cover.Data))
+}
+
+// readCoverUpload odczytuje plik z pola file formularza multipart, odrzucając pliki większe niż maxCoverBytes
+func readCoverUpload(c *gin.Context) ([]byte, error) {
+	// Zapas na nagłówki i granice formularza multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCoverBytes+64<<10)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, coverTooLarge()
+		}
+		return nil, &requestError{http.StatusBadRequest, "Brak pliku okładki w polu file formularza multipart"}
+	}
+	if fileHeader.Size > maxCoverBytes {
+		return nil, coverTooLarge()
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, maxCoverBytes))
+}
+
+func coverTooLarge() error {
+	return &requestError{http.StatusRequestEntityTooLarge, fmt.Sprintf("Plik okładki może mieć najwyżej %d MB", maxCoverBytes>>20)}
+}
+
+// decodeCover sprawdza typ obrazu na podstawie zawartości pliku i dekoduje go
+func decodeCover(data []byte) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	if !coverContentTypes[contentType] {
+		return nil, "", &requestError{http.StatusUnsupportedMediaType, "Nieobsługiwany format okładki (dozwolone: JPEG, PNG, WebP)"}
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", &requestError{http.StatusBadRequest, "Plik okładki nie jest poprawnym obrazem"}
+	}
+	if config.Width*config.Height > maxCoverPixels {
+		return nil, "", &requestError{http.StatusBadRequest, "Wymiary okładki są zbyt duże"}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", &requestError{http.StatusBadRequest, "Plik okładki nie jest poprawnym obrazem"}
+	}
+	return img, contentType, nil
+}
+
+// coverThumbnail zmniejsza obraz tak, aby dłuższy bok miał maxSide pikseli
+// (mniejsze obrazy nie są powiększane), i koduje go jako JPEG. Przezroczyste
+// obszary wypełniane są bielą.
+func coverThumbnail(src image.Image, maxSide int) ([]byte, error) {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			height = max(1, height*maxSide/width)
+			width = maxSide
+		} else {
+			width = max(1, width*maxSide/height)
+			height = maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isCoverSize(size string) bool {
+	if size == coverSizeOriginal {
+		return true
+	}
+	for _, side := range coverThumbnailSizes {
+		if size == strconv.Itoa(side) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,8 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
-	"music-store-api/config"
 	"music-store-api/middleware"
 	"music-store-api/models"
 	"net/http"
@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -27,118 +26,92 @@ import (
 // @Failure 500 {object} map[string]string "Błąd serwera lub pliku danych"
 // @Router /data/load [post]
 func LoadTestData(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	db := config.DB
-
-	type LoadFile struct {
-		FilePath   string
-		Collection string
-		Data       interface{}
-		Process    func(interface{})
+	if err := SeedTestData(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	files := []LoadFile{
-		{
-			FilePath:   "data/albums.json",
-			Collection: "albums",
-			Data:       &[]models.Album{},
-			Process: func(data interface{}) {
-				albums := data.(*[]models.Album)
-				now := time.Now()
-				for i := range *albums {
-					(*albums)[i].ID = primitive.NewObjectID()
-					(*albums)[i].CreatedAt = now
-					(*albums)[i].UpdatedAt = now
-				}
-			},
-		},
-		{
-			FilePath:   "data/users.json",
-			Collection: "users",
-			Data:       &[]models.User{},
-			Process: func(data interface{}) {
-				users := data.(*[]models.User)
-				now := time.Now()
-				for i := range *users {
-					(*users)[i].ID = primitive.NewObjectID()
-					(*users)[i].CreatedAt = now
-					(*users)[i].UpdatedAt = now
-
-					hashed, err := middleware.HashPassword((*users)[i].Password)
-					if err != nil {
-						log.Printf("Błąd haszowania hasła użytkownika: %v", err)
-						(*users)[i].PasswordHash = ""
-					} else {
-						(*users)[i].PasswordHash = hashed
-					}
+	c.JSON(http.StatusOK, gin.H{"message": "Dane zostały wczytane"})
+}
 
-					(*users)[i].Password = ""
-				}
-			},
-		},
+// SeedTestData zastępuje zawartość repozytoriów danymi testowymi z katalogu data.
+// Zwracane błędy zawierają komunikat przeznaczony dla klienta API, szczegóły trafiają do logu.
+func SeedTestData(ctx context.Context) error {
+	now := time.Now()
+	var artists []models.Artist
+	if err := readDataFile("data/artists.json", &artists); err != nil {
+		return err
 	}
-
-	for _, file := range files {
-		byteValue, err := os.ReadFile(file.FilePath)
-		if err != nil {
-			log.Printf("Nie udało się otworzyć %s: %v", file.FilePath, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd odczytu pliku " + file.FilePath})
-			return
+	for i := range artists {
+		normalizeArtist(&artists[i])
+		if err := validateArtist(artists[i]); err != nil {
+			return fmt.Errorf("Wykonawca %d w pliku data/artists.json: %v", i+1, err)
 		}
+		artists[i].ID = primitive.NewObjectID()
+		artists[i].CreatedAt = now
+		artists[i].UpdatedAt = now
+		artists[i].Version = 1
+	}
+	if err := artistStore.ReplaceAll(ctx, artists); err != nil {
+		log.Printf("Błąd przy wstawianiu wykonawców: %v", err)
+		return fmt.Errorf("Błąd wstawiania do kolekcji artists")
+	}
 
-		err = json.Unmarshal(byteValue, file.Data)
-		if err != nil {
-			log.Printf("Błąd dekodowania %s: %v", file.FilePath, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dekodowania pliku " + file.FilePath})
-			return
-		}
-
-		file.Process(file.Data)
-
-		if err := db.Collection(file.Collection).Drop(ctx); err != nil {
-			log.Printf("Błąd przy czyszczeniu kolekcji %s: %v", file.Collection, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd czyszczenia kolekcji " + file.Collection})
-			return
+	var albums []models.Album
+	if err := readDataFile("data/albums.json", &albums); err != nil {
+		return err
+	}
+	for i := range albums {
+		if err := prepareNewAlbum(&albums[i]); err != nil {
+			return fmt.Errorf("Album %d w pliku data/albums.json: %v", i+1, err)
 		}
-
-		docs := toInterfaceSlice(file.Data)
-		if len(docs) > 0 {
-			_, err := db.Collection(file.Collection).InsertMany(ctx, docs)
-			if err != nil {
-				log.Printf("Błąd przy wstawianiu do %s: %v", file.Collection, err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd wstawiania do kolekcji " + file.Collection})
-				return
-			}
+		// Albumy z pliku odwołują się do wykonawców nazwą
+		if err := linkAlbumArtist(ctx, &albums[i]); err != nil {
+			log.Printf("Błąd przypisania wykonawcy albumu: %v", err)
+			return fmt.Errorf("Album %d w pliku data/albums.json: brak wykonawcy", i+1)
 		}
+		albums[i].ID = primitive.NewObjectID()
+		albums[i].CreatedAt = now
+		albums[i].UpdatedAt = now
+		albums[i].Version = 1
+	}
+	if len(albums) == 0 {
+		return fmt.Errorf("Brak albumów w pliku data/albums.json")
+	}
+	if err := albumStore.ReplaceAll(ctx, albums); err != nil {
+		log.Printf("Błąd przy wstawianiu albumów: %v", err)
+		return fmt.Errorf("Błąd wstawiania do kolekcji albums")
 	}
 
-	var albums []models.Album
 	var users []models.User
-
-	cursor, err := db.Collection("albums").Find(ctx, bson.M{})
-	if err != nil {
-		log.Println("Błąd przy odczycie albumów:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd przy odczycie albumów"})
-		return
-	}
-	if err := cursor.All(ctx, &albums); err != nil || len(albums) == 0 {
-		log.Println("Błąd dekodowania albumów lub brak danych:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd przy dekodowaniu albumów"})
-		return
+	if err := readDataFile("data/users.json", &users); err != nil {
+		return err
 	}
+	for i := range users {
+		users[i].ID = primitive.NewObjectID()
+		users[i].CreatedAt = now
+		users[i].UpdatedAt = now
+		users[i].Version = 1
 
-	cursor, err = db.Collection("users").Find(ctx, bson.M{})
-	if err != nil {
-		log.Println("Błąd przy odczycie użytkowników:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd przy odczycie użytkowników"})
-		return
+		hashed, err := middleware.HashPassword(users[i].Password)
+		if err != nil {
+			log.Printf("Błąd haszowania hasła użytkownika: %v", err)
+			users[i].PasswordHash = ""
+		} else {
+			users[i].PasswordHash = hashed
+		}
+
+		users[i].Password = ""
 	}
-	if err := cursor.All(ctx, &users); err != nil || len(users) == 0 {
-		log.Println("Błąd dekodowania użytkowników lub brak danych:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd przy dekodowaniu użytkowników"})
-		return
+	if len(users) == 0 {
+		return fmt.Errorf("Brak użytkowników w pliku data/users.json")
+	}
+	if err := userStore.ReplaceAll(ctx, users); err != nil {
+		log.Printf("Błąd przy wstawianiu użytkowników: %v", err)
+		return fmt.Errorf("Błąd wstawiania do kolekcji users")
 	}
 
 	reviews := []models.Review{
@@ -149,6 +122,7 @@ func LoadTestData(c *gin.Context) {
 			Rating:    5,
 			Comment:   "Świetny album!",
 			CreatedAt: time.Now(),
+			Version:   1,
 		},
 		{
 			ID:        primitive.NewObjectID(),
@@ -157,6 +131,7 @@ func LoadTestData(c *gin.Context) {
 			Rating:    4,
 			Comment:   "Fajny, ale mógłby być lepszy.",
 			CreatedAt: time.Now(),
+			Version:   1,
 		},
 		{
 			ID:        primitive.NewObjectID(),
@@ -165,6 +140,7 @@ func LoadTestData(c *gin.Context) {
 			Rating:    3,
 			Comment:   "Nie do końca mój klimat.",
 			CreatedAt: time.Now(),
+			Version:   1,
 		},
 		{
 			ID:        primitive.NewObjectID(),
@@ -173,6 +149,7 @@ func LoadTestData(c *gin.Context) {
 			Rating:    5,
 			Comment:   "Kolejny hit! Polecam każdemu.",
 			CreatedAt: time.Now(),
+			Version:   1,
 		},
 	}
 
@@ -181,15 +158,12 @@ func LoadTestData(c *gin.Context) {
 			ID:     primitive.NewObjectID(),
 			UserID: users[0].ID,
 			Items: []models.OrderItem{
-				{
-					AlbumID:  albums[0].ID,
-					Quantity: 2,
-					Price:    albums[0].Price,
-				},
+				seedOrderItem(albums[0], 2),
 			},
-			Total:     albums[0].Price * 2,
+			Total:     albums[0].Variants[0].Price * 2,
 			Status:    "pending",
 			CreatedAt: time.Now(),
+			Version:   1,
 			UpdatedAt: time.Now(),
 			Shipping: models.ShippingDetails{
 				Address:     "ul. Testowa 1",
@@ -203,20 +177,13 @@ func LoadTestData(c *gin.Context) {
 			ID:     primitive.NewObjectID(),
 			UserID: users[1].ID,
 			Items: []models.OrderItem{
-				{
-					AlbumID:  albums[1].ID,
-					Quantity: 1,
-					Price:    albums[1].Price,
-				},
-				{
-					AlbumID:  albums[2%len(albums)].ID,
-					Quantity: 3,
-					Price:    albums[2%len(albums)].Price,
-				},
+				seedOrderItem(albums[1], 1),
+				seedOrderItem(albums[2%len(albums)], 3),
 			},
-			Total:     albums[1].Price*1 + albums[2%len(albums)].Price*3,
+			Total:     albums[1].Variants[0].Price*1 + albums[2%len(albums)].Variants[0].Price*3,
 			Status:    "processing",
 			CreatedAt: time.Now(),
+			Version:   1,
 			UpdatedAt: time.Now(),
 			Shipping: models.ShippingDetails{
 				Address:     "ul. Muzyczna 7",
@@ -230,15 +197,12 @@ func LoadTestData(c *gin.Context) {
 			ID:     primitive.NewObjectID(),
 			UserID: users[2%len(users)].ID,
 			Items: []models.OrderItem{
-				{
-					AlbumID:  albums[0].ID,
-					Quantity: 1,
-					Price:    albums[0].Price,
-				},
+				seedOrderItem(albums[0], 1),
 			},
-			Total:     albums[0].Price,
+			Total:     albums[0].Variants[0].Price,
 			Status:    "completed",
 			CreatedAt: time.Now(),
+			Version:   1,
 			UpdatedAt: time.Now(),
 			Shipping: models.ShippingDetails{
 				Address:     "ul. Finalna 99",
@@ -250,42 +214,40 @@ func LoadTestData(c *gin.Context) {
 		},
 	}
 
-	if err := db.Collection("reviews").Drop(ctx); err != nil {
-		log.Printf("Błąd przy czyszczeniu kolekcji reviews: %v", err)
-	}
-	if _, err := db.Collection("reviews").InsertMany(ctx, toInterfaceSlice(&reviews)); err != nil {
+	if err := reviewStore.ReplaceAll(ctx, reviews); err != nil {
 		log.Printf("Błąd przy wstawianiu recenzji: %v", err)
 	}
 
-	if err := db.Collection("orders").Drop(ctx); err != nil {
-		log.Printf("Błąd przy czyszczeniu kolekcji orders: %v", err)
-	}
-	if _, err := db.Collection("orders").InsertMany(ctx, toInterfaceSlice(&orders)); err != nil {
+	if err := orderStore.ReplaceAll(ctx, orders); err != nil {
 		log.Printf("Błąd przy wstawianiu zamówień: %v", err)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Dane zostały wczytane"})
+	return nil
 }
 
-func toInterfaceSlice(data interface{}) []interface{} {
-	var res []interface{}
-	switch v := data.(type) {
-	case *[]models.Album:
-		for _, item := range *v {
-			res = append(res, item)
-		}
-	case *[]models.User:
-		for _, item := range *v {
-			res = append(res, item)
-		}
-	case *[]models.Review:
-		for _, item := range *v {
-			res = append(res, item)
-		}
-	case *[]models.Order:
-		for _, item := range *v {
-			res = append(res, item)
-		}
+func readDataFile(path string, target interface{}) error {
+	byteValue, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Nie udało się otworzyć %s: %v", path, err)
+		return fmt.Errorf("Błąd odczytu pliku %s", path)
+	}
+
+	if err := json.Unmarshal(byteValue, target); err != nil {
+		log.Printf("Błąd dekodowania %s: %v", path, err)
+		return fmt.Errorf("Błąd dekodowania pliku %s", path)
+	}
+	return nil
+}
+
+// seedOrderItem zwraca pozycję przykładowego zamówienia na pierwszą wersję albumu
+func seedOrderItem(album models.Album, quantity int) models.OrderItem {
+	variant := album.Variants[0]
+	return models.OrderItem{
+		AlbumID:   album.ID,
+		VariantID: variant.ID,
+		Format:    variant.Format,
+		SKU:       variant.SKU,
+		Quantity:  quantity,
+		Price:     variant.Price,
 	}
-	return res
 }
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag zwraca silny znacznik ETag dla wersji zasobu
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// respondVersioned odsyła zasób z nagłówkiem ETag. Gdy nagłówek
+// If-None-Match pasuje do wersji zasobu, odsyła 304 bez treści.
+func respondVersioned(c *gin.Context, version int64, body interface{}) {
+	tag := etag(version)
+	c.Header("ETag", tag)
+	if header := c.GetHeader("If-None-Match"); header != "" && matchesNoneMatch(header, tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// matchesNoneMatch porównuje znaczniki z If-None-Match w trybie słabym (RFC 9110)
+func matchesNoneMatch(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch sprawdza nagłówek If-Match z bieżącą wersją zasobu. Brak
+// nagłówka lub "*" pasuje zawsze; znaczniki słabe nigdy nie pasują
+// (porównanie silne).
+func checkIfMatch(c *gin.Context, current int64) error {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+	tag := etag(current)
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimSpace(candidate) == tag {
+			return nil
+		}
+	}
+	return errPreconditionFailed
+}
+
+// errPreconditionFailed zwracany jest, gdy If-Match nie pasuje do wersji zasobu
+var errPreconditionFailed = &requestError{http.StatusPreconditionFailed, "Zasób został zmieniony – wersja z nagłówka If-Match jest nieaktualna"}
+
+// versionConflict opisuje błąd store.ErrConflict przy aktualizacji warunkowej:
+// 412, gdy klient przesłał If-Match, a 409, gdy zasób zmienił się równolegle
+// między odczytem a zapisem wykonywanym przez serwer
+func versionConflict(c *gin.Context) error {
+	if c.GetHeader("If-Match") != "" {
+		return errPreconditionFailed
+	}
+	return &requestError{http.StatusConflict, "Zasób został w międzyczasie zmieniony, spróbuj ponownie"}
+}
+
+// ifMatchVersion obsługuje If-Match w zapisach, które nie odczytują zasobu
+// przed zmianą. Bez nagłówka zwraca store.AnyVersion; w przeciwnym razie
+// pobiera bieżącą wersję przez getVersion, sprawdza ją z nagłówkiem i zwraca
+// do aktualizacji warunkowej, aby zmiana między odczytem a zapisem dała ErrConflict.
+func ifMatchVersion(c *gin.Context, getVersion func() (int64, error)) (int64, error) {
+	if c.GetHeader("If-Match") == "" {
+		return store.AnyVersion, nil
+	}
+	version, err := getVersion()
+	if err != nil {
+		return 0, err
+	}
+	if err := checkIfMatch(c, version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// albumFilterFromQuery buduje filtr albumów z parametrów zapytania. Parametry
+// tekstowe mogą zawierać kilka wartości rozdzielonych przecinkami, z których
+// wystarczy spełnić dowolną. Niepoprawne wartości dopisywane są do errs.
+func albumFilterFromQuery(c *gin.Context, errs *validationError) store.AlbumFilter {
+	filter := store.AlbumFilter{
+		Artists: splitQueryList(c.Query("artist")),
+		Genres:  splitQueryList(c.Query("genre")),
+	}
+	for _, value := range splitQueryList(c.Query("artist_id")) {
+		artistID, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			errs.add("artist_id", value, "Oczekiwano identyfikatora wykonawcy")
+			continue
+		}
+		filter.ArtistIDs = append(filter.ArtistIDs, artistID)
+	}
+
+	switch match := c.Query("match"); match {
+	case "", "partial":
+	case "exact":
+		filter.ExactMatch = true
+	default:
+		errs.add("match", match, "Dozwolone wartości: partial, exact")
+	}
+
+	for _, value := range splitQueryList(c.Query("decade")) {
+		decade, err := store.ParseDecade(value)
+		if err != nil {
+			errs.add("decade", value, "Oczekiwano roku rozpoczynającego dekadę, np. 1980")
+			continue
+		}
+		filter.Decades = append(filter.Decades, decade)
+	}
+	for _, value := range splitQueryList(c.Query("price_range")) {
+		priceRange, err := store.ParsePriceRange(value)
+		if err != nil {
+			errs.add("price_range", value, "Oczekiwano przedziału w postaci od-do (np. 20-30) lub od+ (np. 50+)")
+			continue
+		}
+		filter.PriceRanges = append(filter.PriceRanges, priceRange)
+	}
+
+	filter.MinPrice = queryPrice(c, errs, "min_price")
+	filter.MaxPrice = queryPrice(c, errs, "max_price")
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		errs.add("max_price", c.Query("max_price"), "Cena maksymalna nie może być mniejsza od minimalnej")
+	}
+
+	filter.ReleasedAfter = queryDate(c, errs, "released_after")
+	filter.ReleasedBefore = queryDate(c, errs, "released_before")
+	if filter.ReleasedAfter != nil && filter.ReleasedBefore != nil && filter.ReleasedAfter.After(*filter.ReleasedBefore) {
+		errs.add("released_before", c.Query("released_before"), "Data końcowa nie może być wcześniejsza od początkowej")
+	}
+
+	for _, format := range splitQueryList(c.Query("format")) {
+		format = strings.ToLower(format)
+		if !models.IsValidFormat(format) {
+			errs.add("format", format, "Dozwolone wartości: "+strings.Join(models.Formats, ", "))
+			continue
+		}
+		filter.Formats = append(filter.Formats, format)
+	}
+
+	if inStock := queryBool(c, errs, "in_stock"); inStock != nil {
+		filter.InStock = *inStock
+	}
+
+	return filter
+}
+
+// userFilterFromQuery buduje filtr użytkowników z parametrów role (kilka
+// wartości po przecinku) i is_active
+func userFilterFromQuery(c *gin.Context, errs *validationError) store.UserFilter {
+	var filter store.UserFilter
+	for _, role := range splitQueryList(c.Query("role")) {
+		if !models.IsValidRole(role) {
+			errs.add("role", role, "Dozwolone wartości: customer, employee, admin")
+			continue
+		}
+		filter.Roles = append(filter.Roles, role)
+	}
+	filter.IsActive = queryBool(c, errs, "is_active")
+	return filter
+}
+
+// artistFilterFromQuery buduje filtr wykonawców z parametrów name i country
+// (kilka kodów kraju po przecinku)
+func artistFilterFromQuery(c *gin.Context, errs *validationError) store.ArtistFilter {
+	filter := store.ArtistFilter{Name: strings.TrimSpace(c.Query("name"))}
+	for _, country := range splitQueryList(c.Query("country")) {
+		if !isCountryCode(strings.ToUpper(country)) {
+			errs.add("country", country, "Oczekiwano dwuliterowego kodu kraju, np. GB")
+			continue
+		}
+		filter.Countries = append(filter.Countries, strings.ToUpper(country))
+	}
+	return filter
+}
+
+// orderFilterFromQuery buduje filtr zamówień z parametrów status (kilka
+// wartości po przecinku), created_after i created_before
+func orderFilterFromQuery(c *gin.Context, errs *validationError) store.OrderFilter {
+	var filter store.OrderFilter
+	for _, status := range splitQueryList(c.Query("status")) {
+		if !models.IsValidOrderStatus(status) {
+			errs.add("status", status, "Dozwolone wartości: pending, processing, shipped, completed, cancelled")
+			continue
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	filter.CreatedAfter = queryDate(c, errs, "created_after")
+	filter.CreatedBefore = queryDate(c, errs, "created_before")
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && filter.CreatedAfter.After(*filter.CreatedBefore) {
+		errs.add("created_before", c.Query("created_before"), "Data końcowa nie może być wcześniejsza od początkowej")
+	}
+	return filter
+}
+
+// reviewFilterFromQuery buduje filtr recenzji z parametrów min_rating i max_rating
+func reviewFilterFromQuery(c *gin.Context, errs *validationError) store.ReviewFilter {
+	var filter store.ReviewFilter
+	filter.MinRating = queryRating(c, errs, "min_rating")
+	filter.MaxRating = queryRating(c, errs, "max_rating")
+	if filter.MinRating != nil && filter.MaxRating != nil && *filter.MinRating > *filter.MaxRating {
+		errs.add("max_rating", c.Query("max_rating"), "Ocena maksymalna nie może być mniejsza od minimalnej")
+	}
+	return filter
+}
+
+// queryRating odczytuje ocenę z zakresu 1-5; brak parametru daje nil
+func queryRating(c *gin.Context, errs *validationError, param string) *int {
+	value, ok := c.GetQuery(param)
+	if !ok || value == "" {
+		return nil
+	}
+	rating, err := strconv.Atoi(value)
+	if err != nil || rating < 1 || rating > 5 {
+		errs.add(param, value, "Oczekiwano liczby całkowitej od 1 do 5")
+		return nil
+	}
+	return &rating
+}
+
+// queryBool odczytuje wartość logiczną; brak parametru daje nil
+func queryBool(c *gin.Context, errs *validationError, param string) *bool {
+	value, ok := c.GetQuery(param)
+	if !ok || value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		errs.add(param, value, "Oczekiwano wartości true lub false")
+		return nil
+	}
+	return &parsed
+}
+
+// queryPrice odczytuje nieujemną cenę z parametru zapytania; brak parametru daje nil
+func queryPrice(c *gin.Context, errs *validationError, param string) *float64 {
+	value, ok := c.GetQuery(param)
+	if !ok || value == "" {
+		return nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price < 0 {
+		errs.add(param, value, "Oczekiwano nieujemnej liczby")
+		return nil
+	}
+	return &price
+}
+
+// queryDate odczytuje datę w postaci RRRR-MM-DD lub RFC 3339; brak parametru daje nil
+func queryDate(c *gin.Context, errs *validationError, param string) *time.Time {
+	value, ok := c.GetQuery(param)
+	if !ok || value == "" {
+		return nil
+	}
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return &date
+		}
+	}
+	errs.add(param, value, "Oczekiwano daty w postaci RRRR-MM-DD lub RFC 3339")
+	return nil
+}
+
+// splitQueryList dzieli wartość parametru po przecinkach, pomijając puste elementy
+func splitQueryList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"music-store-api/config"
+	"music-store-api/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dbContext zwraca kontekst z limitem czasu operacji na danych (database.query_timeout)
+func dbContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), config.App.Database.QueryTimeout.Std())
+}
+
+// requestError opisuje błąd wynikający z treści żądania wraz z kodem HTTP dla klienta
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// validationError zbiera błędy wszystkich niepoprawnych parametrów zapytania,
+// aby klient otrzymał je w jednej odpowiedzi
+type validationError struct {
+	details []models.ParamError
+}
+
+func (e *validationError) Error() string {
+	return fmt.Sprintf("niepoprawne parametry zapytania (%d)", len(e.details))
+}
+
+func (e *validationError) add(param, value, message string) {
+	e.details = append(e.details, models.ParamError{Param: param, Value: value, Message: message})
+}
+
+// errOrNil zwraca błąd tylko wtedy, gdy zebrano co najmniej jeden błąd parametru
+func (e *validationError) errOrNil() error {
+	if len(e.details) == 0 {
+		return nil
+	}
+	return e
+}
+
+// respondError odsyła błąd żądania z jego kodem, a pozostałe błędy jako 500 z podanym komunikatem
+func respondError(c *gin.Context, err error, internalMessage string) {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		c.JSON(reqErr.status, gin.H{"error": reqErr.message})
+		return
+	}
+	var refErr *referenceConflict
+	if errors.As(err, &refErr) {
+		c.JSON(http.StatusConflict, models.ReferenceConflictResponse{
+			Error:      refErr.Error(),
+			References: refErr.references,
+		})
+		return
+	}
+	var valErr *validationError
+	if errors.As(err, &valErr) {
+		c.JSON(http.StatusBadRequest, models.ValidationErrorResponse{
+			Error:   "Niepoprawne parametry zapytania",
+			Details: valErr.details,
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": internalMessage})
+}
+
+// currentUserID zwraca ID zalogowanego użytkownika ustawione przez AuthMiddleware
+func currentUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	userID, err := primitive.ObjectIDFromHex(c.GetString("userID"))
+	if err != nil {
+		return primitive.NilObjectID, false
+	}
+	return userID, true
+}
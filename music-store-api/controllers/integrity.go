@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// integrityAction określa, co dzieje się z dokumentami wskazującymi na usuwany dokument
+type integrityAction int
+
+const (
+	// restrictAction blokuje usunięcie, dopóki istnieją odwołania
+	restrictAction integrityAction = iota
+	// cascadeAction usuwa wskazujące dokumenty razem z usuwanym
+	cascadeAction
+	// anonymizeAction zachowuje wskazujące dokumenty, usuwając z nich dane osobowe
+	anonymizeAction
+)
+
+// Najwięcej identyfikatorów jednego zasobu w odpowiedzi 409
+const maxListedReferences = 20
+
+// integrityRule opisuje relację dokumentów innego zasobu z usuwanym dokumentem
+type integrityRule struct {
+	// Zasób zawierający odwołania, np. "orders"
+	resource string
+	// Opis relacji zwracany przy blokadzie usunięcia
+	reason string
+	action integrityAction
+	// Administrator może pominąć regułę restrictAction parametrem force
+	forceable bool
+	// references zwraca ID dokumentów wskazujących na usuwany dokument id
+	references func(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error)
+	// apply wykonuje akcję cascadeAction lub anonymizeAction na wskazującym dokumencie
+	apply func(ctx context.Context, refID primitive.ObjectID) error
+}
+
+// Reguły spójności przy usuwaniu albumu: otwarte zamówienia blokują
+// usunięcie, a recenzje albumu trafiają razem z nim do kosza
+var albumIntegrityRules = []integrityRule{
+	{
+		resource:  "orders",
+		reason:    "Album występuje w otwartych zamówieniach",
+		action:    restrictAction,
+		forceable: true,
+		references: func(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+			return orderIDs(ctx, store.OrderFilter{AlbumID: id, Statuses: models.OpenOrderStatuses})
+		},
+	},
+	{
+		resource: "reviews",
+		action:   cascadeAction,
+		references: func(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+			return reviewIDs(ctx, store.ReviewFilter{AlbumID: id})
+		},
+		apply: func(ctx context.Context, refID primitive.ObjectID) error {
+			return reviewStore.Delete(ctx, refID, store.AnyVersion)
+		},
+	},
+}
+
+// Reguły spójności przy usuwaniu użytkownika: otwarte zamówienia blokują
+// przeniesienie do kosza. Pozostałe reguły są nieodwracalne, dlatego stosuje
+// się je dopiero przy trwałym usunięciu użytkownika z kosza: wszystkie jego
+// zamówienia (także otwarte, dopuszczone przez force, i te w koszu) zostają
+// bez danych wysyłki, recenzje (także w koszu) tracą powiązanie z kontem,
+// a koszyk jest usuwany. Reguły anonimizacji działają na wszystkich
+// dokumentach użytkownika naraz, więc jako odwołanie zwracają jego ID.
+var userIntegrityRules = []integrityRule{
+	{
+		resource:  "orders",
+		reason:    "Użytkownik ma otwarte zamówienia",
+		action:    restrictAction,
+		forceable: true,
+		references: func(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+			return orderIDs(ctx, store.OrderFilter{UserID: id, Statuses: models.OpenOrderStatuses})
+		},
+	},
+	{
+		resource:   "orders",
+		action:     anonymizeAction,
+		references: ownReference,
+		apply: func(ctx context.Context, userID primitive.ObjectID) error {
+			return orderStore.AnonymizeShipping(ctx, userID)
+		},
+	},
+	{
+		resource:   "reviews",
+		action:     anonymizeAction,
+		references: ownReference,
+		apply: func(ctx context.Context, userID primitive.ObjectID) error {
+			return reviewStore.DetachUser(ctx, userID)
+		},
+	},
+	{
+		resource:   "carts",
+		action:     cascadeAction,
+		references: ownReference,
+		apply: func(ctx context.Context, refID primitive.ObjectID) error {
+			return cartStore.Delete(ctx, refID)
+		},
+	},
+}
+
+// Reguły spójności przy usuwaniu wykonawcy: albumy wykonawcy, także te
+// w koszu, blokują usunięcie bez możliwości jego wymuszenia
+var artistIntegrityRules = []integrityRule{
+	{
+		resource: "albums",
+		reason:   "Wykonawca ma przypisane albumy (także w koszu)",
+		action:   restrictAction,
+		references: func(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+			albums, err := albumStore.List(ctx, store.AlbumFilter{ArtistIDs: []primitive.ObjectID{id}}, store.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			// Album z kosza można przywrócić, więc nadal wskazuje na wykonawcę
+			trashed, err := albumStore.ListTrash(ctx, store.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			for _, album := range trashed {
+				if album.ArtistID == id {
+					albums = append(albums, album)
+				}
+			}
+			return documentIDs(albums, func(album models.Album) primitive.ObjectID { return album.ID }), nil
+		},
+	},
+}
+
+// ownReference zwraca ID samego usuwanego dokumentu dla reguł, których akcja
+// obejmuje jedną operacją wszystkie wskazujące na niego dokumenty
+func ownReference(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return []primitive.ObjectID{id}, nil
+}
+
+func orderIDs(ctx context.Context, filter store.OrderFilter) ([]primitive.ObjectID, error) {
+	orders, err := orderStore.List(ctx, filter, store.ListOptions{})
+	return documentIDs(orders, func(order models.Order) primitive.ObjectID { return order.ID }), err
+}
+
+func reviewIDs(ctx context.Context, filter store.ReviewFilter) ([]primitive.ObjectID, error) {
+	reviews, err := reviewStore.List(ctx, filter, store.ListOptions{})
+	return documentIDs(reviews, func(review models.Review) primitive.ObjectID { return review.ID }), err
+}
+
+func documentIDs[T any](docs []T, id func(T) primitive.ObjectID) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		ids[i] = id(doc)
+	}
+	return ids
+}
+
+// referenceConflict zwracany jest, gdy usunięcie dokumentu blokują odwołania
+type referenceConflict struct {
+	references []models.BlockingReference
+	// Wszystkie blokujące reguły pozwalają na wymuszenie usunięcia
+	forceable bool
+}
+
+func (e *referenceConflict) Error() string {
+	message := "Usunięcie blokują odwołania z innych zasobów"
+	if e.forceable {
+		message += " (administrator może je wymusić parametrem force=true)"
+	}
+	return message
+}
+
+// queryForce odczytuje parametr force; wymuszenie usunięcia wymaga roli administratora
+func queryForce(c *gin.Context) (bool, error) {
+	errs := &validationError{}
+	force := queryBool(c, errs, "force")
+	if err := errs.errOrNil(); err != nil {
+		return false, err
+	}
+	if force == nil || !*force {
+		return false, nil
+	}
+	if c.GetString("userRole") != models.RoleAdmin {
+		return false, &requestError{http.StatusForbidden, "Tylko administrator może wymusić usunięcie"}
+	}
+	return true, nil
+}
+
+// checkReferences sprawdza reguły restrictAction dla usuwanego dokumentu id
+// i zwraca *referenceConflict z blokującymi odwołaniami. Przy force pomijane
+// są reguły, które pozwalają na wymuszenie.
+func checkReferences(ctx context.Context, rules []integrityRule, id primitive.ObjectID, force bool) error {
+	conflict := &referenceConflict{forceable: true}
+	for _, rule := range rules {
+		if rule.action != restrictAction || (force && rule.forceable) {
+			continue
+		}
+		ids, err := rule.references(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		listed := ids
+		if len(listed) > maxListedReferences {
+			listed = listed[:maxListedReferences]
+		}
+		conflict.references = append(conflict.references, models.BlockingReference{
+			Resource: rule.resource,
+			Reason:   rule.reason,
+			Count:    len(ids),
+			IDs:      listed,
+		})
+		conflict.forceable = conflict.forceable && rule.forceable
+	}
+	if len(conflict.references) > 0 {
+		return conflict
+	}
+	return nil
+}
+
+// applyIntegrityRules wykonuje reguły cascadeAction i anonymizeAction po
+// usunięciu dokumentu id (użytkowników – po trwałym usunięciu z kosza).
+// Dokumenty usunięte w międzyczasie są pomijane.
+func applyIntegrityRules(ctx context.Context, rules []integrityRule, id primitive.ObjectID) error {
+	var errs []error
+	for _, rule := range rules {
+		if rule.action == restrictAction {
+			continue
+		}
+		ids, err := rule.references(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", rule.resource, err))
+			continue
+		}
+		for _, refID := range ids {
+			if err := rule.apply(ctx, refID); err != nil && !errors.Is(err, store.ErrNotFound) {
+				errs = append(errs, fmt.Errorf("%s %s: %w", rule.resource, refID.Hex(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
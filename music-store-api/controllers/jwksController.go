@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"net/http"
+
+	"music-store-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Klucze mogą być buforowane krótko – nowy klucz podpisu należy opublikować
+// jako klucz weryfikacji z wyprzedzeniem dłuższym niż ten czas
+const jwksCacheControl = "public, max-age=300"
+
+// GetJWKS godoc
+// @Summary Klucze publiczne tokenów
+// @Description Zwraca klucze publiczne (JWKS, RFC 7517), którymi inne usługi mogą weryfikować tokeny dostępu wydane przez API. Klucz wybierany jest po nagłówku kid tokenu. Podczas rotacji zbiór zawiera również poprzedni i następny klucz podpisu. Przy podpisie HS256 zbiór jest pusty.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} config.JWKSet
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", jwksCacheControl)
+	c.JSON(http.StatusOK, config.PublicJWKS())
+}
@@ -1,15 +1,12 @@
 package controllers
 
 import (
-	"context"
-	"log"
-	"music-store-api/config"
 	"music-store-api/middleware"
 	"music-store-api/models"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // LoginRequest reprezentuje payload do logowania
@@ -18,14 +15,19 @@ type LoginRequest struct {
 	Password string `json:"password" example:"strongpassword"`
 }
 
-// LoginResponse reprezentuje odpowiedź po zalogowaniu (token JWT)
+// LoginResponse reprezentuje odpowiedź po zalogowaniu lub odświeżeniu sesji
 type LoginResponse struct {
+	// Krótkotrwały token dostępu (JWT)
 	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// Jednorazowy token odświeżania, wymieniany przez POST /token/refresh
+	RefreshToken string `json:"refresh_token" example:"dVfKA23WcIwu7cpS7GOH-zjO914zsCFjABhPLX6yKbI"`
+	// Czas ważności tokenu dostępu w sekundach
+	ExpiresIn int64 `json:"expires_in" example:"900"`
 }
 
 // Login godoc
 // @Summary Logowanie użytkownika
-// @Description Zwraca token JWT po poprawnym zalogowaniu
+// @Description Rozpoczyna sesję i zwraca krótkotrwały token dostępu JWT oraz token odświeżania (POST /token/refresh). Konta nieaktywne, w tym z niepotwierdzonym adresem e-mail, nie mogą się zalogować.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -33,6 +35,7 @@ type LoginResponse struct {
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} models.ErrorResponse "Niepoprawne dane"
 // @Failure 401 {object} models.ErrorResponse "Błędne dane logowania"
+// @Failure 403 {object} models.ErrorResponse "Konto nieaktywne (niepotwierdzony adres e-mail lub konto zablokowane)"
 // @Router /login [post]
 func Login(c *gin.Context) {
 	var credentials struct {
@@ -44,8 +47,10 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	err := config.DB.Collection("users").FindOne(context.Background(), bson.M{"email": credentials.Email}).Decode(&user)
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	user, err := userStore.GetByEmail(ctx, credentials.Email)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Nieprawidłowy email lub hasło"})
 		return
@@ -53,15 +58,19 @@ func Login(c *gin.Context) {
 
 	if !middleware.CheckPasswordHash(credentials.Password, user.PasswordHash) {
 		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Nieprawidłowy email lub hasło"})
-		log.Printf("User found: %s, PasswordHash: %s", user.Email, user.PasswordHash)
 		return
 	}
 
-	token, err := config.GenerateJWT(user.ID.Hex(), user.Role)
+	if !user.IsActive {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Konto nie jest aktywne"})
+		return
+	}
+
+	session, err := issueSession(ctx, user, primitive.NewObjectID())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Błąd generowania tokena"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, session)
 }
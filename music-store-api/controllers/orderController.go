@@ -2,21 +2,23 @@ package controllers
 
 import (
 	"context"
-	"music-store-api/config"
+	"errors"
+	"fmt"
+	"math"
 	"music-store-api/models"
+	"music-store-api/store"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var orderCollection *mongo.Collection
+var orderStore store.OrderStore
 
-func InitOrderCollection() {
-	orderCollection = config.DB.Collection("orders")
+func InitOrderStore(s store.OrderStore) {
+	orderStore = s
 }
 
 // GetOrders godoc
@@ -24,27 +26,53 @@ func InitOrderCollection() {
 // @Security BearerAuth
 // @Tags Orders
 // @Produce json
-// @Success 200 {array} models.Order
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param status query string false "Filtruj po statusie (kilka wartości po przecinku)"
+// @Param created_after query string false "Najwcześniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339"
+// @Param created_before query string false "Najpóźniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339"
+// @Param sort query string false "Sortowanie po polach (np. -created_at); dozwolone pola: created_at, updated_at, total, status"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista zamówień), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /orders [get]
 func GetOrders(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	listOrders(c, primitive.NilObjectID)
+}
 
-	cursor, err := orderCollection.Find(ctx, bson.M{})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania zamówień"})
+// Pola, po których można sortować zamówienia (nazwa w API → pole w dokumencie)
+var orderSortFields = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"total":      "total",
+	"status":     "status",
+}
+
+// listOrders odsyła stronę zamówień spełniających filtry z parametrów zapytania.
+// Niezerowe userID zawęża listę do zamówień tego użytkownika.
+func listOrders(c *gin.Context, userID primitive.ObjectID) {
+	errs := &validationError{}
+	filter := orderFilterFromQuery(c, errs)
+	filter.UserID = userID
+	lp := parseListQuery(c, orderSortFields, errs)
+	if err := errs.errOrNil(); err != nil {
+		respondError(c, err, "Błąd pobierania zamówień")
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var orders []models.Order
-	if err = cursor.All(ctx, &orders); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dekodowania danych"})
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	response, err := fetchPage(lp,
+		func(opts store.ListOptions) ([]models.Order, error) { return orderStore.List(ctx, filter, opts) },
+		func() (int64, error) { return orderStore.Count(ctx, filter) })
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania zamówień"})
 		return
 	}
 
-	c.JSON(http.StatusOK, orders)
+	c.JSON(http.StatusOK, response)
 }
 
 // GetOrderByID godoc
@@ -53,7 +81,10 @@ func GetOrders(c *gin.Context) {
 // @Tags Orders
 // @Produce json
 // @Param id path string true "ID zamówienia"
+// @Param If-None-Match header string false "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304"
 // @Success 200 {object} models.Order
+// @Header 200 {string} ETag "Wersja zasobu"
+// @Success 304 "Zasób nie zmienił się"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /orders/{id} [get]
@@ -65,17 +96,20 @@ func GetOrderByID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	var order models.Order
-	err = orderCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&order)
-	if err != nil {
+	order, err := orderStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania zamówienia"})
+		return
+	}
 
-	c.JSON(http.StatusOK, order)
+	respondVersioned(c, order.Version, order)
 }
 
 // GetOrdersByUserID godoc
@@ -84,8 +118,16 @@ func GetOrderByID(c *gin.Context) {
 // @Tags Orders
 // @Produce json
 // @Param userID path string true "ID użytkownika"
-// @Success 200 {array} models.Order
-// @Failure 404 {object} models.ErrorResponse
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param status query string false "Filtruj po statusie (kilka wartości po przecinku)"
+// @Param created_after query string false "Najwcześniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339"
+// @Param created_before query string false "Najpóźniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339"
+// @Param sort query string false "Sortowanie po polach (np. -created_at); dozwolone pola: created_at, updated_at, total, status"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista zamówień), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /orders/user/{userID} [get]
 func GetOrdersByUserID(c *gin.Context) {
 	userIDParam := c.Param("userID")
@@ -95,70 +137,187 @@ func GetOrdersByUserID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cursor, err := orderCollection.Find(ctx, bson.M{"user_id": userID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania zamówień"})
-		return
-	}
-	defer cursor.Close(ctx)
-
-	var orders []models.Order
-	if err = cursor.All(ctx, &orders); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dekodowania danych"})
-		return
-	}
-
-	c.JSON(http.StatusOK, orders)
+	listOrders(c, userID)
 }
 
 // CreateOrder godoc
 // @Summary Utwórz nowe zamówienie
 // @Security BearerAuth
+// @Description Tworzy zamówienie zalogowanego użytkownika. Pozycja wskazuje wersję albumu polem variant_id, które można pominąć, gdy album ma jedną wersję. Ceny pozycji, suma i status (pending) wyliczane są po stronie serwera na podstawie aktualnych danych wersji. Stan magazynowy wersji zmniejszany jest w jednej transakcji z zapisem zamówienia.
 // @Tags Orders
 // @Accept json
 // @Produce json
-// @Param order body models.Order true "Nowe zamówienie"
+// @Param order body models.CreateOrderRequest true "Zamawiane albumy i dane wysyłki"
 // @Success 201 {object} models.Order
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Niewystarczająca liczba sztuk"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /orders [post]
 func CreateOrder(c *gin.Context) {
-	var order models.Order
-	if err := c.ShouldBindJSON(&order); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane"})
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
 		return
 	}
 
-	order.ID = primitive.NewObjectID()
-	order.CreatedAt = time.Now()
-	order.UpdatedAt = time.Now()
+	var req models.CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane"})
+		return
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	_, err := orderCollection.InsertOne(ctx, order)
+	order, err := placeOrder(ctx, userID, req.Items, req.Shipping)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd tworzenia zamówienia"})
+		respondError(c, err, "Błąd tworzenia zamówienia")
 		return
 	}
 
 	c.JSON(http.StatusCreated, order)
 }
 
+// placeOrder wycenia pozycje i zapisuje nowe zamówienie użytkownika ze
+// statusem pending, rezerwując sztuki w magazynie
+func placeOrder(ctx context.Context, userID primitive.ObjectID, requested []models.OrderItemRequest, shipping models.ShippingDetails) (models.Order, error) {
+	items, total, err := priceOrderItems(ctx, requested)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	now := time.Now()
+	order := models.Order{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Items:     items,
+		Total:     total,
+		Status:    models.OrderStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+		Shipping:  shipping,
+		History: []models.StatusHistory{
+			{To: models.OrderStatusPending, ActorID: userID, ChangedAt: now},
+		},
+	}
+
+	err = orderStore.Place(ctx, order)
+	var stockErr *store.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		return models.Order{}, &requestError{http.StatusConflict, fmt.Sprintf("Niewystarczająca liczba sztuk albumu %s w wersji %s", stockErr.AlbumID.Hex(), stockErr.SKU)}
+	}
+	if err != nil {
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+// priceOrderItems wycenia pozycje zamówienia według aktualnych cen wersji albumów.
+// Pozycje dotyczące tej samej wersji są łączone, a ilości sprawdzane ze stanem magazynowym.
+func priceOrderItems(ctx context.Context, requested []models.OrderItemRequest) ([]models.OrderItem, float64, error) {
+	if len(requested) == 0 {
+		return nil, 0, &requestError{http.StatusBadRequest, "Zamówienie musi zawierać co najmniej jedną pozycję"}
+	}
+
+	type line struct {
+		album    models.Album
+		variant  models.Variant
+		quantity int
+	}
+	var lines []*line
+	byVariant := map[primitive.ObjectID]*line{}
+	albums := map[primitive.ObjectID]models.Album{}
+	for _, item := range requested {
+		if item.AlbumID.IsZero() {
+			return nil, 0, &requestError{http.StatusBadRequest, "Każda pozycja musi zawierać album_id"}
+		}
+		if item.Quantity < 1 {
+			return nil, 0, &requestError{http.StatusBadRequest, "Ilość musi być dodatnia"}
+		}
+
+		album, seen := albums[item.AlbumID]
+		if !seen {
+			var err error
+			album, err = albumStore.GetByID(ctx, item.AlbumID)
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, 0, &requestError{http.StatusBadRequest, fmt.Sprintf("Album %s nie istnieje", item.AlbumID.Hex())}
+			}
+			if err != nil {
+				return nil, 0, err
+			}
+			albums[item.AlbumID] = album
+		}
+		variant, err := selectVariant(album, item.VariantID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if l, ok := byVariant[variant.ID]; ok {
+			l.quantity += item.Quantity
+			continue
+		}
+		l := &line{album: album, variant: variant, quantity: item.Quantity}
+		byVariant[variant.ID] = l
+		lines = append(lines, l)
+	}
+
+	items := make([]models.OrderItem, 0, len(lines))
+	var total float64
+	for _, l := range lines {
+		if l.quantity > l.variant.Stock {
+			return nil, 0, &requestError{http.StatusConflict, fmt.Sprintf("Niewystarczająca liczba sztuk albumu %q w wersji %s (dostępne: %d)", l.album.Title, l.variant.SKU, l.variant.Stock)}
+		}
+
+		items = append(items, models.OrderItem{
+			AlbumID:   l.album.ID,
+			VariantID: l.variant.ID,
+			Format:    l.variant.Format,
+			SKU:       l.variant.SKU,
+			Quantity:  l.quantity,
+			Price:     l.variant.Price,
+		})
+		total += l.variant.Price * float64(l.quantity)
+	}
+
+	return items, roundPrice(total), nil
+}
+
+// selectVariant zwraca wersję albumu o podanym ID, a bez ID – jedyną wersję albumu
+func selectVariant(album models.Album, variantID primitive.ObjectID) (models.Variant, error) {
+	if variantID.IsZero() {
+		if len(album.Variants) != 1 {
+			return models.Variant{}, &requestError{http.StatusBadRequest, fmt.Sprintf("Album %q ma kilka wersji – podaj variant_id", album.Title)}
+		}
+		return album.Variants[0], nil
+	}
+	variant, ok := album.Variant(variantID)
+	if !ok {
+		return models.Variant{}, &requestError{http.StatusBadRequest, fmt.Sprintf("Album %q nie ma wersji %s", album.Title, variantID.Hex())}
+	}
+	return variant, nil
+}
+
+// roundPrice zaokrągla kwotę do pełnych groszy
+func roundPrice(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
 // UpdateOrder godoc
-// @Summary Zaktualizuj zamówienie (np. status)
+// @Summary Zaktualizuj zamówienie
 // @Security BearerAuth
+// @Description Aktualizuje dane wysyłki zamówienia; pozostałe pola treści są pomijane. Status zmienia PATCH /orders/{id}/status, a pozycje, suma, właściciel i data utworzenia wyliczane są przy składaniu zamówienia razem z rezerwacją sztuk w magazynie. Dane wysyłki są wymagane, a ID w treści (jeśli podane) musi być zgodne z ID w ścieżce.
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param id path string true "ID zamówienia"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
 // @Param order body models.Order true "Dane zamówienia do aktualizacji"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /orders/{id} [put]
 func UpdateOrder(c *gin.Context) {
@@ -175,25 +334,53 @@ func UpdateOrder(c *gin.Context) {
 		return
 	}
 
+	if !order.ID.IsZero() && order.ID != objID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID w treści nie zgadza się z ID w ścieżce"})
+		return
+	}
+	if order.Shipping == (models.ShippingDetails{}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brak danych wysyłki"})
+		return
+	}
+
 	order.UpdatedAt = time.Now()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	update := bson.M{
-		"$set": order,
+	version, err := ifMatchVersion(c, func() (int64, error) {
+		current, err := orderStore.GetByID(ctx, objID)
+		return current.Version, err
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
+		return
 	}
-
-	result, err := orderCollection.UpdateByID(ctx, objID, update)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji"})
+		respondError(c, err, "Błąd aktualizacji")
 		return
 	}
 
-	if result.MatchedCount == 0 {
+	// Zmieniane są wyłącznie dane wysyłki – status zmienia PATCH /orders/:id/status,
+	// a pozycje i suma są wyceniane przy składaniu zamówienia razem z rezerwacją sztuk
+	update := bson.M{
+		"shipping":   order.Shipping,
+		"updated_at": order.UpdatedAt,
+	}
+
+	err = orderStore.Update(ctx, objID, version, update)
+	if errors.Is(err, store.ErrConflict) {
+		respondError(c, versionConflict(c), "Błąd aktualizacji")
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Zamówienie zaktualizowane"})
 }
@@ -201,12 +388,16 @@ func UpdateOrder(c *gin.Context) {
 // DeleteOrder godoc
 // @Summary Usuń zamówienie
 // @Security BearerAuth
+// @Description Przenosi zamówienie do kosza (GET /trash/orders); przywrócenie przez POST /orders/{id}/restore. Usunąć można tylko zakończone zamówienie (completed lub cancelled) – zamówienie w realizacji rezerwuje sztuki w magazynie i należy je najpierw anulować.
 // @Tags Orders
 // @Produce json
 // @Param id path string true "ID zamówienia"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Zamówienie jest w realizacji"
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /orders/{id} [delete]
 func DeleteOrder(c *gin.Context) {
@@ -217,34 +408,51 @@ func DeleteOrder(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	result, err := orderCollection.DeleteOne(ctx, bson.M{"_id": objID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd usuwania zamówienia"})
-		return
+	order, err := orderStore.GetByID(ctx, objID)
+	if err == nil {
+		err = checkIfMatch(c, order.Version)
 	}
-
-	if result.DeletedCount == 0 {
+	if err == nil && models.IsOpenOrderStatus(order.Status) {
+		err = &requestError{http.StatusConflict, "Zamówienie jest w realizacji – anuluj je przed usunięciem, aby zwrócić sztuki do magazynu"}
+	}
+	// Usunięcie warunkowe na odczytanej wersji, aby zamówienie nie zmieniło
+	// statusu między sprawdzeniem a przeniesieniem do kosza
+	if err == nil {
+		err = orderStore.Delete(ctx, objID, order.Version)
+	}
+	if errors.Is(err, store.ErrConflict) {
+		err = versionConflict(c)
+	}
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
 		return
 	}
+	if err != nil {
+		respondError(c, err, "Błąd usuwania zamówienia")
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Zamówienie usunięte"})
+	c.JSON(http.StatusOK, gin.H{"message": "Zamówienie przeniesione do kosza"})
 }
 
 // UpdateOrderStatus godoc
 // @Summary Zaktualizuj status zamówienia
 // @Security BearerAuth
+// @Description Zmienia status zgodnie z dozwolonymi przejściami: pending→processing→shipped→completed; anulowanie (cancelled) możliwe tylko przed wysyłką. Każda zmiana zapisywana jest w historii zamówienia. Anulowanie zwraca zarezerwowane sztuki do magazynu w jednej transakcji ze zmianą statusu.
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param id path string true "ID zamówienia"
-// @Param status body map[string]string true "Nowy status zamówienia"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
+// @Param status body models.UpdateOrderStatusRequest true "Nowy status zamówienia i opcjonalna notatka"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Niedozwolona zmiana statusu"
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /orders/{id}/status [patch]
 func UpdateOrderStatus(c *gin.Context) {
@@ -255,45 +463,108 @@ func UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	var body struct {
-		Status string `json:"status"`
-	}
-	if err := c.ShouldBindJSON(&body); err != nil || body.Status == "" {
+	var body models.UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&body); err != nil || !models.IsValidOrderStatus(body.Status) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawny status"})
 		return
 	}
 
-	if body.Status != models.OrderStatusPending &&
-		body.Status != models.OrderStatusProcessing &&
-		body.Status != models.OrderStatusShipped &&
-		body.Status != models.OrderStatusCompleted &&
-		body.Status != models.OrderStatusCancelled {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawny status"})
+	actorID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	update := bson.M{
-		"$set": bson.M{
-			"status":     body.Status,
-			"updated_at": time.Now(),
-		},
+	order, err := orderStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania zamówienia"})
+		return
 	}
 
-	result, err := orderCollection.UpdateByID(ctx, objID, update)
+	version, err := ifMatchVersion(c, func() (int64, error) { return order.Version, nil })
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji statusu"})
+		respondError(c, err, "Błąd aktualizacji statusu")
 		return
 	}
+	if err := changeOrderStatus(ctx, order, version, body.Status, actorID, body.Note); err != nil {
+		respondError(c, err, "Błąd aktualizacji statusu")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Status zamówienia zaktualizowany"})
+}
 
-	if result.MatchedCount == 0 {
+// GetOrderHistory godoc
+// @Summary Pobierz historię statusów zamówienia
+// @Security BearerAuth
+// @Tags Orders
+// @Produce json
+// @Param id path string true "ID zamówienia"
+// @Success 200 {array} models.StatusHistory
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /orders/{id}/history [get]
+func GetOrderHistory(c *gin.Context) {
+	idParam := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	order, err := orderStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania zamówienia"})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Status zamówienia zaktualizowany"})
+	history := order.History
+	if history == nil {
+		history = []models.StatusHistory{}
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// changeOrderStatus przeprowadza zamówienie do statusu to zgodnie z tabelą
+// dozwolonych przejść i zapisuje zmianę w historii. Przy version innym niż
+// store.AnyVersion (If-Match) zmiana równoległa daje 412 zamiast 409.
+func changeOrderStatus(ctx context.Context, order models.Order, version int64, to string, actorID primitive.ObjectID, note string) error {
+	if !models.CanTransitionOrderStatus(order.Status, to) {
+		return &requestError{http.StatusConflict, fmt.Sprintf("Niedozwolona zmiana statusu z %s na %s", order.Status, to)}
+	}
+
+	err := orderStore.TransitionStatus(ctx, order.ID, version, models.StatusHistory{
+		From:      order.Status,
+		To:        to,
+		ActorID:   actorID,
+		ChangedAt: time.Now(),
+		Note:      note,
+	})
+	if errors.Is(err, store.ErrConflict) && version != store.AnyVersion {
+		return errPreconditionFailed
+	}
+	if errors.Is(err, store.ErrConflict) {
+		return &requestError{http.StatusConflict, "Status zamówienia został w międzyczasie zmieniony, spróbuj ponownie"}
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return &requestError{http.StatusNotFound, "Zamówienie nie znalezione"}
+	}
+	return err
 }
 
 // UpdateOrderShipping godoc
@@ -303,10 +574,12 @@ func UpdateOrderStatus(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID zamówienia"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
 // @Param shipping body models.ShippingDetails true "Nowe dane wysyłki"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /orders/{id}/shipping [put]
 func UpdateOrderShipping(c *gin.Context) {
@@ -323,26 +596,165 @@ func UpdateOrderShipping(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
+	version, err := ifMatchVersion(c, func() (int64, error) {
+		current, err := orderStore.GetByID(ctx, objID)
+		return current.Version, err
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji danych wysyłki")
+		return
+	}
+
 	update := bson.M{
-		"$set": bson.M{
-			"shipping":   shipping,
-			"updated_at": time.Now(),
-		},
+		"shipping":   shipping,
+		"updated_at": time.Now(),
 	}
 
-	result, err := orderCollection.UpdateByID(ctx, objID, update)
+	err = orderStore.Update(ctx, objID, version, update)
+	if errors.Is(err, store.ErrConflict) {
+		respondError(c, versionConflict(c), "Błąd aktualizacji danych wysyłki")
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji danych wysyłki"})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Zamówienie nie znalezione"})
+	c.JSON(http.StatusOK, gin.H{"message": "Dane wysyłki zaktualizowane"})
+}
+
+// GetMyOrders godoc
+// @Summary Pobierz zamówienia zalogowanego użytkownika
+// @Security BearerAuth
+// @Tags Orders
+// @Produce json
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param status query string false "Filtruj po statusie (kilka wartości po przecinku)"
+// @Param created_after query string false "Najwcześniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339"
+// @Param created_before query string false "Najpóźniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339"
+// @Param sort query string false "Sortowanie po polach (np. -created_at); dozwolone pola: created_at, updated_at, total, status"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista zamówień), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /orders/me [get]
+func GetMyOrders(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Dane wysyłki zaktualizowane"})
+	listOrders(c, userID)
+}
+
+// GetMyOrderByID godoc
+// @Summary Pobierz zamówienie zalogowanego użytkownika po ID
+// @Security BearerAuth
+// @Tags Orders
+// @Produce json
+// @Param id path string true "ID zamówienia"
+// @Param If-None-Match header string false "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304"
+// @Success 200 {object} models.Order
+// @Header 200 {string} ETag "Wersja zasobu"
+// @Success 304 "Zasób nie zmienił się"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /orders/me/{id} [get]
+func GetMyOrderByID(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	order, err := getOwnOrder(ctx, c.Param("id"), userID)
+	if err != nil {
+		respondError(c, err, "Błąd pobierania zamówienia")
+		return
+	}
+
+	respondVersioned(c, order.Version, order)
+}
+
+// CancelMyOrder godoc
+// @Summary Anuluj zamówienie zalogowanego użytkownika
+// @Security BearerAuth
+// @Description Anuluje własne zamówienie, dopóki ma status pending. Zarezerwowane sztuki wracają do magazynu.
+// @Tags Orders
+// @Produce json
+// @Param id path string true "ID zamówienia"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Zamówienie nie ma już statusu pending"
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /orders/me/{id}/cancel [post]
+func CancelMyOrder(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	order, err := getOwnOrder(ctx, c.Param("id"), userID)
+	if err != nil {
+		respondError(c, err, "Błąd pobierania zamówienia")
+		return
+	}
+	version, err := ifMatchVersion(c, func() (int64, error) { return order.Version, nil })
+	if err != nil {
+		respondError(c, err, "Błąd anulowania zamówienia")
+		return
+	}
+	if order.Status != models.OrderStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Można anulować tylko zamówienie o statusie pending"})
+		return
+	}
+
+	if err := changeOrderStatus(ctx, order, version, models.OrderStatusCancelled, userID, "Anulowane przez klienta"); err != nil {
+		respondError(c, err, "Błąd anulowania zamówienia")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Zamówienie anulowane"})
+}
+
+// getOwnOrder pobiera zamówienie należące do użytkownika userID. Cudze
+// zamówienia traktowane są jak nieistniejące, aby nie ujawniać ich ID.
+func getOwnOrder(ctx context.Context, idParam string, userID primitive.ObjectID) (models.Order, error) {
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return models.Order{}, &requestError{http.StatusBadRequest, "Niepoprawne ID"}
+	}
+
+	order, err := orderStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && order.UserID != userID) {
+		return models.Order{}, &requestError{http.StatusNotFound, "Zamówienie nie znalezione"}
+	}
+	return order, err
 }
@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Maksymalna liczba elementów na stronie; większe wartości limit są obcinane
+const maxPageLimit = 100
+
+// parsePaging odczytuje parametry page i limit, stosując wartości domyślne dla
+// niepoprawnych i ograniczając limit do maxPageLimit
+func parsePaging(c *gin.Context) (page, limit int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return page, limit
+}
+
+// parseListQuery odczytuje parametry stronicowania i sortowania wspólne dla
+// wszystkich list: page, limit, sort i cursor
+func parseListQuery(c *gin.Context, allowedSort map[string]string, errs *validationError) listPage {
+	page, limit := parsePaging(c)
+	return parseListPage(c.Query("sort"), c.Query("cursor"), page, limit, allowedSort, errs)
+}
+
+// fetchPage pobiera stronę listy i zwraca kopertę odpowiedzi: page (tylko bez
+// kursora), limit, total, data oraz next_cursor i prev_cursor
+func fetchPage[T any](lp listPage, list func(opts store.ListOptions) ([]T, error), count func() (int64, error)) (gin.H, error) {
+	docs, err := list(lp.options())
+	if err != nil {
+		return nil, err
+	}
+	docs, next, prev, err := trimPage(lp, docs)
+	if err != nil {
+		return nil, err
+	}
+	total, err := count()
+	if err != nil {
+		return nil, err
+	}
+
+	response := gin.H{
+		"limit":       lp.Limit,
+		"total":       total,
+		"data":        docs,
+		"next_cursor": next,
+		"prev_cursor": prev,
+	}
+	if lp.Cursor == nil {
+		response["page"] = lp.Page
+	}
+	return response, nil
+}
+
+// pageCursor jest zawartością nieprzezroczystych tokenów next_cursor i prev_cursor.
+// Zapamiętuje sortowanie, dla którego powstał, oraz wartości klucza sortowania
+// i _id dokumentu na granicy strony.
+type pageCursor struct {
+	Sort   string             `bson:"s"`
+	Values []bson.RawValue    `bson:"v"`
+	ID     primitive.ObjectID `bson:"i"`
+	Before bool               `bson:"b,omitempty"`
+}
+
+var errInvalidCursor = errors.New("niepoprawny kursor")
+
+func encodeCursor(cursor pageCursor) (string, error) {
+	data, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(token string) (pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	var cursor pageCursor
+	if err := bson.Unmarshal(data, &cursor); err != nil || cursor.ID.IsZero() {
+		return pageCursor{}, errInvalidCursor
+	}
+	return cursor, nil
+}
+
+// storeCursor przekłada token na kursor repozytorium
+func (p pageCursor) storeCursor() *store.Cursor {
+	values := make([]interface{}, len(p.Values))
+	for i, value := range p.Values {
+		values[i] = value
+	}
+	return &store.Cursor{Values: values, ID: p.ID, Before: p.Before}
+}
+
+// Wartość zapisywana w kursorze dla brakującego pola sortowania; MongoDB
+// sortuje brakujące pola tak jak null
+var nullValue = bson.RawValue{Type: bsontype.Null}
+
+// cursorAt tworzy token wskazujący na dokument doc w kolejności fields
+func cursorAt(doc interface{}, sort string, fields []store.SortField, before bool) (*string, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	cursor := pageCursor{Sort: sort, Before: before}
+	for _, field := range fields {
+		value, err := bson.Raw(raw).LookupErr(strings.Split(field.Field, ".")...)
+		if errors.Is(err, bsoncore.ErrElementNotFound) {
+			value = nullValue
+		} else if err != nil {
+			return nil, err
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+	id, ok := bson.Raw(raw).Lookup("_id").ObjectIDOK()
+	if !ok {
+		return nil, errors.New("dokument bez _id")
+	}
+	cursor.ID = id
+
+	token, err := encodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// parseSort odczytuje parametr sort (np. "price,-title"), dopuszczając tylko
+// pola z allowed (nazwa w API → pole w dokumencie)
+func parseSort(value string, allowed map[string]string, errs *validationError) []store.SortField {
+	var fields []store.SortField
+	for _, name := range splitQueryList(value) {
+		desc := strings.HasPrefix(name, "-")
+		field, ok := allowed[strings.TrimPrefix(name, "-")]
+		if !ok {
+			errs.add("sort", name, "Niedozwolone pole sortowania (dozwolone: "+strings.Join(sortedKeys(allowed), ", ")+")")
+			continue
+		}
+		fields = append(fields, store.SortField{Field: field, Desc: desc})
+	}
+	return fields
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatSort zapisuje sortowanie w postaci kanonicznej parametru sort
+func formatSort(fields []store.SortField, allowed map[string]string) string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		for name, docField := range allowed {
+			if docField == field.Field {
+				if field.Desc {
+					name = "-" + name
+				}
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// listPage opisuje sposób stronicowania listy: numer strony albo kursor
+type listPage struct {
+	Page   int
+	Limit  int
+	Sort   string
+	Fields []store.SortField
+	Cursor *pageCursor
+}
+
+// parseListPage odczytuje parametry sort i cursor. Kursor przenosi sortowanie,
+// z którym powstał; jawny parametr sort musi być z nim zgodny.
+func parseListPage(sortParam, cursorParam string, page, limit int, allowed map[string]string, errs *validationError) listPage {
+	lp := listPage{Page: page, Limit: limit}
+	lp.Fields = parseSort(sortParam, allowed, errs)
+	lp.Sort = formatSort(lp.Fields, allowed)
+
+	if cursorParam == "" {
+		return lp
+	}
+	cursor, err := decodeCursor(cursorParam)
+	if err != nil {
+		errs.add("cursor", cursorParam, "Niepoprawny kursor")
+		return lp
+	}
+	if sortParam != "" && lp.Sort != cursor.Sort {
+		errs.add("sort", sortParam, "Sortowanie musi być takie samo jak przy pobraniu kursora ("+cursor.Sort+")")
+		return lp
+	}
+	lp.Fields = parseSort(cursor.Sort, allowed, errs)
+	if len(lp.Fields) != len(cursor.Values) {
+		errs.add("cursor", cursorParam, "Niepoprawny kursor")
+		return lp
+	}
+	lp.Sort = cursor.Sort
+	lp.Cursor = &cursor
+	return lp
+}
+
+// options zwraca opcje listy pobierające o jeden element więcej niż limit,
+// aby sprawdzić, czy istnieje kolejna strona w kierunku przeglądania
+func (lp listPage) options() store.ListOptions {
+	opts := store.ListOptions{Sort: lp.Fields, Limit: int64(lp.Limit) + 1}
+	if lp.Cursor != nil {
+		opts.Cursor = lp.Cursor.storeCursor()
+	} else {
+		opts.Skip = int64((lp.Page - 1) * lp.Limit)
+	}
+	return opts
+}
+
+// trimPage obcina nadmiarowy element pobrany według options i zwraca tokeny
+// sąsiednich stron (nil, gdy strona nie istnieje)
+func trimPage[T any](lp listPage, docs []T) (page []T, next, prev *string, err error) {
+	backward := lp.Cursor != nil && lp.Cursor.Before
+	hasMore := len(docs) > lp.Limit
+	if hasMore {
+		if backward {
+			docs = docs[1:]
+		} else {
+			docs = docs[:lp.Limit]
+		}
+	}
+	if len(docs) == 0 {
+		return docs, nil, nil, nil
+	}
+
+	hasNext := (!backward && hasMore) || backward
+	hasPrev := (backward && hasMore) || (lp.Cursor != nil && !backward) || (lp.Cursor == nil && lp.Page > 1)
+	if hasNext {
+		if next, err = cursorAt(docs[len(docs)-1], lp.Sort, lp.Fields, false); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if hasPrev {
+		if prev, err = cursorAt(docs[0], lp.Sort, lp.Fields, true); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return docs, next, prev, nil
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"music-store-api/config"
+	"music-store-api/mailer"
+	"music-store-api/middleware"
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ForgotPasswordRequest reprezentuje prośbę o reset hasła
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+// ResetPasswordRequest reprezentuje ustawienie nowego hasła tokenem z wiadomości
+type ResetPasswordRequest struct {
+	Token       string `json:"token" example:"dVfKA23WcIwu7cpS7GOH-zjO914zsCFjABhPLX6yKbI"`
+	NewPassword string `json:"new_password" example:"newstrongpassword"`
+}
+
+// ForgotPassword godoc
+// @Summary Prośba o reset hasła
+// @Description Wysyła na adres konta jednorazowy token resetu hasła (ważny domyślnie godzinę). Odpowiedź jest zawsze 202, niezależnie od tego, czy konto istnieje.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Adres e-mail konta"
+// @Success 202 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse "Niepoprawne dane"
+// @Router /password/forgot [post]
+func ForgotPassword(c *gin.Context) {
+	var request ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
+		return
+	}
+
+	// Błędy są tylko logowane, aby odpowiedź nie zdradzała, które adresy mają konta
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	if err := sendPasswordReset(email); err != nil {
+		log.Printf("Błąd wysyłki tokenu resetu hasła do %s: %v", email, err)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Jeśli konto z tym adresem istnieje, wysłano na nie instrukcję resetu hasła"})
+}
+
+// sendPasswordReset wysyła token resetu hasła, jeśli konto z adresem email istnieje
+func sendPasswordReset(email string) error {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	user, err := userStore.GetByEmail(ctx, email)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ttl := config.App.Security.PasswordResetTokenTTL.Std()
+	token, err := issueUserToken(ctx, user.ID, models.TokenPurposePasswordReset, ttl)
+	if err != nil {
+		return err
+	}
+	return mailSender.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset hasła w Music Store",
+		Body: "Dzień dobry " + user.FirstName + ",\n\n" +
+			"otrzymaliśmy prośbę o reset hasła do konta w Music Store. Token resetu hasła:\n\n" +
+			"token=" + token + "\n\n" +
+			"Aby ustawić nowe hasło, wyślij token i nowe hasło (pola token i new_password) w żądaniu POST " +
+			strings.TrimSuffix(config.App.Mail.BaseURL, "/") + "/password/reset. " +
+			"Token jest jednorazowy i ważny przez " + ttl.String() + ". " +
+			"Jeśli to nie Ty prosisz o reset, zignoruj tę wiadomość – hasło pozostanie bez zmian.\n",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Ustawienie nowego hasła
+// @Description Ustawia nowe hasło (co najmniej 8 znaków) za pomocą tokenu z wiadomości wysłanej przez POST /password/forgot. Token jest jednorazowy, a wszystkie tokeny JWT i tokeny odświeżania wydane wcześniej użytkownikowi przestają być ważne.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Token resetu i nowe hasło"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse "Niepoprawne dane lub token nieprawidłowy, użyty albo wygasły"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /password/reset [post]
+func ResetPassword(c *gin.Context) {
+	var request ResetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
+		return
+	}
+	if len(request.NewPassword) < minPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hasło musi mieć co najmniej 8 znaków"})
+		return
+	}
+	hashedPassword, err := middleware.HashPassword(request.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd haszowania hasła"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	reset, err := tokenStore.Consume(ctx, models.TokenPurposePasswordReset, hashToken(request.Token))
+	if err == nil {
+		now := time.Now()
+		err = userStore.Update(ctx, reset.UserID, store.AnyVersion, bson.M{
+			"password_hash":     hashedPassword,
+			"tokens_revoked_at": now,
+			"updated_at":        now,
+		})
+	}
+	if err == nil {
+		err = refreshTokenStore.RevokeUser(ctx, reset.UserID)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token resetu hasła jest nieprawidłowy lub wygasł"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd resetu hasła"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hasło zostało zmienione, zaloguj się ponownie"})
+}
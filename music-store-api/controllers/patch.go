@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Typy treści przyjmowane przez endpointy PATCH
+const (
+	contentTypeJSON       = "application/json"
+	contentTypeMergePatch = "application/merge-patch+json"
+	contentTypeJSONPatch  = "application/json-patch+json"
+)
+
+// patchField opisuje pole zasobu, które można zmienić przez PATCH
+type patchField struct {
+	// Klucz pola w dokumencie (BSON)
+	bson string
+	// Pole można usunąć wartością null (merge patch) lub operacją remove (JSON Patch)
+	optional bool
+}
+
+// applyPatch nakłada treść żądania na zasób current i dekoduje wynik do target.
+// Content-Type application/merge-patch+json (oraz application/json) oznacza
+// JSON Merge Patch (RFC 7396), a application/json-patch+json – JSON Patch
+// (RFC 6902). Zwraca posortowaną listę zmienionych pól (kluczy JSON); zmiana
+// pola spoza fields lub usunięcie pola wymaganego kończy się błędem 400.
+func applyPatch(c *gin.Context, current, target interface{}, fields map[string]patchField) ([]string, error) {
+	body, err := c.GetRawData()
+	if err != nil {
+		return nil, &requestError{http.StatusBadRequest, "Niepoprawne dane wejściowe"}
+	}
+	original, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch contentType := c.ContentType(); contentType {
+	case "", contentTypeJSON, contentTypeMergePatch:
+		if !isJSONObject(body) {
+			return nil, &requestError{http.StatusBadRequest, "Treść merge patch musi być obiektem JSON"}
+		}
+		patched, err = jsonpatch.MergePatch(original, body)
+		if err != nil {
+			return nil, &requestError{http.StatusBadRequest, "Niepoprawne dane wejściowe"}
+		}
+	case contentTypeJSONPatch:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, &requestError{http.StatusBadRequest, "Niepoprawny dokument JSON Patch"}
+		}
+		patched, err = patch.Apply(original)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, &requestError{http.StatusConflict, "Operacja test dokumentu JSON Patch nie powiodła się"}
+		}
+		if err != nil {
+			return nil, &requestError{http.StatusBadRequest, "Nie można zastosować dokumentu JSON Patch: " + err.Error()}
+		}
+	default:
+		return nil, &requestError{http.StatusUnsupportedMediaType, fmt.Sprintf(
+			"Nieobsługiwany typ treści %q (dozwolone: %s, %s, %s)",
+			contentType, contentTypeMergePatch, contentTypeJSONPatch, contentTypeJSON)}
+	}
+
+	changed, err := changedFields(original, patched)
+	if err != nil {
+		return nil, &requestError{http.StatusBadRequest, "Wynik aktualizacji musi być obiektem JSON"}
+	}
+	for _, key := range changed {
+		field, ok := fields[key]
+		if !ok {
+			return nil, &requestError{http.StatusBadRequest, fmt.Sprintf("Pole %s nie może być zmieniane", key)}
+		}
+		if !field.optional && isNullField(patched, key) {
+			return nil, &requestError{http.StatusBadRequest, fmt.Sprintf("Pole %s jest wymagane i nie może zostać usunięte", key)}
+		}
+	}
+
+	if err := json.Unmarshal(patched, target); err != nil {
+		return nil, &requestError{http.StatusBadRequest, "Niepoprawne dane wejściowe"}
+	}
+	return changed, nil
+}
+
+// patchUpdate buduje mapę aktualizacji repozytorium dla zmienionych pól
+// dokumentu doc. Pola, których nie ma w dokumencie po zmianie, otrzymują
+// wartość nil, dzięki czemu repozytorium usuwa je z dokumentu.
+func patchUpdate(doc interface{}, changed []string, fields map[string]patchField) (bson.M, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var values bson.M
+	if err := bson.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{}
+	for _, key := range changed {
+		name := fields[key].bson
+		update[name] = values[name]
+	}
+	return update, nil
+}
+
+// changedFields porównuje pola najwyższego poziomu dwóch obiektów JSON;
+// brak pola i wartość null traktowane są tak samo
+func changedFields(original, patched []byte) ([]string, error) {
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil || after == nil {
+		return nil, errors.New("wynik nie jest obiektem JSON")
+	}
+
+	var changed []string
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			changed = append(changed, key)
+		}
+	}
+	for key, value := range before {
+		if _, ok := after[key]; !ok && value != nil {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// isNullField sprawdza, czy pole obiektu JSON jest nieobecne lub równe null
+func isNullField(doc []byte, key string) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return false
+	}
+	value, ok := fields[key]
+	return !ok || string(value) == "null"
+}
+
+func isJSONObject(data []byte) bool {
+	var object map[string]json.RawMessage
+	return json.Unmarshal(data, &object) == nil && object != nil
+}
+
+// containsField sprawdza, czy lista zmienionych pól zawiera pole key
+func containsField(changed []string, key string) bool {
+	i := sort.SearchStrings(changed, key)
+	return i < len(changed) && changed[i] == key
+}
+
+// withFields dopisuje do posortowanej listy zmienionych pól brakujące klucze
+func withFields(changed []string, keys ...string) []string {
+	for _, key := range keys {
+		if !containsField(changed, key) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
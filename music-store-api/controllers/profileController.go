@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"music-store-api/middleware"
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Pola profilu, które zalogowany użytkownik może zmienić przez PATCH /me.
+// Rola, aktywność konta, e-mail i hasło nie mogą być zmieniane tą drogą.
+var profilePatchFields = map[string]patchField{
+	"first_name":       {bson: "first_name"},
+	"last_name":        {bson: "last_name"},
+	"phone_number":     {bson: "phone_number", optional: true},
+	"shipping_details": {bson: "shipping_details", optional: true},
+}
+
+// ChangePasswordRequest reprezentuje zmianę hasła przez zalogowanego użytkownika
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" example:"oldpassword"`
+	NewPassword     string `json:"new_password" example:"newstrongpassword"`
+}
+
+// currentUser pobiera konto zalogowanego użytkownika wskazane przez userID z tokenu
+func currentUser(ctx context.Context, c *gin.Context) (models.User, error) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return models.User{}, &requestError{http.StatusUnauthorized, "Nieprawidłowy identyfikator użytkownika w tokenie"}
+	}
+	user, err := userStore.GetByID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return models.User{}, &requestError{http.StatusNotFound, "Użytkownik nie znaleziony"}
+	}
+	return user, err
+}
+
+// GetMe godoc
+// @Summary Pobierz profil zalogowanego użytkownika
+// @Security BearerAuth
+// @Description Zwraca konto użytkownika wskazanego w tokenie JWT
+// @Tags Profile
+// @Produce json
+// @Param If-None-Match header string false "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304"
+// @Success 200 {object} models.User
+// @Header 200 {string} ETag "Wersja zasobu"
+// @Success 304 "Zasób nie zmienił się"
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /me [get]
+func GetMe(c *gin.Context) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	user, err := currentUser(ctx, c)
+	if err != nil {
+		respondError(c, err, "Błąd pobierania użytkownika")
+		return
+	}
+
+	respondVersioned(c, user.Version, user)
+}
+
+// UpdateMe godoc
+// @Summary Aktualizuj profil zalogowanego użytkownika
+// @Security BearerAuth
+// @Description Częściowo aktualizuje imię, nazwisko, telefon i dane wysyłki zalogowanego użytkownika (merge patch lub JSON Patch, jak PATCH /users/{id}). Zmiana innych pól, w tym role i is_active, kończy się błędem 400; hasło zmienia się przez POST /me/password.
+// @Tags Profile
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
+// @Param user body models.User true "Zmieniane pola profilu (first_name, last_name, phone_number, shipping_details)"
+// @Success 200 {object} models.User
+// @Header 200 {string} ETag "Nowa wersja zasobu"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /me [patch]
+func UpdateMe(c *gin.Context) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	current, err := currentUser(ctx, c)
+	if err == nil {
+		err = checkIfMatch(c, current.Version)
+	}
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji profilu")
+		return
+	}
+
+	var user models.User
+	changed, err := applyPatch(c, current, &user, profilePatchFields)
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji profilu")
+		return
+	}
+	if len(changed) == 0 {
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusOK, current)
+		return
+	}
+
+	user.UpdatedAt = time.Now()
+	update, err := patchUpdate(user, changed, profilePatchFields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji profilu"})
+		return
+	}
+	update["updated_at"] = user.UpdatedAt
+
+	err = userStore.Update(ctx, current.ID, current.Version, update)
+	if errors.Is(err, store.ErrConflict) {
+		respondError(c, versionConflict(c), "Błąd aktualizacji profilu")
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Użytkownik nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji profilu"})
+		return
+	}
+
+	user.Version = current.Version + 1
+	c.Header("ETag", etag(user.Version))
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangeMyPassword godoc
+// @Summary Zmień hasło zalogowanego użytkownika
+// @Security BearerAuth
+// @Description Ustawia nowe hasło (co najmniej 8 znaków) po sprawdzeniu obecnego. Zmiana unieważnia wszystkie tokeny dostępu i tokeny odświeżania użytkownika, a odpowiedź zawiera tokeny nowej sesji.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Param password body ChangePasswordRequest true "Obecne i nowe hasło"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse "Nieprawidłowe obecne hasło"
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /me/password [post]
+func ChangeMyPassword(c *gin.Context) {
+	var request ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
+		return
+	}
+	if len(request.NewPassword) < minPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hasło musi mieć co najmniej 8 znaków"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	user, err := currentUser(ctx, c)
+	if err != nil {
+		respondError(c, err, "Błąd zmiany hasła")
+		return
+	}
+	if !middleware.CheckPasswordHash(request.CurrentPassword, user.PasswordHash) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Nieprawidłowe obecne hasło"})
+		return
+	}
+
+	hashedPassword, err := middleware.HashPassword(request.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd haszowania hasła"})
+		return
+	}
+	// Jak reset hasła, zmiana kończy wszystkie sesje; wywołujący otrzymuje nową
+	now := time.Now()
+	err = userStore.Update(ctx, user.ID, user.Version, bson.M{
+		"password_hash":     hashedPassword,
+		"tokens_revoked_at": now,
+		"updated_at":        now,
+	})
+	if err == nil {
+		err = refreshTokenStore.RevokeUser(ctx, user.ID)
+	}
+	if errors.Is(err, store.ErrConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Konto zostało w międzyczasie zmienione, spróbuj ponownie"})
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Użytkownik nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd zmiany hasła"})
+		return
+	}
+
+	user.TokensRevokedAt = &now
+	session, err := issueSession(ctx, user, primitive.NewObjectID())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Hasło zostało zmienione, ale nie udało się wydać nowego tokena – zaloguj się ponownie"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"music-store-api/config"
+	"music-store-api/mailer"
+	"music-store-api/middleware"
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Minimalna długość hasła ustawianego przez użytkownika
+const minPasswordLength = 8
+
+var mailSender mailer.Mailer
+
+// InitMailer ustawia sposób wysyłki wiadomości e-mail do użytkowników
+func InitMailer(m mailer.Mailer) {
+	mailSender = m
+}
+
+// ActiveMailer zwraca mailer ustawiony przez InitMailer
+func ActiveMailer() mailer.Mailer {
+	return mailSender
+}
+
+// RegisterRequest reprezentuje dane rejestracji klienta. Rola konta jest
+// zawsze "customer".
+type RegisterRequest struct {
+	FirstName   string `json:"first_name" example:"Jan"`
+	LastName    string `json:"last_name" example:"Nowak"`
+	Email       string `json:"email" example:"jan.nowak@example.com"`
+	Password    string `json:"password" example:"strongpassword"`
+	PhoneNumber string `json:"phone_number,omitempty" example:"+48123456789"`
+}
+
+// RegisterResponse reprezentuje odpowiedź po rejestracji
+type RegisterResponse struct {
+	ID      string `json:"_id" example:"65f1c0ffee0000000000abcd"`
+	Message string `json:"message" example:"Konto utworzone. Link aktywacyjny wysłano na podany adres e-mail"`
+}
+
+// validate normalizuje adres e-mail i sprawdza wymagane pola rejestracji
+func (r *RegisterRequest) validate() error {
+	r.FirstName = strings.TrimSpace(r.FirstName)
+	r.LastName = strings.TrimSpace(r.LastName)
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+	switch {
+	case r.FirstName == "" || r.LastName == "":
+		return &requestError{http.StatusBadRequest, "Imię i nazwisko są wymagane"}
+	case !isEmailAddress(r.Email):
+		return &requestError{http.StatusBadRequest, "Niepoprawny adres e-mail"}
+	case len(r.Password) < minPasswordLength:
+		return &requestError{http.StatusBadRequest, "Hasło musi mieć co najmniej 8 znaków"}
+	}
+	return nil
+}
+
+// isEmailAddress sprawdza, czy wartość jest samym adresem e-mail (bez nazwy wyświetlanej)
+func isEmailAddress(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
+
+// Register godoc
+// @Summary Rejestracja klienta
+// @Description Tworzy nieaktywne konto z rolą customer i wysyła na podany adres link aktywacyjny (GET /verify). Rola i aktywność konta nie mogą być wybrane przez użytkownika. Logowanie jest możliwe po potwierdzeniu adresu.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param user body RegisterRequest true "Dane nowego konta"
+// @Success 201 {object} RegisterResponse
+// @Failure 400 {object} models.ErrorResponse "Niepoprawne dane"
+// @Failure 409 {object} models.ErrorResponse "Adres e-mail jest już zajęty"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /register [post]
+func Register(c *gin.Context) {
+	var request RegisterRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
+		return
+	}
+	if err := request.validate(); err != nil {
+		respondError(c, err, "Błąd rejestracji")
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	hashedPassword, err := middleware.HashPassword(request.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd haszowania hasła"})
+		return
+	}
+	now := time.Now()
+	user := models.User{
+		ID:           primitive.NewObjectID(),
+		FirstName:    request.FirstName,
+		LastName:     request.LastName,
+		Email:        request.Email,
+		PhoneNumber:  request.PhoneNumber,
+		PasswordHash: hashedPassword,
+		Role:         models.RoleCustomer,
+		IsActive:     false,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Version:      1,
+	}
+	// Repozytorium odrzuca adres zajęty przez inne konto (także w koszu) w tej
+	// samej operacji co zapis, więc równoległe rejestracje nie utworzą dwóch kont
+	err = userStore.Create(ctx, user)
+	if errors.Is(err, store.ErrDuplicate) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Konto z tym adresem e-mail już istnieje"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd tworzenia użytkownika"})
+		return
+	}
+
+	err = sendVerification(user)
+	if err != nil {
+		log.Printf("Błąd wysyłki linku aktywacyjnego do %s: %v", user.Email, err)
+		// Konto bez linku aktywacyjnego nie może zostać aktywowane, więc rejestrację można ponowić
+		if err := userStore.Remove(ctx, user.ID); err != nil {
+			log.Printf("Błąd usuwania nieaktywowanego użytkownika %s: %v", user.ID.Hex(), err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd wysyłki linku aktywacyjnego"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, RegisterResponse{
+		ID:      user.ID.Hex(),
+		Message: "Konto utworzone. Link aktywacyjny wysłano na podany adres e-mail",
+	})
+}
+
+// sendVerification wysyła użytkownikowi link aktywacyjny konta
+func sendVerification(user models.User) error {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	token, err := issueUserToken(ctx, user.ID, models.TokenPurposeVerification, config.App.Security.VerificationTokenTTL.Std())
+	if err != nil {
+		return err
+	}
+	link := strings.TrimSuffix(config.App.Mail.BaseURL, "/") + "/verify?token=" + url.QueryEscape(token)
+	return mailSender.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Potwierdź adres e-mail w Music Store",
+		Body: "Dzień dobry " + user.FirstName + ",\n\n" +
+			"dziękujemy za rejestrację w Music Store. Aby aktywować konto, otwórz link:\n\n" +
+			link + "\n\n" +
+			"Link jest ważny przez " + config.App.Security.VerificationTokenTTL.Std().String() + ". " +
+			"Jeśli to nie Ty zakładasz konto, zignoruj tę wiadomość.\n",
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Potwierdzenie adresu e-mail
+// @Description Aktywuje konto za pomocą tokenu z linku wysłanego po rejestracji. Token jest jednorazowy.
+// @Tags Auth
+// @Produce json
+// @Param token query string true "Token z linku aktywacyjnego"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse "Token nieprawidłowy, użyty lub wygasły"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /verify [get]
+func VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brak tokenu weryfikacyjnego"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	verification, err := tokenStore.Consume(ctx, models.TokenPurposeVerification, hashToken(token))
+	if err == nil {
+		err = userStore.Update(ctx, verification.UserID, store.AnyVersion, bson.M{
+			"is_active":  true,
+			"updated_at": time.Now(),
+		})
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Link aktywacyjny jest nieprawidłowy lub wygasł"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktywacji konta"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Adres e-mail potwierdzony, konto jest aktywne"})
+}
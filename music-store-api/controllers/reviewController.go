@@ -1,49 +1,72 @@
 package controllers
 
 import (
-	"context"
-	"music-store-api/config"
+	"errors"
 	"music-store-api/models"
+	"music-store-api/store"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var reviewCollection *mongo.Collection
+var reviewStore store.ReviewStore
 
-func InitReviewCollection() {
-	reviewCollection = config.DB.Collection("reviews")
+func InitReviewStore(s store.ReviewStore) {
+	reviewStore = s
 }
 
 // GetReviews godoc
 // @Summary Pobierz wszystkie recenzje
 // @Tags Reviews
 // @Produce json
-// @Success 200 {object} models.Review
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param min_rating query int false "Minimalna ocena (włącznie, 1-5)"
+// @Param max_rating query int false "Maksymalna ocena (włącznie, 1-5)"
+// @Param sort query string false "Sortowanie po polach (np. -rating); dozwolone pola: rating, created_at"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista recenzji), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /reviews [get]
 func GetReviews(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	listReviews(c, store.ReviewFilter{})
+}
 
-	cursor, err := reviewCollection.Find(ctx, bson.M{})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania recenzji"})
+// Pola, po których można sortować recenzje (nazwa w API → pole w dokumencie)
+var reviewSortFields = map[string]string{
+	"rating":     "rating",
+	"created_at": "created_at",
+}
+
+// listReviews odsyła stronę recenzji spełniających filtry z parametrów
+// zapytania, zawężonych do albumu i użytkownika z scope
+func listReviews(c *gin.Context, scope store.ReviewFilter) {
+	errs := &validationError{}
+	filter := reviewFilterFromQuery(c, errs)
+	filter.AlbumID = scope.AlbumID
+	filter.UserID = scope.UserID
+	lp := parseListQuery(c, reviewSortFields, errs)
+	if err := errs.errOrNil(); err != nil {
+		respondError(c, err, "Błąd pobierania recenzji")
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var reviews []models.Review
-	if err = cursor.All(ctx, &reviews); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dekodowania danych"})
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	response, err := fetchPage(lp,
+		func(opts store.ListOptions) ([]models.Review, error) { return reviewStore.List(ctx, filter, opts) },
+		func() (int64, error) { return reviewStore.Count(ctx, filter) })
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania recenzji"})
 		return
 	}
 
-	c.JSON(http.StatusOK, reviews)
+	c.JSON(http.StatusOK, response)
 }
 
 // GetReviewByID godoc
@@ -51,7 +74,10 @@ func GetReviews(c *gin.Context) {
 // @Tags Reviews
 // @Produce json
 // @Param id path string true "ID recenzji"
+// @Param If-None-Match header string false "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304"
 // @Success 200 {object} models.Review
+// @Header 200 {string} ETag "Wersja zasobu"
+// @Success 304 "Zasób nie zmienił się"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /reviews/{id} [get]
@@ -63,17 +89,20 @@ func GetReviewByID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	var review models.Review
-	err = reviewCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&review)
-	if err != nil {
+	review, err := reviewStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Recenzja nie znaleziona"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania recenzji"})
+		return
+	}
 
-	c.JSON(http.StatusOK, review)
+	respondVersioned(c, review.Version, review)
 }
 
 // GetReviewsByAlbumID godoc
@@ -81,8 +110,14 @@ func GetReviewByID(c *gin.Context) {
 // @Tags Reviews
 // @Produce json
 // @Param albumID path string true "ID albumu"
-// @Success 200 {array} models.Review
-// @Failure 400 {object} models.ErrorResponse
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param min_rating query int false "Minimalna ocena (włącznie, 1-5)"
+// @Param max_rating query int false "Maksymalna ocena (włącznie, 1-5)"
+// @Param sort query string false "Sortowanie po polach (np. -rating); dozwolone pola: rating, created_at"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista recenzji), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /reviews/album/{albumID} [get]
 func GetReviewsByAlbumID(c *gin.Context) {
@@ -93,23 +128,7 @@ func GetReviewsByAlbumID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cursor, err := reviewCollection.Find(ctx, bson.M{"album_id": albumID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania recenzji"})
-		return
-	}
-	defer cursor.Close(ctx)
-
-	var reviews []models.Review
-	if err = cursor.All(ctx, &reviews); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dekodowania danych"})
-		return
-	}
-
-	c.JSON(http.StatusOK, reviews)
+	listReviews(c, store.ReviewFilter{AlbumID: albumID})
 }
 
 // GetReviewsByUserID godoc
@@ -117,8 +136,14 @@ func GetReviewsByAlbumID(c *gin.Context) {
 // @Tags Reviews
 // @Produce json
 // @Param userID path string true "ID użytkownika"
-// @Success 200 {array} models.Review
-// @Failure 400 {object} models.ErrorResponse
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param min_rating query int false "Minimalna ocena (włącznie, 1-5)"
+// @Param max_rating query int false "Maksymalna ocena (włącznie, 1-5)"
+// @Param sort query string false "Sortowanie po polach (np. -rating); dozwolone pola: rating, created_at"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista recenzji), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /reviews/user/{userID} [get]
@@ -130,23 +155,7 @@ func GetReviewsByUserID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cursor, err := reviewCollection.Find(ctx, bson.M{"user_id": userID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania recenzji"})
-		return
-	}
-	defer cursor.Close(ctx)
-
-	var reviews []models.Review
-	if err = cursor.All(ctx, &reviews); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dekodowania danych"})
-		return
-	}
-
-	c.JSON(http.StatusOK, reviews)
+	listReviews(c, store.ReviewFilter{UserID: userID})
 }
 
 // CreateReview godoc
@@ -179,12 +188,12 @@ func CreateReview(c *gin.Context) {
 
 	review.ID = primitive.NewObjectID()
 	review.CreatedAt = time.Now()
+	review.Version = 1
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	_, err := reviewCollection.InsertOne(ctx, review)
-	if err != nil {
+	if err := reviewStore.Create(ctx, review); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dodawania recenzji"})
 		return
 	}
@@ -199,10 +208,12 @@ func CreateReview(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID recenzji"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
 // @Param review body models.Review true "Dane recenzji do aktualizacji"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /reviews/{id} [put]
 func UpdateReview(c *gin.Context) {
@@ -229,27 +240,42 @@ func UpdateReview(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
+	version, err := ifMatchVersion(c, func() (int64, error) {
+		current, err := reviewStore.GetByID(ctx, objID)
+		return current.Version, err
+	})
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recenzja nie znaleziona"})
+		return
+	}
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji recenzji")
+		return
+	}
+
 	update := bson.M{
-		"$set": bson.M{
-			"album_id": review.AlbumID,
-			"user_id":  review.UserID,
-			"rating":   review.Rating,
-			"comment":  review.Comment,
-		},
+		"album_id": review.AlbumID,
+		"user_id":  review.UserID,
+		"rating":   review.Rating,
+		"comment":  review.Comment,
 	}
 
-	result, err := reviewCollection.UpdateByID(ctx, objID, update)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji recenzji"})
+	err = reviewStore.Update(ctx, objID, version, update)
+	if errors.Is(err, store.ErrConflict) {
+		respondError(c, versionConflict(c), "Błąd aktualizacji recenzji")
 		return
 	}
-	if result.MatchedCount == 0 {
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Recenzja nie znaleziona"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji recenzji"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Recenzja zaktualizowana"})
 }
@@ -257,12 +283,15 @@ func UpdateReview(c *gin.Context) {
 // DeleteReview godoc
 // @Summary Usuń recenzję
 // @Security BearerAuth
+// @Description Przenosi recenzję do kosza (GET /trash/reviews); przywrócenie przez POST /reviews/{id}/restore
 // @Tags Reviews
 // @Produce json
 // @Param id path string true "ID recenzji"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /reviews/{id} [delete]
 func DeleteReview(c *gin.Context) {
@@ -273,19 +302,27 @@ func DeleteReview(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	result, err := reviewCollection.DeleteOne(ctx, bson.M{"_id": objID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd usuwania recenzji"})
-		return
+	version, err := ifMatchVersion(c, func() (int64, error) {
+		review, err := reviewStore.GetByID(ctx, objID)
+		return review.Version, err
+	})
+	if err == nil {
+		err = reviewStore.Delete(ctx, objID, version)
 	}
-
-	if result.DeletedCount == 0 {
+	if errors.Is(err, store.ErrConflict) {
+		err = versionConflict(c)
+	}
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Recenzja nie znaleziona"})
 		return
 	}
+	if err != nil {
+		respondError(c, err, "Błąd usuwania recenzji")
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Recenzja usunięta"})
+	c.JSON(http.StatusOK, gin.H{"message": "Recenzja przeniesiona do kosza"})
 }
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"music-store-api/config"
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var refreshTokenStore store.RefreshTokenStore
+
+var revokedTokenStore store.RevokedTokenStore
+
+// InitRefreshTokenStore ustawia repozytorium tokenów odświeżania
+func InitRefreshTokenStore(s store.RefreshTokenStore) {
+	refreshTokenStore = s
+}
+
+// InitRevokedTokenStore ustawia listę unieważnionych tokenów dostępu
+func InitRevokedTokenStore(s store.RevokedTokenStore) {
+	revokedTokenStore = s
+}
+
+// RefreshRequest reprezentuje wymianę tokenu odświeżania
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" example:"dVfKA23WcIwu7cpS7GOH-zjO914zsCFjABhPLX6yKbI"`
+}
+
+// errRefreshRejected oznacza token odświeżania, którego nie można wymienić
+var errRefreshRejected = &requestError{http.StatusUnauthorized, "Nieprawidłowy lub wygasły token odświeżania"}
+
+// issueSession wydaje użytkownikowi token dostępu i nowy token odświeżania
+// z rodziny familyID
+func issueSession(ctx context.Context, user models.User, familyID primitive.ObjectID) (LoginResponse, error) {
+	refreshToken, err := randomToken()
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	now := time.Now()
+	err = refreshTokenStore.Create(ctx, models.RefreshToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		Hash:      hashToken(refreshToken),
+		CreatedAt: now,
+		ExpiresAt: now.Add(config.App.JWT.RefreshTokenTTL.Std()),
+	})
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	waitPastRevocation(user)
+	token, err := config.GenerateJWT(user.ID.Hex(), user.Role, familyID.Hex())
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	return LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.App.JWT.TokenTTL.Std().Seconds()),
+	}, nil
+}
+
+// waitPastRevocation czeka, aż minie milisekunda ostatniego unieważnienia
+// tokenów użytkownika – token wydany w tej samej milisekundzie AuthMiddleware
+// odrzuca, bo nie da się go odróżnić od tokenu wydanego przed unieważnieniem.
+// Data iat zapisana w tokenie jako ułamek sekund może po odczycie być o
+// milisekundę wcześniejsza, stąd zapas dwóch milisekund.
+func waitPastRevocation(user models.User) {
+	if user.TokensRevokedAt == nil {
+		return
+	}
+	if wait := time.Until(user.TokensRevokedAt.Truncate(time.Millisecond).Add(2 * time.Millisecond)); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// rotateRefreshToken wymienia token odświeżania na nową sesję. Ponowne
+// użycie wymienionego tokenu unieważnia całą jego rodzinę, bo oznacza, że
+// token mógł wyciec.
+func rotateRefreshToken(ctx context.Context, refreshToken string) (LoginResponse, error) {
+	current, err := refreshTokenStore.GetByHash(ctx, hashToken(refreshToken))
+	if errors.Is(err, store.ErrNotFound) {
+		return LoginResponse{}, errRefreshRejected
+	}
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	if current.RevokedAt != nil || !current.ExpiresAt.After(time.Now()) {
+		return LoginResponse{}, errRefreshRejected
+	}
+
+	err = refreshTokenStore.MarkUsed(ctx, current.ID, time.Now())
+	if errors.Is(err, store.ErrConflict) {
+		log.Printf("Ponowne użycie tokenu odświeżania sesji %s użytkownika %s – sesja unieważniona", current.FamilyID.Hex(), current.UserID.Hex())
+		if err := refreshTokenStore.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return LoginResponse{}, err
+		}
+		return LoginResponse{}, errRefreshRejected
+	}
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	// Nowy token dostępu otrzymuje bieżącą rolę; konta usunięte i nieaktywne tracą sesję
+	user, err := userStore.GetByID(ctx, current.UserID)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && !user.IsActive) {
+		if err := refreshTokenStore.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return LoginResponse{}, err
+		}
+		return LoginResponse{}, errRefreshRejected
+	}
+	if err != nil {
+		return LoginResponse{}, err
+	}
+	return issueSession(ctx, user, current.FamilyID)
+}
+
+// RefreshToken godoc
+// @Summary Odświeżenie sesji
+// @Description Wymienia token odświeżania na nowy token dostępu i nowy token odświeżania. Każdy token odświeżania można użyć raz; ponowne użycie unieważnia całą sesję (wszystkie tokeny odświeżania wydane od zalogowania).
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body RefreshRequest true "Token odświeżania"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} models.ErrorResponse "Niepoprawne dane"
+// @Failure 401 {object} models.ErrorResponse "Token odświeżania nieprawidłowy, wygasły, unieważniony lub użyty ponownie"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /token/refresh [post]
+func RefreshToken(c *gin.Context) {
+	var request RefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	session, err := rotateRefreshToken(ctx, request.RefreshToken)
+	if err != nil {
+		respondError(c, err, "Błąd odświeżania sesji")
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// revokeAccessToken dodaje token dostępu bieżącego żądania do listy unieważnionych
+func revokeAccessToken(ctx context.Context, c *gin.Context) (*config.Claims, error) {
+	claims, ok := c.MustGet("tokenClaims").(*config.Claims)
+	if !ok || claims.ID == "" || claims.ExpiresAt == nil {
+		return claims, nil
+	}
+	return claims, revokedTokenStore.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// Logout godoc
+// @Summary Wylogowanie
+// @Security BearerAuth
+// @Description Unieważnia bieżący token dostępu oraz tokeny odświeżania jego sesji
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /logout [post]
+func Logout(c *gin.Context) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	claims, err := revokeAccessToken(ctx, c)
+	if err == nil && claims.SessionID != "" {
+		if familyID, parseErr := primitive.ObjectIDFromHex(claims.SessionID); parseErr == nil {
+			err = refreshTokenStore.RevokeFamily(ctx, familyID)
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd wylogowania"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wylogowano"})
+}
+
+// LogoutAll godoc
+// @Summary Wylogowanie ze wszystkich urządzeń
+// @Security BearerAuth
+// @Description Unieważnia wszystkie tokeny dostępu i tokeny odświeżania zalogowanego użytkownika
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /logout-all [post]
+func LogoutAll(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Nieprawidłowy identyfikator użytkownika w tokenie"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	// Tokeny dostępu wydane przed tą chwilą odrzuca AuthMiddleware
+	err := userStore.Update(ctx, userID, store.AnyVersion, bson.M{"tokens_revoked_at": time.Now()})
+	if err == nil {
+		err = refreshTokenStore.RevokeUser(ctx, userID)
+	}
+	if err == nil {
+		_, err = revokeAccessToken(ctx, c)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Użytkownik nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd wylogowania"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Wylogowano ze wszystkich urządzeń"})
+}
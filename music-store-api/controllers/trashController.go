@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// trashResource udostępnia kosz jednego repozytorium niezależnie od typu dokumentów
+type trashResource struct {
+	list    func(ctx context.Context, opts store.ListOptions) ([]interface{}, error)
+	count   func(ctx context.Context) (int64, error)
+	restore func(ctx context.Context, id primitive.ObjectID) error
+	purge   func(ctx context.Context, before time.Time) ([]primitive.ObjectID, error)
+	// Komunikat 404, gdy przywracanego dokumentu nie ma w koszu
+	notFound string
+	// Komunikat po przywróceniu dokumentu
+	restored string
+}
+
+func newTrashResource[T any](trash store.Trash[T], notFound, restored string) trashResource {
+	return trashResource{
+		list: func(ctx context.Context, opts store.ListOptions) ([]interface{}, error) {
+			docs, err := trash.ListTrash(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			items := make([]interface{}, len(docs))
+			for i, doc := range docs {
+				items[i] = doc
+			}
+			return items, nil
+		},
+		count:    trash.CountTrash,
+		restore:  trash.Restore,
+		purge:    trash.Purge,
+		notFound: notFound,
+		restored: restored,
+	}
+}
+
+// trashResources zwraca zasoby z koszem według nazwy w ścieżce /trash/:resource.
+// Budowana przy każdym wywołaniu, bo repozytoria ustawiane są po inicjalizacji pakietu.
+func trashResources() map[string]trashResource {
+	return map[string]trashResource{
+		"albums":  newTrashResource[models.Album](albumStore, "Album nie znaleziony w koszu", "Album przywrócony"),
+		"users":   newTrashResource[models.User](userStore, "Użytkownik nie znaleziony w koszu", "Użytkownik przywrócony"),
+		"orders":  newTrashResource[models.Order](orderStore, "Zamówienie nie znalezione w koszu", "Zamówienie przywrócone"),
+		"reviews": newTrashResource[models.Review](reviewStore, "Recenzja nie znaleziona w koszu", "Recenzja przywrócona"),
+	}
+}
+
+// Pola, po których można sortować zawartość kosza (nazwa w API → pole w dokumencie)
+var trashSortFields = map[string]string{
+	"deleted_at": "deleted_at",
+}
+
+// GetTrash godoc
+// @Summary Pobierz zawartość kosza
+// @Security BearerAuth
+// @Description Zwraca usunięte dokumenty zasobu, które nie zostały jeszcze trwale usunięte. Dokumenty w koszu są niewidoczne dla pozostałych endpointów do czasu przywrócenia; po upływie trash.retention_days dni od usunięcia są usuwane trwale.
+// @Tags Trash
+// @Produce json
+// @Param resource path string true "Zasób: albums, users, orders lub reviews"
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param sort query string false "Sortowanie (np. -deleted_at); dozwolone pola: deleted_at"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista dokumentów z polem deleted_at), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /trash/{resource} [get]
+func GetTrash(c *gin.Context) {
+	resources := trashResources()
+	resource, ok := resources[c.Param("resource")]
+	if !ok {
+		names := make([]string, 0, len(resources))
+		for name := range resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Nieznany zasób kosza (dozwolone: " + strings.Join(names, ", ") + ")"})
+		return
+	}
+
+	errs := &validationError{}
+	lp := parseListQuery(c, trashSortFields, errs)
+	if err := errs.errOrNil(); err != nil {
+		respondError(c, err, "Błąd pobierania kosza")
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	response, err := fetchPage(lp,
+		func(opts store.ListOptions) ([]interface{}, error) { return resource.list(ctx, opts) },
+		func() (int64, error) { return resource.count(ctx) })
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania kosza"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// restoreFromTrash przywraca z kosza dokument zasobu o ID z parametru ścieżki
+func restoreFromTrash(c *gin.Context, name string) {
+	resource := trashResources()[name]
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne ID"})
+		return
+	}
+
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	err = resource.restore(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": resource.notFound})
+		return
+	}
+	if errors.Is(err, store.ErrDuplicate) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Przywrócenie naruszyłoby unikalność danych (np. adres e-mail jest zajęty przez inne konto)"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd przywracania z kosza"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": resource.restored})
+}
+
+// RestoreAlbum godoc
+// @Summary Przywróć album z kosza
+// @Security BearerAuth
+// @Tags Trash
+// @Produce json
+// @Param id path string true "ID albumu"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /albums/{id}/restore [post]
+func RestoreAlbum(c *gin.Context) {
+	restoreFromTrash(c, "albums")
+}
+
+// RestoreUser godoc
+// @Summary Przywróć użytkownika z kosza
+// @Security BearerAuth
+// @Description Adres e-mail użytkownika w koszu pozostaje zajęty (rejestracja i POST /users zwracają dla niego 409), więc przywrócenie nie tworzy drugiego konta z tym samym adresem. Gdy adres jest mimo to zajęty przez inne konto, odpowiedź to 409.
+// @Tags Trash
+// @Produce json
+// @Param id path string true "ID użytkownika"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Adres e-mail jest zajęty przez inne konto"
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/restore [post]
+func RestoreUser(c *gin.Context) {
+	restoreFromTrash(c, "users")
+}
+
+// RestoreOrder godoc
+// @Summary Przywróć zamówienie z kosza
+// @Security BearerAuth
+// @Tags Trash
+// @Produce json
+// @Param id path string true "ID zamówienia"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /orders/{id}/restore [post]
+func RestoreOrder(c *gin.Context) {
+	restoreFromTrash(c, "orders")
+}
+
+// RestoreReview godoc
+// @Summary Przywróć recenzję z kosza
+// @Security BearerAuth
+// @Tags Trash
+// @Produce json
+// @Param id path string true "ID recenzji"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /reviews/{id}/restore [post]
+func RestoreReview(c *gin.Context) {
+	restoreFromTrash(c, "reviews")
+}
+
+// PurgeTrash trwale usuwa dokumenty przeniesione do kosza przed before
+// wraz z okładkami usuniętych albumów i stosuje reguły spójności dla
+// usuniętych użytkowników. Błąd jednego zasobu nie przerywa czyszczenia
+// pozostałych.
+func PurgeTrash(ctx context.Context, before time.Time) error {
+	var errs []error
+	for name, resource := range trashResources() {
+		purged, err := resource.purge(ctx, before)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(purged) > 0 {
+			log.Printf("Trwale usunięto z kosza %s: %d", name, len(purged))
+		}
+		for _, id := range purged {
+			if err := afterPurge(ctx, name, id); err != nil {
+				log.Printf("Błąd usuwania danych powiązanych z %s %s: %v", name, id.Hex(), err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// afterPurge usuwa dane zależne od trwale usuniętego dokumentu: okładkę
+// albumu albo dane osobowe użytkownika w zamówieniach, recenzjach i koszyku
+func afterPurge(ctx context.Context, resource string, id primitive.ObjectID) error {
+	switch resource {
+	case "albums":
+		return coverStore.Delete(ctx, id)
+	case "users":
+		return applyIntegrityRules(ctx, userIntegrityRules, id)
+	}
+	return nil
+}
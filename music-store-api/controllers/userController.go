@@ -1,25 +1,23 @@
 package controllers
 
 import (
-	"context"
-	"music-store-api/config"
+	"errors"
 	"music-store-api/middleware"
 	"music-store-api/models"
+	"music-store-api/store"
 
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-var userCollection *mongo.Collection
+var userStore store.UserStore
 
-// InitUserCollection inicjalizuje kolekcję użytkowników
-func InitUserCollection() {
-	userCollection = config.DB.Collection("users")
+// InitUserStore ustawia repozytorium użytkowników
+func InitUserStore(s store.UserStore) {
+	userStore = s
 }
 
 // GetUsers godoc
@@ -29,26 +27,47 @@ func InitUserCollection() {
 // @Tags Users
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.User
+// @Param page query int false "Numer strony (domyślnie 1), pomijany przy kursorze"
+// @Param limit query int false "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)"
+// @Param cursor query string false "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi"
+// @Param role query string false "Filtruj po roli (kilka wartości po przecinku)"
+// @Param is_active query bool false "Filtruj po aktywności konta"
+// @Param sort query string false "Sortowanie po polach (np. last_name,first_name); dozwolone pola: first_name, last_name, email, role, created_at, updated_at"
+// @Success 200 {object} map[string]interface{} "Struktura danych zawiera: page (bez kursora), limit, total, data (lista użytkowników), next_cursor i prev_cursor"
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 500 {object} models.ErrorResponse
 // @Router /users [get]
 func GetUsers(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	errs := &validationError{}
+	filter := userFilterFromQuery(c, errs)
+	lp := parseListQuery(c, userSortFields, errs)
+	if err := errs.errOrNil(); err != nil {
+		respondError(c, err, "Błąd pobierania użytkowników")
+		return
+	}
+
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	cursor, err := userCollection.Find(ctx, bson.M{})
+	response, err := fetchPage(lp,
+		func(opts store.ListOptions) ([]models.User, error) { return userStore.List(ctx, filter, opts) },
+		func() (int64, error) { return userStore.Count(ctx, filter) })
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania użytkowników"})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var users []models.User
-	if err = cursor.All(ctx, &users); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd dekodowania użytkowników"})
-		return
-	}
+	c.JSON(http.StatusOK, response)
+}
 
-	c.JSON(http.StatusOK, users)
+// Pola, po których można sortować użytkowników (nazwa w API → pole w dokumencie)
+var userSortFields = map[string]string{
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"email":      "email",
+	"role":       "role",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
 }
 
 // GetUserByID godoc
@@ -59,7 +78,10 @@ func GetUsers(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID użytkownika"
+// @Param If-None-Match header string false "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304"
 // @Success 200 {object} models.User
+// @Header 200 {string} ETag "Wersja zasobu"
+// @Success 304 "Zasób nie zmienił się"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Router /users/{id} [get]
@@ -71,29 +93,33 @@ func GetUserByID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	var user models.User
-	err = userCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&user)
-	if err != nil {
+	user, err := userStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Użytkownik nie znaleziony"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania użytkownika"})
+		return
+	}
 
-	c.JSON(http.StatusOK, user)
+	respondVersioned(c, user.Version, user)
 }
 
 // CreateUser godoc
 // @Summary Dodaj nowego użytkownika
 // @Security BearerAuth
-// @Description Tworzy nowego użytkownika w bazie
+// @Description Tworzy nowego użytkownika w bazie. Adres e-mail nie może być zajęty przez inne konto (bez względu na wielkość liter), także przez konto w koszu.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param user body models.User true "Użytkownik do dodania"
 // @Success 201 {object} models.User
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse "Adres e-mail jest już zajęty"
 // @Failure 500 {object} models.ErrorResponse
 // @Router /users [post]
 func CreateUser(c *gin.Context) {
@@ -114,11 +140,16 @@ func CreateUser(c *gin.Context) {
 	user.ID = primitive.NewObjectID()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	user.Version = 1
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := dbContext()
 	defer cancel()
 
-	_, err = userCollection.InsertOne(ctx, user)
+	err = userStore.Create(ctx, user)
+	if errors.Is(err, store.ErrDuplicate) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Konto z tym adresem e-mail już istnieje"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd tworzenia użytkownika"})
 		return
@@ -130,15 +161,22 @@ func CreateUser(c *gin.Context) {
 // UpdateUser godoc
 // @Summary Aktualizuj użytkownika
 // @Security BearerAuth
-// @Description Aktualizuje dane użytkownika na podstawie ID
+// @Description Częściowo aktualizuje użytkownika. Treść application/merge-patch+json (lub application/json) zmienia tylko podane pola, a null usuwa pola opcjonalne (phone_number, shipping_details). Treść application/json-patch+json to lista operacji JSON Patch (RFC 6902). Nowe hasło zapisywane jest wyłącznie jako hash. Zmiana hasła, roli lub aktywności konta unieważnia wszystkie tokeny dostępu i tokeny odświeżania użytkownika.
 // @Tags Users
 // @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
 // @Produce json
 // @Param id path string true "ID użytkownika"
-// @Param user body models.User true "Zaktualizowane dane użytkownika"
-// @Success 200 {object} models.SuccessResponse
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
+// @Param user body models.User true "Zmieniane pola użytkownika (merge patch) lub lista operacji JSON Patch"
+// @Success 200 {object} models.User
+// @Header 200 {string} ETag "Nowa wersja zasobu"
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /users/{id} [patch]
 func UpdateUser(c *gin.Context) {
@@ -149,59 +187,123 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Niepoprawne dane wejściowe"})
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	current, err := userStore.GetByID(ctx, objID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Użytkownik nie znaleziony"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd pobierania użytkownika"})
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	if err := checkIfMatch(c, current.Version); err != nil {
+		respondError(c, err, "Błąd aktualizacji użytkownika")
+		return
+	}
 
-	update := bson.M{
-		"$set": bson.M{
-			"first_name": user.FirstName,
-			"last_name":  user.LastName,
-			"email":      user.Email,
-			"role":       user.Role,
-			"password":   user.Password,
-			"updated_at": time.Now(),
-		},
+	var user models.User
+	changed, err := applyPatch(c, current, &user, userPatchFields)
+	if err != nil {
+		respondError(c, err, "Błąd aktualizacji użytkownika")
+		return
+	}
+	if !models.IsValidRole(user.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nieznana rola użytkownika"})
+		return
+	}
+	if len(changed) == 0 {
+		c.Header("ETag", etag(current.Version))
+		c.JSON(http.StatusOK, current)
+		return
 	}
 
-	if user.Password != "" {
+	user.PasswordHash = current.PasswordHash
+	if containsField(changed, "password") {
+		if user.Password == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Hasło nie może być puste"})
+			return
+		}
 		hashedPassword, err := middleware.HashPassword(user.Password)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd haszowania hasła"})
 			return
 		}
-		update["$set"].(bson.M)["password_hash"] = hashedPassword
+		user.PasswordHash = hashedPassword
+		user.Password = ""
 	}
 
-	result, err := userCollection.UpdateByID(ctx, objID, update)
+	user.UpdatedAt = time.Now()
+	update, err := patchUpdate(user, changed, userPatchFields)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji użytkownika"})
 		return
 	}
-	if result.MatchedCount == 0 {
+	update["updated_at"] = user.UpdatedAt
+
+	// Zmiana hasła, roli lub aktywności konta kończy wszystkie sesje użytkownika
+	revokeSessions := containsField(changed, "password") || user.Role != current.Role || user.IsActive != current.IsActive
+	if revokeSessions {
+		update["tokens_revoked_at"] = user.UpdatedAt
+	}
+
+	err = userStore.Update(ctx, objID, current.Version, update)
+	if err == nil && revokeSessions {
+		err = refreshTokenStore.RevokeUser(ctx, objID)
+	}
+	if errors.Is(err, store.ErrConflict) {
+		respondError(c, versionConflict(c), "Błąd aktualizacji użytkownika")
+		return
+	}
+	if errors.Is(err, store.ErrDuplicate) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Konto z tym adresem e-mail już istnieje"})
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Użytkownik nie znaleziony"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd aktualizacji użytkownika"})
+		return
+	}
+
+	user.Version = current.Version + 1
+	c.Header("ETag", etag(user.Version))
+	c.JSON(http.StatusOK, user)
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Użytkownik zaktualizowany"})
+// Pola użytkownika, które można zmienić przez PATCH (klucz JSON → pole w dokumencie).
+// Hasło zapisywane jest jako password_hash.
+var userPatchFields = map[string]patchField{
+	"first_name":       {bson: "first_name"},
+	"last_name":        {bson: "last_name"},
+	"email":            {bson: "email"},
+	"phone_number":     {bson: "phone_number", optional: true},
+	"password":         {bson: "password_hash"},
+	"role":             {bson: "role"},
+	"is_active":        {bson: "is_active"},
+	"shipping_details": {bson: "shipping_details", optional: true},
 }
 
 // DeleteUser godoc
 // @Summary Usuń użytkownika
 // @Security BearerAuth
-// @Description Usuwa użytkownika na podstawie ID
+// @Description Przenosi użytkownika do kosza (GET /trash/users); przywrócenie przez POST /users/{id}/restore. Użytkownik z otwartymi zamówieniami nie jest usuwany (409 z listą zamówień) bez force=true. Dopiero przy trwałym usunięciu z kosza wszystkie zamówienia użytkownika (także w koszu) pozostają w historii bez danych wysyłki (poza krajem), recenzje tracą powiązanie z kontem, a koszyk jest usuwany.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param id path string true "ID użytkownika"
+// @Param force query bool false "Usuń mimo otwartych zamówień (tylko admin)"
+// @Param If-Match header string false "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ReferenceConflictResponse
+// @Failure 412 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /users/{id} [delete]
 func DeleteUser(c *gin.Context) {
@@ -212,19 +314,36 @@ func DeleteUser(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	result, err := userCollection.DeleteOne(ctx, bson.M{"_id": objID})
+	force, err := queryForce(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Błąd usuwania użytkownika"})
+		respondError(c, err, "Błąd usuwania użytkownika")
 		return
 	}
 
-	if result.DeletedCount == 0 {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	version, err := ifMatchVersion(c, func() (int64, error) {
+		user, err := userStore.GetByID(ctx, objID)
+		return user.Version, err
+	})
+	if err == nil {
+		err = checkReferences(ctx, userIntegrityRules, objID, force)
+	}
+	if err == nil {
+		err = userStore.Delete(ctx, objID, version)
+	}
+	if errors.Is(err, store.ErrConflict) {
+		err = versionConflict(c)
+	}
+	if errors.Is(err, store.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Użytkownik nie znaleziony"})
 		return
 	}
+	if err != nil {
+		respondError(c, err, "Błąd usuwania użytkownika")
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Użytkownik usunięty"})
+	c.JSON(http.StatusOK, gin.H{"message": "Użytkownik przeniesiony do kosza"})
 }
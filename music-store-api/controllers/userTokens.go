@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var tokenStore store.TokenStore
+
+// InitTokenStore ustawia repozytorium jednorazowych tokenów użytkowników
+func InitTokenStore(s store.TokenStore) {
+	tokenStore = s
+}
+
+// randomToken zwraca losowy token (256 bitów) do wysłania użytkownikowi
+func randomToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashToken zwraca hash SHA-256 tokenu, pod którym token jest przechowywany
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueUserToken tworzy token użytkownika ważny przez ttl i zwraca go do
+// wysłania. Wcześniejsze tokeny użytkownika o tym samym przeznaczeniu
+// przestają być ważne.
+func issueUserToken(ctx context.Context, userID primitive.ObjectID, purpose string, ttl time.Duration) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := tokenStore.DeleteByUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = tokenStore.Create(ctx, models.UserToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Purpose:   purpose,
+		Hash:      hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
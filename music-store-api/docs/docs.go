@@ -24,6 +24,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Zwraca klucze publiczne (JWKS, RFC 7517), którymi inne usługi mogą weryfikować tokeny dostępu wydane przez API. Klucz wybierany jest po nagłówku kid tokenu. Podczas rotacji zbiór zawiera również poprzedni i następny klucz podpisu. Przy podpisie HS256 zbiór jest pusty.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Klucze publiczne tokenów",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/config.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
         "/albums": {
             "get": {
                 "description": "Zwraca wszystkie albumy w sklepie z opcjonalnym filtrowaniem, sortowaniem i paginacją",
@@ -40,43 +60,125 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Numer strony (domyślnie 1)",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
                         "name": "page",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Liczba wyników na stronę (domyślnie 10)",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filtruj po wykonawcy (częściowa zgodność, bez wielkości liter)",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtruj po wykonawcy (częściowa zgodność, bez wielkości liter; kilka wartości po przecinku)",
                         "name": "artist",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filtruj po gatunku muzycznym (częściowa zgodność, bez wielkości liter)",
+                        "description": "Filtruj po gatunku muzycznym (częściowa zgodność, bez wielkości liter; kilka wartości po przecinku)",
                         "name": "genre",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sortowanie po polach (np. price,-title)",
+                        "description": "Filtruj po dekadzie wydania, np. 1980 (kilka wartości po przecinku)",
+                        "name": "decade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtruj po przedziale cenowym, np. 20-30 lub 50+ (kilka wartości po przecinku)",
+                        "name": "price_range",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID wykonawców (kilka wartości po przecinku)",
+                        "name": "artist_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "partial",
+                            "exact"
+                        ],
+                        "type": "string",
+                        "description": "Sposób dopasowania artist i genre: partial (domyślnie, zawiera) lub exact (równa się)",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimalna cena (włącznie)",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maksymalna cena (włącznie)",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Najwcześniejsza data wydania (włącznie), RRRR-MM-DD lub RFC 3339",
+                        "name": "released_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Najpóźniejsza data wydania (włącznie), RRRR-MM-DD lub RFC 3339",
+                        "name": "released_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtruj po formacie wersji: vinyl, cd, cassette, digital (kilka wartości po przecinku); z in_stock – dostępne w danym formacie",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Tylko albumy dostępne w magazynie",
+                        "name": "in_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Dołącz liczności albumów dla gatunków, wykonawców, dekad i przedziałów cenowych",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. price,-title); dozwolone pola: title, artist, genre, price, quantity, release_date, created_at, updated_at",
                         "name": "sort",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Struktura danych zawiera: page, limit, total i data (lista albumów)",
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista albumów), next_cursor i prev_cursor (null, gdy strona nie istnieje) oraz opcjonalnie facets (models.AlbumFacets)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -91,7 +193,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Dodaje album do bazy danych",
+                "description": "Dodaje album do bazy danych. Album przypisywany jest do wykonawcy artist_id, a bez niego – do wykonawcy o nazwie lub aliasie z pola artist (brakujący wykonawca jest tworzony). Album bez listy variants otrzymuje jedną wersję CD z ceną price i liczbą sztuk quantity; cena i liczba sztuk albumu z wersjami wyliczane są z wersji.",
                 "consumes": [
                     "application/json"
                 ],
@@ -126,6 +228,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Kod SKU używany przez inny album",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -181,6 +289,66 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Kod SKU używany przez inny album",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/albums/search": {
+            "get": {
+                "description": "Wyszukiwanie pełnotekstowe w tytule, wykonawcy, opisie i utworach albumów. Wielkość liter i znaki diakrytyczne są pomijane (np. \"Łódź\" znajduje \"Lodz\"). Wyniki sortowane są według trafności.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Albums"
+                ],
+                "summary": "Wyszukaj albumy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Szukana fraza",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: query, page, limit, total i data (lista albumów z polem score)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -210,6 +378,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -217,8 +391,17 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.Album"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Wersja zasobu"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Zasób nie zmienił się"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -239,7 +422,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Usuwa album na podstawie ID",
+                "description": "Przenosi album do kosza (GET /trash/albums). Album znika z katalogu i można go przywrócić przez POST /albums/{id}/restore; po upływie trash.retention_days dni jest usuwany trwale wraz z okładką. Album występujący w otwartych zamówieniach nie jest usuwany (409 z listą zamówień), chyba że administrator przekaże force=true. Recenzje albumu trafiają razem z nim do kosza.",
                 "consumes": [
                     "application/json"
                 ],
@@ -257,6 +440,18 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Usuń mimo otwartych zamówień (tylko admin)",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -272,12 +467,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferenceConflictResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -292,9 +505,11 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Aktualizuje dane albumu na podstawie ID",
+                "description": "Częściowo aktualizuje album. Treść application/merge-patch+json (lub application/json) zmienia tylko podane pola, a null usuwa pola opcjonalne (description, tracks, cover_url). Pole variants zastępuje całą listę wersji – wersje bez id zachowują ID istniejącej wersji o tym samym SKU. Pola price i quantity można zmieniać tylko w albumie z jedną wersją. Treść application/json-patch+json to lista operacji JSON Patch (RFC 6902).",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
                 ],
                 "produces": [
                     "application/json"
@@ -312,7 +527,13 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Zaktualizowane dane albumu",
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Zmieniane pola albumu (merge patch) lub lista operacji JSON Patch",
                         "name": "album",
                         "in": "body",
                         "required": true,
@@ -325,7 +546,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.Album"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nowa wersja zasobu"
+                            }
                         }
                     },
                     "400": {
@@ -340,124 +567,81 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/data/load": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
                     },
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Wczytuje dane z plików JSON i wstawia je do kolekcji MongoDB.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Data"
-                ],
-                "summary": "Ładowanie danych testowych",
-                "responses": {
-                    "200": {
-                        "description": "Dane zostały wczytane",
+                    "412": {
+                        "description": "Precondition Failed",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Błąd serwera lub pliku danych",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Zwraca token JWT po poprawnym zalogowaniu",
-                "consumes": [
-                    "application/json"
-                ],
+        "/albums/{id}/cover": {
+            "get": {
+                "description": "Zwraca oryginalną okładkę albumu lub jej miniaturę. Odpowiedź zawiera nagłówki ETag, Last-Modified i Cache-Control; żądania warunkowe zwracają 304.",
                 "produces": [
-                    "application/json"
+                    "image/jpeg",
+                    "image/png",
+                    "image/webp"
                 ],
                 "tags": [
-                    "Auth"
+                    "Albums"
                 ],
-                "summary": "Logowanie użytkownika",
+                "summary": "Pobierz okładkę albumu",
                 "parameters": [
                     {
-                        "description": "Dane logowania",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "ID albumu",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rozmiar: original (domyślnie), 150 lub 600",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.LoginResponse"
+                            "type": "file"
                         }
                     },
+                    "304": {
+                        "description": "Okładka nie zmieniła się"
+                    },
                     "400": {
-                        "description": "Niepoprawne dane",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Błędne dane logowania",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/orders": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Orders"
-                ],
-                "summary": "Pobierz wszystkie zamówienia",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Order"
-                            }
-                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -473,32 +657,39 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
+                "description": "Zapisuje okładkę (JPEG, PNG lub WebP, do 5 MB) w GridFS wraz z miniaturami 150 i 600 px i ustawia cover_url albumu",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Albums"
                 ],
-                "summary": "Utwórz nowe zamówienie",
+                "summary": "Prześlij okładkę albumu",
                 "parameters": [
                     {
-                        "description": "Nowe zamówienie",
-                        "name": "order",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Order"
-                        }
+                        "type": "string",
+                        "description": "ID albumu",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Plik obrazu okładki",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created",
+                        "description": "Adres okładki (cover_url) i dostępne rozmiary",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -507,6 +698,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -516,8 +725,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/user/{userID}": {
-            "get": {
+        "/albums/{id}/restore": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -527,14 +736,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Trash"
                 ],
-                "summary": "Pobierz zamówienia użytkownika",
+                "summary": "Przywróć album z kosza",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID użytkownika",
-                        "name": "userID",
+                        "description": "ID albumu",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -543,10 +752,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Order"
-                            }
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -554,55 +766,86 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/orders/{id}": {
+        "/artists": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Artists"
                 ],
-                "summary": "Pobierz zamówienie po ID",
+                "summary": "Pobierz listę wykonawców",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "ID zamówienia",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fragment nazwy lub aliasu (bez wielkości liter)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Kody kraju (kilka wartości po przecinku)",
+                        "name": "country",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. -formed_year); dozwolone pola: name (według sort_name), formed_year, created_at",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista wykonawców), next_cursor i prev_cursor",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -615,32 +858,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Artists"
                 ],
-                "summary": "Zaktualizuj zamówienie (np. status)",
+                "summary": "Dodaj wykonawcę",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID zamówienia",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Dane zamówienia do aktualizacji",
-                        "name": "order",
+                        "description": "Wykonawca do dodania",
+                        "name": "artist",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "$ref": "#/definitions/models.Artist"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.Artist"
                         }
                     },
                     "400": {
@@ -649,8 +885,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -662,36 +898,48 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+            }
+        },
+        "/artists/{id}": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Artists"
                 ],
-                "summary": "Usuń zamówienie",
+                "summary": "Pobierz wykonawcę po ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID zamówienia",
+                        "description": "ID wykonawcy",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.Artist"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Wersja zasobu"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Zasób nie zmienił się"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -711,41 +959,34 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/orders/{id}/shipping": {
-            "put": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Usuwa wykonawcę, który nie ma przypisanych albumów – również albumów w koszu, które można jeszcze przywrócić",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Artists"
                 ],
-                "summary": "Zaktualizuj dane wysyłki zamówienia",
+                "summary": "Usuń wykonawcę",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID zamówienia",
+                        "description": "ID wykonawcy",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Nowe dane wysyłki",
-                        "name": "shipping",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.ShippingDetails"
-                        }
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -767,6 +1008,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferenceConflictResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -774,43 +1027,47 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/orders/{id}/status": {
+            },
             "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Częściowo aktualizuje wykonawcę (JSON Merge Patch lub JSON Patch, jak PATCH /albums/{id}). Zmiana nazwy jest przepisywana do pola artist albumów wykonawcy.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Artists"
                 ],
-                "summary": "Zaktualizuj status zamówienia",
+                "summary": "Zaktualizuj wykonawcę",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID zamówienia",
+                        "description": "ID wykonawcy",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Nowy status zamówienia",
-                        "name": "status",
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Zmieniane pola wykonawcy (merge patch) lub lista operacji JSON Patch",
+                        "name": "artist",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.Artist"
                         }
                     }
                 ],
@@ -818,7 +1075,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.Artist"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nowa wersja zasobu"
+                            }
                         }
                     },
                     "400": {
@@ -833,6 +1096,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -842,20 +1123,67 @@ const docTemplate = `{
                 }
             }
         },
-        "/reviews": {
+        "/artists/{id}/albums": {
             "get": {
+                "description": "Zwraca albumy przypisane do wykonawcy; przyjmuje te same filtry, sortowanie i stronicowanie co GET /albums",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reviews"
+                    "Artists"
+                ],
+                "summary": "Pobierz albumy wykonawcy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID wykonawcy",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. release_date); dozwolone pola jak w GET /albums",
+                        "name": "sort",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Pobierz wszystkie recenzje",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista albumów), next_cursor i prev_cursor",
                         "schema": {
-                            "$ref": "#/definitions/models.Review"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -865,43 +1193,32 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/cart": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Zwraca koszyk wyceniony według aktualnych cen wersji albumów wraz z ostrzeżeniami o brakach w magazynie.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reviews"
-                ],
-                "summary": "Dodaj nową recenzję",
-                "parameters": [
-                    {
-                        "description": "Nowa recenzja",
-                        "name": "review",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Review"
-                        }
-                    }
+                    "Cart"
                 ],
+                "summary": "Pobierz koszyk zalogowanego użytkownika",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Review"
+                            "$ref": "#/definitions/models.CartView"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -913,44 +1230,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/reviews/album/{albumID}": {
-            "get": {
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reviews"
-                ],
-                "summary": "Pobierz recenzje dla konkretnego albumu",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID albumu",
-                        "name": "albumID",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Cart"
                 ],
+                "summary": "Wyczyść koszyk",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Review"
-                            }
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -958,32 +1266,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/reviews/user/{userID}": {
-            "get": {
+        "/cart/checkout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tworzy zamówienie z pozycji koszyka według aktualnych cen i opróżnia koszyk. Bez danych wysyłki w żądaniu używane są dane zapisane w profilu użytkownika.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reviews"
+                    "Cart"
                 ],
-                "summary": "Pobierz recenzje użytkownika",
+                "summary": "Złóż zamówienie z koszyka",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID użytkownika",
-                        "name": "userID",
-                        "in": "path",
-                        "required": true
+                        "description": "Opcjonalne dane do wysyłki",
+                        "name": "checkout",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckoutRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Review"
-                            }
+                            "$ref": "#/definitions/models.Order"
                         }
                     },
                     "400": {
@@ -992,8 +1307,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Niewystarczająca liczba sztuk",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1007,29 +1328,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/reviews/{id}": {
-            "get": {
+        "/cart/items": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Dodaje podaną liczbę sztuk wersji albumu do koszyka. Pole variant_id można pominąć, gdy album ma jedną wersję. Jeśli wersja jest już w koszyku, liczba sztuk jest zwiększana.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reviews"
+                    "Cart"
                 ],
-                "summary": "Pobierz recenzję po ID",
+                "summary": "Dodaj album do koszyka",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID recenzji",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Album, wersja i liczba sztuk",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CartItemRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Review"
+                            "$ref": "#/definitions/models.CartView"
                         }
                     },
                     "400": {
@@ -1038,14 +1370,22 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/cart/items/{albumID}": {
             "put": {
                 "security": [
                     {
@@ -1059,24 +1399,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Reviews"
+                    "Cart"
                 ],
-                "summary": "Zaktualizuj recenzję",
+                "summary": "Zmień liczbę sztuk albumu w koszyku",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID recenzji",
-                        "name": "id",
+                        "description": "ID albumu",
+                        "name": "albumID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Dane recenzji do aktualizacji",
-                        "name": "review",
+                        "type": "string",
+                        "description": "ID wersji – wymagane, gdy album jest w koszyku w kilku wersjach",
+                        "name": "variant_id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Nowa liczba sztuk",
+                        "name": "item",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Review"
+                            "$ref": "#/definitions/models.CartQuantityRequest"
                         }
                     }
                 ],
@@ -1084,7 +1430,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.CartView"
                         }
                     },
                     "400": {
@@ -1093,6 +1439,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1117,23 +1469,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Reviews"
+                    "Cart"
                 ],
-                "summary": "Usuń recenzję",
+                "summary": "Usuń album z koszyka",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID recenzji",
-                        "name": "id",
+                        "description": "ID albumu",
+                        "name": "albumID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID wersji – wymagane, gdy album jest w koszyku w kilku wersjach",
+                        "name": "variant_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.CartView"
                         }
                     },
                     "400": {
@@ -1142,6 +1500,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1157,43 +1521,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
-            "get": {
+        "/data/load": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "Zwraca wszystkich użytkowników w systemie",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Wczytuje dane z plików JSON i wstawia je do kolekcji MongoDB.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Data"
                 ],
-                "summary": "Pobierz listę użytkowników",
+                "summary": "Ładowanie danych testowych",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Dane zostały wczytane",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Błąd serwera lub pliku danych",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/login": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Tworzy nowego użytkownika w bazie",
+                "description": "Rozpoczyna sesję i zwraca krótkotrwały token dostępu JWT oraz token odświeżania (POST /token/refresh). Konta nieaktywne, w tym z niepotwierdzonym adresem e-mail, nie mogą się zalogować.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1201,35 +1571,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Auth"
                 ],
-                "summary": "Dodaj nowego użytkownika",
+                "summary": "Logowanie użytkownika",
                 "parameters": [
                     {
-                        "description": "Użytkownik do dodania",
-                        "name": "user",
+                        "description": "Dane logowania",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/controllers.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/controllers.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Niepoprawne dane",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Błędne dane logowania",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Konto nieaktywne (niepotwierdzony adres e-mail lub konto zablokowane)",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1237,89 +1613,127 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
+        "/logout": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Zwraca szczegóły użytkownika na podstawie ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Unieważnia bieżący token dostępu oraz tokeny odświeżania jego sesji",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
-                ],
-                "summary": "Pobierz użytkownika po ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID użytkownika",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Auth"
                 ],
+                "summary": "Wylogowanie",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
+            }
+        },
+        "/logout-all": {
+            "post": {
+                "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Usuwa użytkownika na podstawie ID",
-                "consumes": [
+                "description": "Unieważnia wszystkie tokeny dostępu i tokeny odświeżania zalogowanego użytkownika",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Wylogowanie ze wszystkich urządzeń",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Zwraca konto użytkownika wskazanego w tokenie JWT",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Profile"
                 ],
-                "summary": "Usuń użytkownika",
+                "summary": "Pobierz profil zalogowanego użytkownika",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID użytkownika",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.User"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Wersja zasobu"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "304": {
+                        "description": "Zasób nie zmienił się"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -1344,27 +1758,28 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Aktualizuje dane użytkownika na podstawie ID",
+                "description": "Częściowo aktualizuje imię, nazwisko, telefon i dane wysyłki zalogowanego użytkownika (merge patch lub JSON Patch, jak PATCH /users/{id}). Zmiana innych pól, w tym role i is_active, kończy się błędem 400; hasło zmienia się przez POST /me/password.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Profile"
                 ],
-                "summary": "Aktualizuj użytkownika",
+                "summary": "Aktualizuj profil zalogowanego użytkownika",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID użytkownika",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
                     },
                     {
-                        "description": "Zaktualizowane dane użytkownika",
+                        "description": "Zmieniane pola profilu (first_name, last_name, phone_number, shipping_details)",
                         "name": "user",
                         "in": "body",
                         "required": true,
@@ -1377,7 +1792,94 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.User"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nowa wersja zasobu"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ustawia nowe hasło (co najmniej 8 znaków) po sprawdzeniu obecnego. Zmiana unieważnia wszystkie tokeny dostępu i tokeny odświeżania użytkownika, a odpowiedź zawiera tokeny nowej sesji.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Zmień hasło zalogowanego użytkownika",
+                "parameters": [
+                    {
+                        "description": "Obecne i nowe hasło",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginResponse"
                         }
                     },
                     "400": {
@@ -1386,12 +1888,111 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Nieprawidłowe obecne hasło",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Pobierz wszystkie zamówienia",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtruj po statusie (kilka wartości po przecinku)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Najwcześniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Najpóźniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. -created_at); dozwolone pola: created_at, updated_at, total, status",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista zamówień), next_cursor i prev_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1399,85 +2000,2574 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tworzy zamówienie zalogowanego użytkownika. Pozycja wskazuje wersję albumu polem variant_id, które można pominąć, gdy album ma jedną wersję. Ceny pozycji, suma i status (pending) wyliczane są po stronie serwera na podstawie aktualnych danych wersji. Stan magazynowy wersji zmniejszany jest w jednej transakcji z zapisem zamówienia.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Utwórz nowe zamówienie",
+                "parameters": [
+                    {
+                        "description": "Zamawiane albumy i dane wysyłki",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Niewystarczająca liczba sztuk",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Pobierz zamówienia zalogowanego użytkownika",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtruj po statusie (kilka wartości po przecinku)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Najwcześniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Najpóźniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. -created_at); dozwolone pola: created_at, updated_at, total, status",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista zamówień), next_cursor i prev_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/me/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Pobierz zamówienie zalogowanego użytkownika po ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Wersja zasobu"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Zasób nie zmienił się"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/me/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Anuluje własne zamówienie, dopóki ma status pending. Zarezerwowane sztuki wracają do magazynu.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Anuluj zamówienie zalogowanego użytkownika",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Zamówienie nie ma już statusu pending",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/user/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Pobierz zamówienia użytkownika",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID użytkownika",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtruj po statusie (kilka wartości po przecinku)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Najwcześniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Najpóźniejsza data utworzenia (włącznie), RRRR-MM-DD lub RFC 3339",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. -created_at); dozwolone pola: created_at, updated_at, total, status",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista zamówień), next_cursor i prev_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Pobierz zamówienie po ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Wersja zasobu"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Zasób nie zmienił się"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aktualizuje dane wysyłki zamówienia; pozostałe pola treści są pomijane. Status zmienia PATCH /orders/{id}/status, a pozycje, suma, właściciel i data utworzenia wyliczane są przy składaniu zamówienia razem z rezerwacją sztuk w magazynie. Dane wysyłki są wymagane, a ID w treści (jeśli podane) musi być zgodne z ID w ścieżce.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Zaktualizuj zamówienie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Dane zamówienia do aktualizacji",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Przenosi zamówienie do kosza (GET /trash/orders); przywrócenie przez POST /orders/{id}/restore. Usunąć można tylko zakończone zamówienie (completed lub cancelled) – zamówienie w realizacji rezerwuje sztuki w magazynie i należy je najpierw anulować.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Usuń zamówienie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Zamówienie jest w realizacji",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Pobierz historię statusów zamówienia",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StatusHistory"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Przywróć zamówienie z kosza",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/shipping": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Zaktualizuj dane wysyłki zamówienia",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Nowe dane wysyłki",
+                        "name": "shipping",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShippingDetails"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/status": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Zmienia status zgodnie z dozwolonymi przejściami: pending→processing→shipped→completed; anulowanie (cancelled) możliwe tylko przed wysyłką. Każda zmiana zapisywana jest w historii zamówienia. Anulowanie zwraca zarezerwowane sztuki do magazynu w jednej transakcji ze zmianą statusu.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Zaktualizuj status zamówienia",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID zamówienia",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Nowy status zamówienia i opcjonalna notatka",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateOrderStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Niedozwolona zmiana statusu",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Wysyła na adres konta jednorazowy token resetu hasła (ważny domyślnie godzinę). Odpowiedź jest zawsze 202, niezależnie od tego, czy konto istnieje.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Prośba o reset hasła",
+                "parameters": [
+                    {
+                        "description": "Adres e-mail konta",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Niepoprawne dane",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "Ustawia nowe hasło (co najmniej 8 znaków) za pomocą tokenu z wiadomości wysłanej przez POST /password/forgot. Token jest jednorazowy, a wszystkie tokeny JWT i tokeny odświeżania wydane wcześniej użytkownikowi przestają być ważne.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Ustawienie nowego hasła",
+                "parameters": [
+                    {
+                        "description": "Token resetu i nowe hasło",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Niepoprawne dane lub token nieprawidłowy, użyty albo wygasły",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Tworzy nieaktywne konto z rolą customer i wysyła na podany adres link aktywacyjny (GET /verify). Rola i aktywność konta nie mogą być wybrane przez użytkownika. Logowanie jest możliwe po potwierdzeniu adresu.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Rejestracja klienta",
+                "parameters": [
+                    {
+                        "description": "Dane nowego konta",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Niepoprawne dane",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Adres e-mail jest już zajęty",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Pobierz wszystkie recenzje",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimalna ocena (włącznie, 1-5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maksymalna ocena (włącznie, 1-5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. -rating); dozwolone pola: rating, created_at",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista recenzji), next_cursor i prev_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Dodaj nową recenzję",
+                "parameters": [
+                    {
+                        "description": "Nowa recenzja",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/album/{albumID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Pobierz recenzje dla konkretnego albumu",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID albumu",
+                        "name": "albumID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimalna ocena (włącznie, 1-5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maksymalna ocena (włącznie, 1-5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. -rating); dozwolone pola: rating, created_at",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista recenzji), next_cursor i prev_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/user/{userID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Pobierz recenzje użytkownika",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID użytkownika",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimalna ocena (włącznie, 1-5)",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maksymalna ocena (włącznie, 1-5)",
+                        "name": "max_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. -rating); dozwolone pola: rating, created_at",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista recenzji), next_cursor i prev_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Pobierz recenzję po ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID recenzji",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Wersja zasobu"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Zasób nie zmienił się"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Zaktualizuj recenzję",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID recenzji",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Dane recenzji do aktualizacji",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Review"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Przenosi recenzję do kosza (GET /trash/reviews); przywrócenie przez POST /reviews/{id}/restore",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Usuń recenzję",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID recenzji",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reviews/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Przywróć recenzję z kosza",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID recenzji",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Wymienia token odświeżania na nowy token dostępu i nowy token odświeżania. Każdy token odświeżania można użyć raz; ponowne użycie unieważnia całą sesję (wszystkie tokeny odświeżania wydane od zalogowania).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Odświeżenie sesji",
+                "parameters": [
+                    {
+                        "description": "Token odświeżania",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Niepoprawne dane",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Token odświeżania nieprawidłowy, wygasły, unieważniony lub użyty ponownie",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash/{resource}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Zwraca usunięte dokumenty zasobu, które nie zostały jeszcze trwale usunięte. Dokumenty w koszu są niewidoczne dla pozostałych endpointów do czasu przywrócenia; po upływie trash.retention_days dni od usunięcia są usuwane trwale.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Pobierz zawartość kosza",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Zasób: albums, users, orders lub reviews",
+                        "name": "resource",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie (np. -deleted_at); dozwolone pola: deleted_at",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista dokumentów z polem deleted_at), next_cursor i prev_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Zwraca wszystkich użytkowników w systemie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Pobierz listę użytkowników",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Numer strony (domyślnie 1), pomijany przy kursorze",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Liczba wyników na stronę (domyślnie 10, maksymalnie 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token next_cursor lub prev_cursor z poprzedniej odpowiedzi",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtruj po roli (kilka wartości po przecinku)",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filtruj po aktywności konta",
+                        "name": "is_active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sortowanie po polach (np. last_name,first_name); dozwolone pola: first_name, last_name, email, role, created_at, updated_at",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Struktura danych zawiera: page (bez kursora), limit, total, data (lista użytkowników), next_cursor i prev_cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tworzy nowego użytkownika w bazie. Adres e-mail nie może być zajęty przez inne konto (bez względu na wielkość liter), także przez konto w koszu.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Dodaj nowego użytkownika",
+                "parameters": [
+                    {
+                        "description": "Użytkownik do dodania",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Adres e-mail jest już zajęty",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Zwraca szczegóły użytkownika na podstawie ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Pobierz użytkownika po ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID użytkownika",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag posiadanej wersji – gdy jest aktualna, odpowiedź to 304",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Wersja zasobu"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Zasób nie zmienił się"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Przenosi użytkownika do kosza (GET /trash/users); przywrócenie przez POST /users/{id}/restore. Użytkownik z otwartymi zamówieniami nie jest usuwany (409 z listą zamówień) bez force=true. Dopiero przy trwałym usunięciu z kosza wszystkie zamówienia użytkownika (także w koszu) pozostają w historii bez danych wysyłki (poza krajem), recenzje tracą powiązanie z kontem, a koszyk jest usuwany.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Usuń użytkownika",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID użytkownika",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Usuń mimo otwartych zamówień (tylko admin)",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReferenceConflictResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Częściowo aktualizuje użytkownika. Treść application/merge-patch+json (lub application/json) zmienia tylko podane pola, a null usuwa pola opcjonalne (phone_number, shipping_details). Treść application/json-patch+json to lista operacji JSON Patch (RFC 6902). Nowe hasło zapisywane jest wyłącznie jako hash. Zmiana hasła, roli lub aktywności konta unieważnia wszystkie tokeny dostępu i tokeny odświeżania użytkownika.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Aktualizuj użytkownika",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID użytkownika",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag wersji, której dotyczy zmiana – przy niezgodności odpowiedź to 412",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Zmieniane pola użytkownika (merge patch) lub lista operacji JSON Patch",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Nowa wersja zasobu"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adres e-mail użytkownika w koszu pozostaje zajęty (rejestracja i POST /users zwracają dla niego 409), więc przywrócenie nie tworzy drugiego konta z tym samym adresem. Gdy adres jest mimo to zajęty przez inne konto, odpowiedź to 409.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Przywróć użytkownika z kosza",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID użytkownika",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Adres e-mail jest zajęty przez inne konto",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify": {
+            "get": {
+                "description": "Aktywuje konto za pomocą tokenu z linku wysłanego po rejestracji. Token jest jednorazowy.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Potwierdzenie adresu e-mail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token z linku aktywacyjnego",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Token nieprawidłowy, użyty lub wygasły",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "config.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string",
+                    "example": "EdDSA"
+                },
+                "crv": {
+                    "description": "Parametry klucza Ed25519",
+                    "type": "string",
+                    "example": "Ed25519"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string",
+                    "example": "Xw8Dj1m2h8RzJqvH0Q6C3bDhx2Qm5fVj1Zp0aT9LwY4"
+                },
+                "kty": {
+                    "type": "string",
+                    "example": "OKP"
+                },
+                "n": {
+                    "description": "Parametry klucza RSA",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "type": "string",
+                    "example": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+                }
+            }
+        },
+        "config.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/config.JWK"
+                    }
+                }
+            }
+        },
+        "controllers.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "oldpassword"
+                },
+                "new_password": {
+                    "type": "string",
+                    "example": "newstrongpassword"
+                }
+            }
+        },
+        "controllers.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "controllers.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "strongpassword"
+                }
+            }
+        },
+        "controllers.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "description": "Czas ważności tokenu dostępu w sekundach",
+                    "type": "integer",
+                    "example": 900
+                },
+                "refresh_token": {
+                    "description": "Jednorazowy token odświeżania, wymieniany przez POST /token/refresh",
+                    "type": "string",
+                    "example": "dVfKA23WcIwu7cpS7GOH-zjO914zsCFjABhPLX6yKbI"
+                },
+                "token": {
+                    "description": "Krótkotrwały token dostępu (JWT)",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "controllers.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "dVfKA23WcIwu7cpS7GOH-zjO914zsCFjABhPLX6yKbI"
+                }
+            }
+        },
+        "controllers.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "jan.nowak@example.com"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "Jan"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Nowak"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "strongpassword"
+                },
+                "phone_number": {
+                    "type": "string",
+                    "example": "+48123456789"
+                }
+            }
+        },
+        "controllers.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "_id": {
+                    "type": "string",
+                    "example": "65f1c0ffee0000000000abcd"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Konto utworzone. Link aktywacyjny wysłano na podany adres e-mail"
+                }
+            }
+        },
+        "controllers.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string",
+                    "example": "newstrongpassword"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "dVfKA23WcIwu7cpS7GOH-zjO914zsCFjABhPLX6yKbI"
+                }
+            }
+        },
+        "models.Album": {
+            "type": "object",
+            "properties": {
+                "artist": {
+                    "description": "Nazwa wykonawcy (ujednolicana do nazwy wykonawcy artist_id)",
+                    "type": "string"
+                },
+                "artist_id": {
+                    "description": "ID wykonawcy",
+                    "type": "string"
+                },
+                "cover_url": {
+                    "description": "URL do okładki albumu",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Data utworzenia wpisu",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "Data przeniesienia do kosza (tylko dla dokumentów w koszu)",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Opis albumu",
+                    "type": "string"
+                },
+                "genre": {
+                    "description": "Gatunek muzyczny",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID albumu",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Najniższa cena spośród wersji albumu (wyliczana przez repozytorium)",
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "Łączna liczba dostępnych sztuk wszystkich wersji (wyliczana przez repozytorium)",
+                    "type": "integer"
+                },
+                "release_date": {
+                    "description": "Data wydania",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "Tytuł albumu",
+                    "type": "string"
+                },
+                "total_duration": {
+                    "description": "Łączny czas trwania utworów w sekundach (wyliczany przez repozytorium)",
+                    "type": "integer"
+                },
+                "tracks": {
+                    "description": "Lista utworów",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Track"
+                    }
+                },
+                "updated_at": {
+                    "description": "Data ostatniej aktualizacji",
+                    "type": "string"
+                },
+                "variants": {
+                    "description": "Wersje albumu na poszczególnych nośnikach (bez nich tworzona jest jedna wersja CD z price i quantity)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Variant"
+                    }
+                },
+                "version": {
+                    "description": "Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Artist": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Inne nazwy wykonawcy, pod którymi może występować w albumach",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "bio": {
+                    "description": "Biografia wykonawcy",
+                    "type": "string"
+                },
+                "country": {
+                    "description": "Kod kraju pochodzenia (ISO 3166-1 alfa-2), np. GB",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Data utworzenia wpisu",
+                    "type": "string"
+                },
+                "disbanded_year": {
+                    "description": "Rok rozwiązania zespołu lub zakończenia działalności",
+                    "type": "integer"
+                },
+                "formed_year": {
+                    "description": "Rok założenia zespołu lub rozpoczęcia działalności",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "ID wykonawcy",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Nazwa wykonawcy",
+                    "type": "string"
+                },
+                "sort_name": {
+                    "description": "Nazwa używana do sortowania, np. \"Beatles, The\" (domyślnie nazwa bez przedimka)",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Data ostatniej aktualizacji",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)",
+                    "type": "integer"
+                }
             }
-        }
-    },
-    "definitions": {
-        "controllers.LoginRequest": {
+        },
+        "models.BlockingReference": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "user@example.com"
+                "count": {
+                    "description": "Liczba wskazujących dokumentów",
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string",
-                    "example": "strongpassword"
+                "ids": {
+                    "description": "ID wskazujących dokumentów (najwyżej 20 pierwszych)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "description": "Opis relacji",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "Zasób zawierający odwołania, np. orders",
+                    "type": "string"
                 }
             }
         },
-        "controllers.LoginResponse": {
+        "models.CartItemRequest": {
             "type": "object",
             "properties": {
-                "token": {
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                "album_id": {
+                    "description": "ID albumu",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "Liczba dodawanych sztuk",
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "description": "ID wersji (wymagane, gdy album ma kilka wersji)",
+                    "type": "string"
                 }
             }
         },
-        "models.Album": {
+        "models.CartLine": {
             "type": "object",
             "properties": {
-                "artist": {
-                    "description": "Nazwa wykonawcy",
+                "album_id": {
+                    "description": "ID albumu",
                     "type": "string"
                 },
-                "cover_url": {
-                    "description": "URL do okładki albumu",
+                "artist": {
+                    "description": "Wykonawca albumu",
                     "type": "string"
                 },
-                "created_at": {
-                    "description": "Data utworzenia wpisu",
-                    "type": "string"
+                "available": {
+                    "description": "Liczba sztuk wersji dostępnych w magazynie",
+                    "type": "integer"
                 },
-                "description": {
-                    "description": "Opis albumu",
+                "format": {
+                    "description": "Format wersji",
                     "type": "string"
                 },
-                "genre": {
-                    "description": "Gatunek muzyczny",
-                    "type": "string"
+                "in_stock": {
+                    "description": "Czy pozycję można zamówić w całości",
+                    "type": "boolean"
                 },
-                "id": {
-                    "description": "ID albumu",
-                    "type": "string"
+                "line_total": {
+                    "description": "Wartość pozycji (cena × ilość)",
+                    "type": "number"
                 },
                 "price": {
-                    "description": "Cena albumu",
+                    "description": "Aktualna cena jednostkowa",
                     "type": "number"
                 },
                 "quantity": {
-                    "description": "Ilość dostępnych sztuk",
+                    "description": "Liczba sztuk w koszyku",
                     "type": "integer"
                 },
-                "release_date": {
-                    "description": "Data wydania",
+                "sku": {
+                    "description": "Kod SKU wersji",
                     "type": "string"
                 },
                 "title": {
                     "description": "Tytuł albumu",
                     "type": "string"
                 },
-                "tracks": {
-                    "description": "Lista utworów",
+                "variant_id": {
+                    "description": "ID wersji albumu",
+                    "type": "string"
+                },
+                "warning": {
+                    "description": "Ostrzeżenie dotyczące pozycji",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CartQuantityRequest": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "description": "Nowa liczba sztuk",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CartView": {
+            "type": "object",
+            "properties": {
+                "can_checkout": {
+                    "description": "Czy koszyk można zamówić bez zmian",
+                    "type": "boolean"
+                },
+                "item_count": {
+                    "description": "Łączna liczba sztuk",
+                    "type": "integer"
+                },
+                "items": {
+                    "description": "Wycenione pozycje koszyka",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.CartLine"
                     }
                 },
+                "total": {
+                    "description": "Łączna wartość pozycji według aktualnych cen",
+                    "type": "number"
+                },
                 "updated_at": {
-                    "description": "Data ostatniej aktualizacji",
+                    "description": "Data ostatniej zmiany koszyka (brak dla koszyka, który nie był zapisany)",
                     "type": "string"
+                },
+                "warnings": {
+                    "description": "Ostrzeżenia dotyczące pozycji (np. brak na magazynie)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CheckoutRequest": {
+            "type": "object",
+            "properties": {
+                "shipping": {
+                    "description": "Opcjonalne dane do wysyłki",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ShippingDetails"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.CreateOrderRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "Lista zamawianych albumów",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderItemRequest"
+                    }
+                },
+                "shipping": {
+                    "description": "Dane do wysyłki",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ShippingDetails"
+                        }
+                    ]
                 }
             }
         },
@@ -1497,6 +4587,17 @@ const docTemplate = `{
                     "description": "Data utworzenia zamówienia",
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "Data przeniesienia do kosza (tylko dla dokumentów w koszu)",
+                    "type": "string"
+                },
+                "history": {
+                    "description": "Historia zmian statusu zamówienia",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatusHistory"
+                    }
+                },
                 "id": {
                     "description": "ID zamówienia (unikalny identyfikator)",
                     "type": "string"
@@ -1531,6 +4632,10 @@ const docTemplate = `{
                 "user_id": {
                     "description": "ID użytkownika, który złożył zamówienie",
                     "type": "string"
+                },
+                "version": {
+                    "description": "Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)",
+                    "type": "integer"
                 }
             }
         },
@@ -1541,13 +4646,75 @@ const docTemplate = `{
                     "description": "ID albumu w zamówieniu",
                     "type": "string"
                 },
+                "format": {
+                    "description": "Format wersji w momencie zamówienia",
+                    "type": "string"
+                },
                 "price": {
-                    "description": "Cena jednostkowa albumu w momencie zamówienia",
+                    "description": "Cena jednostkowa wersji w momencie zamówienia",
                     "type": "number"
                 },
                 "quantity": {
                     "description": "Ilość sztuk albumu",
                     "type": "integer"
+                },
+                "sku": {
+                    "description": "Kod SKU wersji w momencie zamówienia",
+                    "type": "string"
+                },
+                "variant_id": {
+                    "description": "ID zamówionej wersji albumu (brak w zamówieniach sprzed wprowadzenia wersji)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderItemRequest": {
+            "type": "object",
+            "properties": {
+                "album_id": {
+                    "description": "ID zamawianego albumu",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "Liczba sztuk",
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "description": "ID zamawianej wersji (wymagane, gdy album ma kilka wersji)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ParamError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "Opis błędu",
+                    "type": "string"
+                },
+                "param": {
+                    "description": "Nazwa parametru",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Przekazana wartość",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReferenceConflictResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Komunikat błędu",
+                    "type": "string"
+                },
+                "references": {
+                    "description": "Odwołania blokujące usunięcie",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BlockingReference"
+                    }
                 }
             }
         },
@@ -1566,6 +4733,10 @@ const docTemplate = `{
                     "description": "Data utworzenia recenzji",
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "Data przeniesienia do kosza (tylko dla dokumentów w koszu)",
+                    "type": "string"
+                },
                 "id": {
                     "description": "ID recenzji (unikalny identyfikator)",
                     "type": "string"
@@ -1575,8 +4746,12 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "user_id": {
-                    "description": "ID użytkownika, który dodał recenzję",
+                    "description": "ID użytkownika, który dodał recenzję (zerowe po usunięciu jego konta)",
                     "type": "string"
+                },
+                "version": {
+                    "description": "Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)",
+                    "type": "integer"
                 }
             }
         },
@@ -1605,6 +4780,31 @@ const docTemplate = `{
                 }
             }
         },
+        "models.StatusHistory": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "description": "ID użytkownika, który zmienił status",
+                    "type": "string"
+                },
+                "changed_at": {
+                    "description": "Data zmiany",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "Poprzedni status (pusty dla utworzenia zamówienia)",
+                    "type": "string"
+                },
+                "note": {
+                    "description": "Opcjonalna notatka do zmiany",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "Nowy status",
+                    "type": "string"
+                }
+            }
+        },
         "models.SuccessResponse": {
             "type": "object",
             "properties": {
@@ -1614,6 +4814,48 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Track": {
+            "type": "object",
+            "properties": {
+                "disc": {
+                    "description": "Numer płyty (od 1; domyślnie 1)",
+                    "type": "integer"
+                },
+                "duration": {
+                    "description": "Czas trwania w sekundach",
+                    "type": "integer"
+                },
+                "explicit": {
+                    "description": "Czy utwór zawiera treści dla dorosłych",
+                    "type": "boolean"
+                },
+                "isrc": {
+                    "description": "Międzynarodowy kod nagrania (ISRC), np. GBAYE7300105",
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Pozycja utworu na płycie (od 1; domyślnie kolejna na danej płycie)",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "Tytuł utworu",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateOrderStatusRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "description": "Opcjonalna notatka zapisywana w historii",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Nowy status zamówienia",
+                    "type": "string"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
@@ -1621,6 +4863,10 @@ const docTemplate = `{
                     "description": "Data utworzenia użytkownika",
                     "type": "string"
                 },
+                "deleted_at": {
+                    "description": "Data przeniesienia do kosza (tylko dla dokumentów w koszu)",
+                    "type": "string"
+                },
                 "email": {
                     "description": "Adres email użytkownika",
                     "type": "string"
@@ -1645,7 +4891,7 @@ const docTemplate = `{
                     "description": "Hasło",
                     "type": "string"
                 },
-                "phoneNumber": {
+                "phone_number": {
                     "description": "Numer telefonu",
                     "type": "string"
                 },
@@ -1664,6 +4910,59 @@ const docTemplate = `{
                 "updated_at": {
                     "description": "Data ostatniej aktualizacji",
                     "type": "string"
+                },
+                "version": {
+                    "description": "Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "description": "Błędy poszczególnych parametrów",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ParamError"
+                    }
+                },
+                "error": {
+                    "description": "Komunikat błędu",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Variant": {
+            "type": "object",
+            "properties": {
+                "ean": {
+                    "description": "Kod kreskowy EAN-13",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "Format: vinyl, cd, cassette lub digital",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID wersji (nadawane przez serwer)",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Cena wersji",
+                    "type": "number"
+                },
+                "sku": {
+                    "description": "Kod magazynowy (SKU), unikalny w całym sklepie; domyślnie generowany",
+                    "type": "string"
+                },
+                "stock": {
+                    "description": "Liczba dostępnych sztuk",
+                    "type": "integer"
+                },
+                "weight": {
+                    "description": "Waga przesyłki w gramach (0 dla wersji cyfrowej)",
+                    "type": "integer"
                 }
             }
         }
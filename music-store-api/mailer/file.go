@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer zapisuje wiadomości w logu oraz, gdy podano katalog, w plikach
+// .eml. Przeznaczony do pracy lokalnej bez serwera SMTP.
+type FileMailer struct {
+	// Katalog wiadomości; pusty – wiadomości trafiają tylko do logu
+	Dir string
+	// Adres nadawcy
+	From string
+}
+
+// NewFileMailer tworzy FileMailer, zakładając w razie potrzeby katalog dir
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("tworzenie katalogu wiadomości %s: %w", dir, err)
+		}
+	}
+	return &FileMailer{Dir: dir, From: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	data, err := msg.format(m.From)
+	if err != nil {
+		return err
+	}
+	if m.Dir == "" {
+		log.Printf("Wiadomość e-mail do %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	// Nazwa pliku zaczyna się od czasu wysłania, więc pliki układają się chronologicznie
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, msg.To))
+	path := filepath.Join(m.Dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("zapis wiadomości %s: %w", path, err)
+	}
+	log.Printf("Wiadomość e-mail do %s zapisana w %s", msg.To, path)
+	return nil
+}
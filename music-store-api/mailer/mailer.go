@@ -0,0 +1,62 @@
+// Package mailer definiuje wysyłkę wiadomości e-mail do użytkowników
+// oraz jej implementacje: SMTP oraz zapis do plików i logu.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"music-store-api/config"
+)
+
+// Message jest wiadomością tekstową do jednego odbiorcy
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer wysyła wiadomości e-mail
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New tworzy mailer według sterownika z konfiguracji (mail.driver)
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case config.MailDriverSMTP:
+		return &SMTPMailer{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.From,
+		}, nil
+	case config.MailDriverFile:
+		return NewFileMailer(cfg.Dir, cfg.From)
+	}
+	return nil, fmt.Errorf("nieznany sposób wysyłki poczty %q", cfg.Driver)
+}
+
+// format zwraca wiadomość w formacie RFC 5322 z treścią w UTF-8
+func (m Message) format(from string) ([]byte, error) {
+	if strings.ContainsAny(m.To+from, "\r\n") {
+		return nil, errors.New("niepoprawny adres w nagłówku wiadomości")
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	body := strings.ReplaceAll(m.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTPMailer wysyła wiadomości przez serwer SMTP. Połączenie jest
+// szyfrowane przez STARTTLS, jeśli serwer je obsługuje.
+type SMTPMailer struct {
+	Host string
+	Port int
+	// Dane logowania (PLAIN); puste wyłączają uwierzytelnianie
+	Username string
+	Password string
+	// Adres nadawcy
+	From string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := msg.format(m.From)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, strconv.Itoa(m.Port)))
+	if err != nil {
+		return fmt.Errorf("połączenie z serwerem SMTP: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("połączenie z serwerem SMTP: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return fmt.Errorf("STARTTLS: %w", err)
+		}
+	}
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return fmt.Errorf("uwierzytelnianie SMTP: %w", err)
+		}
+	}
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
 	"music-store-api/config"
 	"music-store-api/controllers"
 	_ "music-store-api/docs"
+	"music-store-api/mailer"
 	"music-store-api/middleware"
+	"music-store-api/store"
 	"music-store-api/tests"
 
 	"github.com/gin-gonic/gin"
@@ -28,13 +36,70 @@ import (
 // @name Authorization
 // @description Token JWT w formacie "Bearer <token>", wymagany do autoryzacji endpointów chronionych.
 func main() {
-	config.ConnectDB()
-	defer config.DisconnectDB()
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji:\n%v", err)
+	}
+
+	memoryBackend := cfg.Storage == config.StorageMemory
+
+	var stores store.Stores
+	if memoryBackend {
+		stores = store.NewMemoryStores()
+	} else {
+		config.ConnectDB()
+		defer config.DisconnectDB()
+		stores = store.NewMongoStores(config.Client, config.DB)
 
-	controllers.InitAlbumCollection()
-	controllers.InitUserCollection()
-	controllers.InitOrderCollection()
-	controllers.InitReviewCollection()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := store.EnsureMongoIndexes(ctx, config.DB); err != nil {
+			log.Fatalf("Błąd przygotowania indeksów MongoDB: %v", err)
+		}
+		cancel()
+	}
+
+	controllers.InitAlbumStore(stores.Albums)
+	controllers.InitUserStore(stores.Users)
+	controllers.InitOrderStore(stores.Orders)
+	controllers.InitReviewStore(stores.Reviews)
+	controllers.InitCartStore(stores.Carts)
+	controllers.InitCoverStore(stores.Covers)
+	controllers.InitArtistStore(stores.Artists)
+	controllers.InitTokenStore(stores.Tokens)
+	controllers.InitRefreshTokenStore(stores.RefreshTokens)
+	controllers.InitRevokedTokenStore(stores.RevokedTokens)
+	middleware.InitUserStore(stores.Users)
+	middleware.InitRevokedTokenStore(stores.RevokedTokens)
+
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji poczty: %v", err)
+	}
+	controllers.InitMailer(mail)
+
+	if memoryBackend {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := controllers.SeedTestData(ctx); err != nil {
+			log.Fatalf("Błąd wczytywania danych testowych: %v", err)
+		}
+		cancel()
+		log.Println("Backend w pamięci zainicjalizowany danymi testowymi")
+	} else {
+		// Albumy zapisane przed wprowadzeniem wykonawców otrzymują artist_id
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		migrated, err := store.MigrateAlbumArtists(ctx, stores.Artists, stores.Albums)
+		if err != nil {
+			log.Fatalf("Błąd migracji wykonawców albumów: %v", err)
+		}
+		cancel()
+		if migrated > 0 {
+			log.Printf("Przypisano wykonawców do %d albumów", migrated)
+		}
+	}
+
+	if cfg.Trash.RetentionDays > 0 {
+		go purgeTrashPeriodically(cfg.Trash)
+	}
 
 	r := gin.Default()
 
@@ -45,17 +110,40 @@ func main() {
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS)
 	r.POST("/login", controllers.Login)
+	r.POST("/register", controllers.Register)
+	r.GET("/verify", controllers.VerifyEmail)
+	r.POST("/password/forgot", controllers.ForgotPassword)
+	r.POST("/password/reset", controllers.ResetPassword)
+	r.POST("/token/refresh", controllers.RefreshToken)
+	r.POST("/logout", middleware.AuthMiddleware(), controllers.Logout)
+	r.POST("/logout-all", middleware.AuthMiddleware(), controllers.LogoutAll)
 
 	albumRoutes := r.Group("/albums")
 	albumRoutes.GET("", controllers.GetAlbums)
+	albumRoutes.GET("/search", controllers.SearchAlbums)
 	albumRoutes.GET("/:id", controllers.GetAlbumByID)
+	albumRoutes.GET("/:id/cover", controllers.GetAlbumCover)
 	albumRoutes.Use(middleware.AuthMiddleware())
 	{
 		albumRoutes.POST("", middleware.RoleMiddleware("employee", "admin"), controllers.CreateAlbum)
 		albumRoutes.POST("/bulk", middleware.RoleMiddleware("employee", "admin"), controllers.CreateAlbumsBulk)
 		albumRoutes.PATCH("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateAlbum)
+		albumRoutes.POST("/:id/cover", middleware.RoleMiddleware("employee", "admin"), controllers.UploadAlbumCover)
 		albumRoutes.DELETE("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.DeleteAlbum)
+		albumRoutes.POST("/:id/restore", middleware.RoleMiddleware("admin"), controllers.RestoreAlbum)
+	}
+
+	artistRoutes := r.Group("/artists")
+	artistRoutes.GET("", controllers.GetArtists)
+	artistRoutes.GET("/:id", controllers.GetArtistByID)
+	artistRoutes.GET("/:id/albums", controllers.GetArtistAlbums)
+	artistRoutes.Use(middleware.AuthMiddleware())
+	{
+		artistRoutes.POST("", middleware.RoleMiddleware("employee", "admin"), controllers.CreateArtist)
+		artistRoutes.PATCH("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateArtist)
+		artistRoutes.DELETE("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.DeleteArtist)
 	}
 
 	userRoutes := r.Group("/users")
@@ -66,18 +154,32 @@ func main() {
 		userRoutes.POST("", middleware.RoleMiddleware("admin"), controllers.CreateUser)
 		userRoutes.PATCH("/:id", middleware.RoleMiddleware("admin"), controllers.UpdateUser)
 		userRoutes.DELETE("/:id", middleware.RoleMiddleware("admin"), controllers.DeleteUser)
+		userRoutes.POST("/:id/restore", middleware.RoleMiddleware("admin"), controllers.RestoreUser)
+	}
+
+	meRoutes := r.Group("/me")
+	meRoutes.Use(middleware.AuthMiddleware())
+	{
+		meRoutes.GET("", controllers.GetMe)
+		meRoutes.PATCH("", controllers.UpdateMe)
+		meRoutes.POST("/password", controllers.ChangeMyPassword)
 	}
 
 	orderRoutes := r.Group("/orders")
 	orderRoutes.Use(middleware.AuthMiddleware())
 	{
 		orderRoutes.GET("/", middleware.RoleMiddleware("employee", "admin"), controllers.GetOrders)
+		orderRoutes.GET("/me", controllers.GetMyOrders)
+		orderRoutes.GET("/me/:id", controllers.GetMyOrderByID)
+		orderRoutes.POST("/me/:id/cancel", controllers.CancelMyOrder)
 		orderRoutes.GET("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.GetOrderByID)
 		orderRoutes.GET("/user/:userID", middleware.RoleMiddleware("employee", "admin"), controllers.GetOrdersByUserID)
 		orderRoutes.POST("/", middleware.RoleMiddleware("customer", "employee", "admin"), controllers.CreateOrder)
 		orderRoutes.PUT("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateOrder)
 		orderRoutes.DELETE("/:id", middleware.RoleMiddleware("admin"), controllers.DeleteOrder)
+		orderRoutes.POST("/:id/restore", middleware.RoleMiddleware("admin"), controllers.RestoreOrder)
 		orderRoutes.PATCH("/:id/status", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateOrderStatus)
+		orderRoutes.GET("/:id/history", middleware.RoleMiddleware("employee", "admin"), controllers.GetOrderHistory)
 		orderRoutes.PUT("/:id/shipping", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateOrderShipping)
 	}
 
@@ -91,6 +193,24 @@ func main() {
 		reviewRoutes.POST("", middleware.RoleMiddleware("customer", "employee", "admin"), controllers.CreateReview)
 		reviewRoutes.PUT("/:id", middleware.RoleMiddleware("customer", "employee", "admin"), controllers.UpdateReview)
 		reviewRoutes.DELETE("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.DeleteReview)
+		reviewRoutes.POST("/:id/restore", middleware.RoleMiddleware("admin"), controllers.RestoreReview)
+	}
+
+	trashRoutes := r.Group("/trash")
+	trashRoutes.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("admin"))
+	{
+		trashRoutes.GET("/:resource", controllers.GetTrash)
+	}
+
+	cartRoutes := r.Group("/cart")
+	cartRoutes.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("customer", "employee", "admin"))
+	{
+		cartRoutes.GET("", controllers.GetCart)
+		cartRoutes.DELETE("", controllers.ClearCart)
+		cartRoutes.POST("/items", controllers.AddCartItem)
+		cartRoutes.PUT("/items/:albumID", controllers.UpdateCartItem)
+		cartRoutes.DELETE("/items/:albumID", controllers.RemoveCartItem)
+		cartRoutes.POST("/checkout", controllers.Checkout)
 	}
 
 	dataRoutes := r.Group("/data")
@@ -101,5 +221,28 @@ func main() {
 
 	r.GET("/run-tests", tests.RunTestsHandler)
 
-	r.Run(":25565")
+	server := &http.Server{
+		Addr:         cfg.Server.ListenAddr,
+		Handler:      r,
+		ReadTimeout:  cfg.Server.ReadTimeout.Std(),
+		WriteTimeout: cfg.Server.WriteTimeout.Std(),
+		IdleTimeout:  cfg.Server.IdleTimeout.Std(),
+	}
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("Błąd serwera HTTP: %v", err)
+	}
+}
+
+// purgeTrashPeriodically co trash.purge_interval trwale usuwa dokumenty
+// przeniesione do kosza ponad trash.retention_days dni temu
+func purgeTrashPeriodically(trash config.TrashConfig) {
+	ticker := time.NewTicker(trash.PurgeInterval.Std())
+	defer ticker.Stop()
+	for ; ; <-ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		if err := controllers.PurgeTrash(ctx, time.Now().Add(-trash.Retention())); err != nil {
+			log.Printf("Błąd czyszczenia kosza: %v", err)
+		}
+		cancel()
+	}
 }
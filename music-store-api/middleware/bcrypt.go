@@ -1,9 +1,13 @@
 package middleware
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"music-store-api/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), config.App.Security.BcryptCost)
 	return string(bytes), err
 }
 
@@ -1,14 +1,79 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"music-store-api/config"
 	"music-store-api/models"
+	"music-store-api/store"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var userStore store.UserStore
+
+var revokedTokenStore store.RevokedTokenStore
+
+// InitUserStore ustawia repozytorium użytkowników, w którym AuthMiddleware
+// sprawdza, czy token nie został unieważniony
+func InitUserStore(s store.UserStore) {
+	userStore = s
+}
+
+// InitRevokedTokenStore ustawia listę unieważnionych tokenów (jti) sprawdzaną przez AuthMiddleware
+func InitRevokedTokenStore(s store.RevokedTokenStore) {
+	revokedTokenStore = s
+}
+
+// errSessionRevoked oznacza token unieważniony przed wygaśnięciem
+var errSessionRevoked = errors.New("token został unieważniony")
+
+// checkSession sprawdza, czy token nie jest na liście unieważnionych, czy
+// konto z tokenu istnieje, jest aktywne i ma rolę z tokenu oraz czy token
+// wydano po ostatnim unieważnieniu wszystkich tokenów użytkownika
+func checkSession(claims *config.Claims) error {
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return errSessionRevoked
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.App.Database.QueryTimeout.Std())
+	defer cancel()
+
+	if claims.ID != "" {
+		revoked, err := revokedTokenStore.IsRevoked(ctx, claims.ID)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return errSessionRevoked
+		}
+	}
+
+	user, err := userStore.GetByID(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return errSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsActive {
+		return errSessionRevoked
+	}
+	// Po zmianie roli klient musi odświeżyć token, aby otrzymać nowe uprawnienia
+	if user.Role != claims.Role {
+		return errSessionRevoked
+	}
+	// Token wydany w tej samej milisekundzie co unieważnienie też jest odrzucany
+	if user.TokensRevokedAt != nil && claims.IssuedAt != nil &&
+		!claims.IssuedAt.Time.After(*user.TokensRevokedAt) {
+		return errSessionRevoked
+	}
+	return nil
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -26,8 +91,19 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if err := checkSession(claims); errors.Is(err, errSessionRevoked) {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Token został unieważniony, odśwież go lub zaloguj się ponownie"})
+			c.Abort()
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Błąd weryfikacji tokena"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", claims.UserID)
 		c.Set("userRole", claims.Role)
+		c.Set("tokenClaims", claims)
 		c.Next()
 	}
 }
@@ -1,8 +1,14 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -13,8 +19,10 @@ type Album struct {
 	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	// Tytuł albumu
 	Title string `bson:"title" json:"title"`
-	// Nazwa wykonawcy
+	// Nazwa wykonawcy (ujednolicana do nazwy wykonawcy artist_id)
 	Artist string `bson:"artist" json:"artist"`
+	// ID wykonawcy
+	ArtistID primitive.ObjectID `bson:"artist_id,omitempty" json:"artist_id"`
 	// Gatunek muzyczny
 	Genre string `bson:"genre" json:"genre"`
 	// Opis albumu
@@ -22,15 +30,180 @@ type Album struct {
 	// Data wydania
 	ReleaseDate time.Time `bson:"release_date" json:"release_date"`
 	// Lista utworów
-	Tracks []string `bson:"tracks,omitempty" json:"tracks,omitempty"`
-	// Cena albumu
+	Tracks TrackList `bson:"tracks,omitempty" json:"tracks,omitempty"`
+	// Łączny czas trwania utworów w sekundach (wyliczany przez repozytorium)
+	TotalDuration int `bson:"total_duration" json:"total_duration"`
+	// Wersje albumu na poszczególnych nośnikach (bez nich tworzona jest jedna wersja CD z price i quantity)
+	Variants []Variant `bson:"variants,omitempty" json:"variants"`
+	// Najniższa cena spośród wersji albumu (wyliczana przez repozytorium)
 	Price float64 `bson:"price" json:"price"`
-	// Ilość dostępnych sztuk
+	// Łączna liczba dostępnych sztuk wszystkich wersji (wyliczana przez repozytorium)
 	Quantity int `bson:"quantity" json:"quantity"`
 	// Data utworzenia wpisu
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	// Data ostatniej aktualizacji
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	// Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)
+	Version int64 `bson:"version" json:"version"`
+	// Data przeniesienia do kosza (tylko dla dokumentów w koszu)
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 	// URL do okładki albumu
 	CoverURL string `bson:"cover_url,omitempty" json:"cover_url,omitempty"`
+	// Tekst do wyszukiwania bez znaków diakrytycznych (wyliczany przez repozytorium)
+	SearchText string `bson:"search_text,omitempty" json:"-" swaggerignore:"true"`
+}
+
+// Track reprezentuje utwór na albumie
+// swagger:model Track
+type Track struct {
+	// Pozycja utworu na płycie (od 1; domyślnie kolejna na danej płycie)
+	Position int `bson:"position" json:"position"`
+	// Numer płyty (od 1; domyślnie 1)
+	Disc int `bson:"disc" json:"disc"`
+	// Tytuł utworu
+	Title string `bson:"title" json:"title"`
+	// Czas trwania w sekundach
+	Duration int `bson:"duration" json:"duration"`
+	// Międzynarodowy kod nagrania (ISRC), np. GBAYE7300105
+	ISRC string `bson:"isrc,omitempty" json:"isrc,omitempty"`
+	// Czy utwór zawiera treści dla dorosłych
+	Explicit bool `bson:"explicit" json:"explicit"`
+}
+
+// TrackList to lista utworów albumu. Przy odczycie z JSON i BSON przyjmuje
+// również dawny format – tablicę samych tytułów – i zamienia go na utwory
+// z kolejnymi pozycjami na pierwszej płycie.
+type TrackList []Track
+
+// UnmarshalJSON przyjmuje tablicę obiektów utworów lub tytułów
+func (l *TrackList) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	if items == nil {
+		*l = nil
+		return nil
+	}
+	tracks := make(TrackList, 0, len(items))
+	for _, item := range items {
+		var title string
+		if err := json.Unmarshal(item, &title); err == nil {
+			tracks = append(tracks, Track{Title: title})
+			continue
+		}
+		var track Track
+		if err := json.Unmarshal(item, &track); err != nil {
+			return err
+		}
+		tracks = append(tracks, track)
+	}
+	*l = tracks.normalized()
+	return nil
+}
+
+// UnmarshalBSONValue odczytuje utwory zapisane jako dokumenty lub (w starszych
+// dokumentach) jako same tytuły
+func (l *TrackList) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bsontype.Null || t == bsontype.Undefined {
+		*l = nil
+		return nil
+	}
+	if t != bsontype.Array {
+		return fmt.Errorf("tracks: nieobsługiwany typ BSON %s", t)
+	}
+	values, err := bson.Raw(data).Values()
+	if err != nil {
+		return err
+	}
+	tracks := make(TrackList, 0, len(values))
+	for _, value := range values {
+		switch value.Type {
+		case bsontype.String:
+			tracks = append(tracks, Track{Title: value.StringValue()})
+		case bsontype.EmbeddedDocument:
+			var track Track
+			if err := value.Unmarshal(&track); err != nil {
+				return err
+			}
+			tracks = append(tracks, track)
+		default:
+			return fmt.Errorf("tracks: nieobsługiwany typ elementu BSON %s", value.Type)
+		}
+	}
+	*l = tracks.normalized()
+	return nil
+}
+
+// normalized uzupełnia brakujące numery płyt i pozycji, ujednolica zapis
+// kodów ISRC i porządkuje utwory według płyty i pozycji
+func (l TrackList) normalized() TrackList {
+	last := map[int]int{}
+	for i := range l {
+		track := &l[i]
+		if track.Disc == 0 {
+			track.Disc = 1
+		}
+		if track.Position == 0 {
+			track.Position = last[track.Disc] + 1
+		}
+		last[track.Disc] = max(last[track.Disc], track.Position)
+		track.ISRC = strings.ToUpper(strings.ReplaceAll(track.ISRC, "-", ""))
+	}
+	slices.SortStableFunc(l, func(a, b Track) int {
+		if a.Disc != b.Disc {
+			return a.Disc - b.Disc
+		}
+		return a.Position - b.Position
+	})
+	return l
+}
+
+// TotalDuration zwraca łączny czas trwania utworów w sekundach
+func (l TrackList) TotalDuration() int {
+	total := 0
+	for _, track := range l {
+		total += track.Duration
+	}
+	return total
+}
+
+// Titles zwraca tytuły utworów w kolejności na albumie
+func (l TrackList) Titles() []string {
+	titles := make([]string, len(l))
+	for i, track := range l {
+		titles[i] = track.Title
+	}
+	return titles
+}
+
+// AlbumSearchResult reprezentuje album znaleziony w wyszukiwaniu pełnotekstowym
+// swagger:model AlbumSearchResult
+type AlbumSearchResult struct {
+	Album `bson:",inline"`
+	// Trafność dopasowania do zapytania (wyższa = lepsza)
+	Score float64 `bson:"score" json:"score"`
+}
+
+// AlbumFacets zawiera liczności albumów dla wartości filtrów katalogu.
+// Wartości można przekazać z powrotem w parametrach genre, artist, decade i price_range.
+// swagger:model AlbumFacets
+type AlbumFacets struct {
+	// Liczba albumów w poszczególnych gatunkach
+	Genre []FacetCount `json:"genre"`
+	// Liczba albumów poszczególnych wykonawców
+	Artist []FacetCount `json:"artist"`
+	// Liczba albumów wydanych w poszczególnych dekadach (np. "1980")
+	Decade []FacetCount `json:"decade"`
+	// Liczba albumów w przedziałach cenowych (np. "20-30", "50+")
+	Price []FacetCount `json:"price"`
+}
+
+// FacetCount reprezentuje wartość facetu i liczbę pasujących albumów
+// swagger:model FacetCount
+type FacetCount struct {
+	// Wartość filtra
+	Value string `json:"value"`
+	// Liczba albumów
+	Count int64 `json:"count"`
 }
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Artist reprezentuje wykonawcę (artystę lub zespół)
+// swagger:model Artist
+type Artist struct {
+	// ID wykonawcy
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	// Nazwa wykonawcy
+	Name string `bson:"name" json:"name"`
+	// Nazwa używana do sortowania, np. "Beatles, The" (domyślnie nazwa bez przedimka)
+	SortName string `bson:"sort_name" json:"sort_name"`
+	// Kod kraju pochodzenia (ISO 3166-1 alfa-2), np. GB
+	Country string `bson:"country,omitempty" json:"country,omitempty"`
+	// Biografia wykonawcy
+	Bio string `bson:"bio,omitempty" json:"bio,omitempty"`
+	// Inne nazwy wykonawcy, pod którymi może występować w albumach
+	Aliases []string `bson:"aliases,omitempty" json:"aliases,omitempty"`
+	// Rok założenia zespołu lub rozpoczęcia działalności
+	FormedYear int `bson:"formed_year,omitempty" json:"formed_year,omitempty"`
+	// Rok rozwiązania zespołu lub zakończenia działalności
+	DisbandedYear int `bson:"disbanded_year,omitempty" json:"disbanded_year,omitempty"`
+	// Data utworzenia wpisu
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	// Data ostatniej aktualizacji
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	// Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)
+	Version int64 `bson:"version" json:"version"`
+	// Klucze nazwy i aliasów do wyszukiwania wykonawcy (wyliczane przez repozytorium)
+	NameKeys []string `bson:"name_keys,omitempty" json:"-" swaggerignore:"true"`
+}
+
+// DefaultSortName zwraca nazwę do sortowania: angielski przedimek z początku
+// nazwy przenoszony jest na koniec ("The Beatles" → "Beatles, The")
+func DefaultSortName(name string) string {
+	name = strings.TrimSpace(name)
+	for _, article := range []string{"The ", "A ", "An "} {
+		if len(name) > len(article) && strings.EqualFold(name[:len(article)], article) {
+			return name[len(article):] + ", " + name[:len(article)-1]
+		}
+	}
+	return name
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cart reprezentuje koszyk zalogowanego użytkownika. Każdy użytkownik ma
+// co najwyżej jeden koszyk, identyfikowany jego ID.
+// swagger:model Cart
+type Cart struct {
+	// ID właściciela koszyka
+	UserID primitive.ObjectID `bson:"_id" json:"user_id"`
+	// Pozycje koszyka
+	Items []CartItem `bson:"items" json:"items"`
+	// Data ostatniej zmiany koszyka
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// CartItem reprezentuje wersję albumu odłożoną do koszyka. Cena nie jest
+// zapisywana – wyliczana jest zawsze z aktualnych danych wersji.
+// swagger:model CartItem
+type CartItem struct {
+	// ID albumu
+	AlbumID primitive.ObjectID `bson:"album_id" json:"album_id"`
+	// ID wersji albumu
+	VariantID primitive.ObjectID `bson:"variant_id,omitempty" json:"variant_id,omitempty"`
+	// Liczba sztuk
+	Quantity int `bson:"quantity" json:"quantity"`
+}
+
+// CartView reprezentuje koszyk wyceniony według aktualnych cen i stanów magazynowych
+// swagger:model CartView
+type CartView struct {
+	// Wycenione pozycje koszyka
+	Items []CartLine `json:"items"`
+	// Łączna liczba sztuk
+	ItemCount int `json:"item_count"`
+	// Łączna wartość pozycji według aktualnych cen
+	Total float64 `json:"total"`
+	// Czy koszyk można zamówić bez zmian
+	CanCheckout bool `json:"can_checkout"`
+	// Ostrzeżenia dotyczące pozycji (np. brak na magazynie)
+	Warnings []string `json:"warnings"`
+	// Data ostatniej zmiany koszyka (brak dla koszyka, który nie był zapisany)
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// CartLine reprezentuje wycenioną pozycję koszyka
+// swagger:model CartLine
+type CartLine struct {
+	// ID albumu
+	AlbumID primitive.ObjectID `json:"album_id"`
+	// Tytuł albumu
+	Title string `json:"title,omitempty"`
+	// Wykonawca albumu
+	Artist string `json:"artist,omitempty"`
+	// ID wersji albumu
+	VariantID primitive.ObjectID `json:"variant_id,omitempty"`
+	// Format wersji
+	Format string `json:"format,omitempty"`
+	// Kod SKU wersji
+	SKU string `json:"sku,omitempty"`
+	// Liczba sztuk w koszyku
+	Quantity int `json:"quantity"`
+	// Aktualna cena jednostkowa
+	Price float64 `json:"price"`
+	// Wartość pozycji (cena × ilość)
+	LineTotal float64 `json:"line_total"`
+	// Liczba sztuk wersji dostępnych w magazynie
+	Available int `json:"available"`
+	// Czy pozycję można zamówić w całości
+	InStock bool `json:"in_stock"`
+	// Ostrzeżenie dotyczące pozycji
+	Warning string `json:"warning,omitempty"`
+}
+
+// CartItemRequest reprezentuje żądanie dodania albumu do koszyka
+// swagger:model CartItemRequest
+type CartItemRequest struct {
+	// ID albumu
+	AlbumID primitive.ObjectID `json:"album_id"`
+	// ID wersji (wymagane, gdy album ma kilka wersji)
+	VariantID primitive.ObjectID `json:"variant_id,omitempty"`
+	// Liczba dodawanych sztuk
+	Quantity int `json:"quantity"`
+}
+
+// CartQuantityRequest reprezentuje żądanie zmiany liczby sztuk pozycji koszyka
+// swagger:model CartQuantityRequest
+type CartQuantityRequest struct {
+	// Nowa liczba sztuk
+	Quantity int `json:"quantity"`
+}
+
+// CheckoutRequest reprezentuje żądanie złożenia zamówienia z koszyka.
+// Bez danych wysyłki używane są dane zapisane w profilu użytkownika.
+// swagger:model CheckoutRequest
+type CheckoutRequest struct {
+	// Opcjonalne dane do wysyłki
+	Shipping *ShippingDetails `json:"shipping,omitempty"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,6 +15,45 @@ const (
 	OrderStatusCancelled  = "cancelled"
 )
 
+// OpenOrderStatuses zawiera statusy zamówień w realizacji (przed zakończeniem lub anulowaniem)
+var OpenOrderStatuses = []string{OrderStatusPending, OrderStatusProcessing, OrderStatusShipped}
+
+// orderTransitions określa dozwolone zmiany statusu zamówienia.
+// Anulowanie jest możliwe tylko przed wysyłką, completed i cancelled są końcowe.
+var orderTransitions = map[string][]string{
+	OrderStatusPending:    {OrderStatusProcessing, OrderStatusCancelled},
+	OrderStatusProcessing: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:    {OrderStatusCompleted},
+	OrderStatusCompleted:  {},
+	OrderStatusCancelled:  {},
+}
+
+// IsOpenOrderStatus sprawdza, czy zamówienie o danym statusie jest w realizacji
+func IsOpenOrderStatus(status string) bool {
+	return slices.Contains(OpenOrderStatuses, status)
+}
+
+// IsValidOrderStatus sprawdza, czy status jest jednym ze znanych statusów zamówienia
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// CanTransitionOrderStatus sprawdza, czy zamówienie może przejść ze statusu from do to
+func CanTransitionOrderStatus(from, to string) bool {
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedOrderTransitions zwraca statusy, do których można przejść ze statusu from
+func AllowedOrderTransitions(from string) []string {
+	return orderTransitions[from]
+}
+
 // Order reprezentuje zamówienie użytkownika
 // swagger:model Order
 type Order struct {
@@ -31,8 +71,38 @@ type Order struct {
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	// Data ostatniej aktualizacji zamówienia
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	// Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)
+	Version int64 `bson:"version" json:"version"`
+	// Data przeniesienia do kosza (tylko dla dokumentów w koszu)
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 	// Dane do wysyłki
 	Shipping ShippingDetails `bson:"shipping" json:"shipping"`
+	// Historia zmian statusu zamówienia
+	History []StatusHistory `bson:"history,omitempty" json:"history,omitempty"`
+}
+
+// StatusHistory reprezentuje pojedynczą zmianę statusu zamówienia
+// swagger:model StatusHistory
+type StatusHistory struct {
+	// Poprzedni status (pusty dla utworzenia zamówienia)
+	From string `bson:"from,omitempty" json:"from,omitempty"`
+	// Nowy status
+	To string `bson:"to" json:"to"`
+	// ID użytkownika, który zmienił status
+	ActorID primitive.ObjectID `bson:"actor_id" json:"actor_id"`
+	// Data zmiany
+	ChangedAt time.Time `bson:"changed_at" json:"changed_at"`
+	// Opcjonalna notatka do zmiany
+	Note string `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+// UpdateOrderStatusRequest reprezentuje żądanie zmiany statusu zamówienia
+// swagger:model UpdateOrderStatusRequest
+type UpdateOrderStatusRequest struct {
+	// Nowy status zamówienia
+	Status string `json:"status"`
+	// Opcjonalna notatka zapisywana w historii
+	Note string `json:"note,omitempty"`
 }
 
 // OrderItem reprezentuje pojedynczą pozycję zamówienia
@@ -40,8 +110,35 @@ type Order struct {
 type OrderItem struct {
 	// ID albumu w zamówieniu
 	AlbumID primitive.ObjectID `bson:"album_id" json:"album_id"`
+	// ID zamówionej wersji albumu (brak w zamówieniach sprzed wprowadzenia wersji)
+	VariantID primitive.ObjectID `bson:"variant_id,omitempty" json:"variant_id,omitempty"`
+	// Format wersji w momencie zamówienia
+	Format string `bson:"format,omitempty" json:"format,omitempty"`
+	// Kod SKU wersji w momencie zamówienia
+	SKU string `bson:"sku,omitempty" json:"sku,omitempty"`
 	// Ilość sztuk albumu
 	Quantity int `bson:"quantity" json:"quantity"`
-	// Cena jednostkowa albumu w momencie zamówienia
+	// Cena jednostkowa wersji w momencie zamówienia
 	Price float64 `bson:"price" json:"price"`
 }
+
+// CreateOrderRequest reprezentuje żądanie utworzenia zamówienia.
+// Ceny, suma i status wyliczane są po stronie serwera.
+// swagger:model CreateOrderRequest
+type CreateOrderRequest struct {
+	// Lista zamawianych albumów
+	Items []OrderItemRequest `json:"items"`
+	// Dane do wysyłki
+	Shipping ShippingDetails `json:"shipping"`
+}
+
+// OrderItemRequest reprezentuje pozycję w żądaniu utworzenia zamówienia
+// swagger:model OrderItemRequest
+type OrderItemRequest struct {
+	// ID zamawianego albumu
+	AlbumID primitive.ObjectID `json:"album_id"`
+	// ID zamawianej wersji (wymagane, gdy album ma kilka wersji)
+	VariantID primitive.ObjectID `json:"variant_id,omitempty"`
+	// Liczba sztuk
+	Quantity int `json:"quantity"`
+}
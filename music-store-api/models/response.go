@@ -1,5 +1,7 @@
 package models
 
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
 // ErrorResponse reprezentuje odpowiedź błędu
 // swagger:model ErrorResponse
 type ErrorResponse struct {
@@ -13,3 +15,46 @@ type SuccessResponse struct {
 	// Wiadomość o sukcesie
 	Message string `json:"message"`
 }
+
+// ValidationErrorResponse reprezentuje odpowiedź błędu walidacji parametrów zapytania
+// swagger:model ValidationErrorResponse
+type ValidationErrorResponse struct {
+	// Komunikat błędu
+	Error string `json:"error"`
+	// Błędy poszczególnych parametrów
+	Details []ParamError `json:"details"`
+}
+
+// ParamError opisuje niepoprawną wartość parametru zapytania
+// swagger:model ParamError
+type ParamError struct {
+	// Nazwa parametru
+	Param string `json:"param"`
+	// Przekazana wartość
+	Value string `json:"value"`
+	// Opis błędu
+	Message string `json:"message"`
+}
+
+// ReferenceConflictResponse reprezentuje odpowiedź 409, gdy usunięcie
+// dokumentu blokują odwołania z innych zasobów
+// swagger:model ReferenceConflictResponse
+type ReferenceConflictResponse struct {
+	// Komunikat błędu
+	Error string `json:"error"`
+	// Odwołania blokujące usunięcie
+	References []BlockingReference `json:"references"`
+}
+
+// BlockingReference opisuje dokumenty jednego zasobu wskazujące na usuwany dokument
+// swagger:model BlockingReference
+type BlockingReference struct {
+	// Zasób zawierający odwołania, np. orders
+	Resource string `json:"resource"`
+	// Opis relacji
+	Reason string `json:"reason"`
+	// Liczba wskazujących dokumentów
+	Count int `json:"count"`
+	// ID wskazujących dokumentów (najwyżej 20 pierwszych)
+	IDs []primitive.ObjectID `json:"ids"`
+}
@@ -13,7 +13,7 @@ type Review struct {
 	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	// ID albumu, którego dotyczy recenzja
 	AlbumID primitive.ObjectID `bson:"album_id" json:"album_id"`
-	// ID użytkownika, który dodał recenzję
+	// ID użytkownika, który dodał recenzję (zerowe po usunięciu jego konta)
 	UserID primitive.ObjectID `bson:"user_id" json:"user_id"`
 	// Ocena albumu (np. od 1 do 5)
 	Rating int `bson:"rating" json:"rating"`
@@ -21,4 +21,8 @@ type Review struct {
 	Comment string `bson:"comment" json:"comment"`
 	// Data utworzenia recenzji
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	// Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)
+	Version int64 `bson:"version" json:"version"`
+	// Data przeniesienia do kosza (tylko dla dokumentów w koszu)
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
 }
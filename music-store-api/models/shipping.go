@@ -14,3 +14,8 @@ type ShippingDetails struct {
 	// Numer telefonu kontaktowego
 	PhoneNumber string `bson:"phone_number" json:"phone_number"`
 }
+
+// Anonymized zwraca dane wysyłki bez danych osobowych; zachowywany jest tylko kraj
+func (s ShippingDetails) Anonymized() ShippingDetails {
+	return ShippingDetails{Country: s.Country}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Przeznaczenia jednorazowych tokenów użytkownika
+const (
+	// Potwierdzenie adresu e-mail i aktywacja konta po rejestracji
+	TokenPurposeVerification = "verification"
+	// Ustawienie nowego hasła przez POST /password/reset
+	TokenPurposePasswordReset = "password_reset"
+)
+
+// UserToken jest jednorazowym tokenem wysłanym użytkownikowi w wiadomości
+// e-mail. Przechowywany jest wyłącznie hash tokenu; sam token zna tylko
+// odbiorca wiadomości.
+type UserToken struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	UserID primitive.ObjectID `bson:"user_id"`
+	// Przeznaczenie tokenu, np. "verification"
+	Purpose string `bson:"purpose"`
+	// Hash SHA-256 tokenu (szesnastkowo)
+	Hash      string    `bson:"hash"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// RefreshToken jest tokenem odświeżania sesji. Każde odświeżenie wymienia
+// token na nowy z tej samej rodziny (sesji); ponowne użycie wymienionego
+// tokenu unieważnia całą rodzinę. Przechowywany jest wyłącznie hash tokenu.
+type RefreshToken struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	UserID primitive.ObjectID `bson:"user_id"`
+	// Rodzina tokenów jednej sesji, zapisywana w tokenach dostępu jako sid
+	FamilyID primitive.ObjectID `bson:"family_id"`
+	// Hash SHA-256 tokenu (szesnastkowo)
+	Hash      string    `bson:"hash"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	// Data wymiany tokenu na nowy
+	UsedAt *time.Time `bson:"used_at,omitempty"`
+	// Data unieważnienia (wylogowanie lub wykryte ponowne użycie)
+	RevokedAt *time.Time `bson:"revoked_at,omitempty"`
+}
+
+// RevokedToken jest unieważnionym tokenem dostępu (wpisem listy jti),
+// przechowywanym do chwili wygaśnięcia tokenu
+type RevokedToken struct {
+	ID primitive.ObjectID `bson:"_id"`
+	// Identyfikator tokenu (jti)
+	JTI       string    `bson:"jti"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
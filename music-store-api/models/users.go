@@ -6,6 +6,17 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	RoleCustomer = "customer"
+	RoleEmployee = "employee"
+	RoleAdmin    = "admin"
+)
+
+// IsValidRole sprawdza, czy rola jest jedną ze znanych ról użytkownika
+func IsValidRole(role string) bool {
+	return role == RoleCustomer || role == RoleEmployee || role == RoleAdmin
+}
+
 // User reprezentuje użytkownika systemu
 // swagger:model User
 type User struct {
@@ -17,8 +28,10 @@ type User struct {
 	LastName string `bson:"last_name" json:"last_name"`
 	// Adres email użytkownika
 	Email string `bson:"email" json:"email"`
+	// Adres email sprowadzony do klucza unikalności (wyliczany przez repozytorium)
+	EmailKey string `bson:"email_key,omitempty" json:"-" swaggerignore:"true"`
 	// Numer telefonu
-	PhoneNumber string `bson:"phone_number,omitempty"`
+	PhoneNumber string `bson:"phone_number,omitempty" json:"phone_number,omitempty"`
 	// Hasło
 	Password string `bson:"-" json:"password"`
 	// Hash hasła (niewidoczny w API)
@@ -29,6 +42,13 @@ type User struct {
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	// Data ostatniej aktualizacji
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+	// Wersja dokumentu zwiększana przy każdej zmianie (zwracana również jako ETag)
+	Version int64 `bson:"version" json:"version"`
+	// Data przeniesienia do kosza (tylko dla dokumentów w koszu)
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	// Data unieważnienia wszystkich tokenów (reset hasła, wylogowanie ze
+	// wszystkich urządzeń); tokeny JWT wydane wcześniej są odrzucane
+	TokensRevokedAt *time.Time `bson:"tokens_revoked_at,omitempty" json:"-"`
 	// Czy konto jest aktywne
 	IsActive bool `bson:"is_active" json:"is_active"`
 	// Dane adresowe
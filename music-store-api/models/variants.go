@@ -0,0 +1,99 @@
+package models
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Formaty (nośniki), w których sprzedawane są albumy
+const (
+	FormatVinyl    = "vinyl"
+	FormatCD       = "cd"
+	FormatCassette = "cassette"
+	FormatDigital  = "digital"
+)
+
+// Formats zawiera wszystkie obsługiwane formaty w kolejności prezentacji
+var Formats = []string{FormatVinyl, FormatCD, FormatCassette, FormatDigital}
+
+// Przedrostki domyślnych kodów SKU poszczególnych formatów
+var skuPrefixes = map[string]string{
+	FormatVinyl:    "LP",
+	FormatCD:       "CD",
+	FormatCassette: "MC",
+	FormatDigital:  "DIG",
+}
+
+// IsValidFormat sprawdza, czy format jest jednym z obsługiwanych nośników
+func IsValidFormat(format string) bool {
+	_, ok := skuPrefixes[format]
+	return ok
+}
+
+// Variant reprezentuje wersję albumu na konkretnym nośniku
+// swagger:model Variant
+type Variant struct {
+	// ID wersji (nadawane przez serwer)
+	ID primitive.ObjectID `bson:"_id" json:"id"`
+	// Format: vinyl, cd, cassette lub digital
+	Format string `bson:"format" json:"format"`
+	// Kod magazynowy (SKU), unikalny w całym sklepie; domyślnie generowany
+	SKU string `bson:"sku" json:"sku"`
+	// Kod kreskowy EAN-13
+	EAN string `bson:"ean,omitempty" json:"ean,omitempty"`
+	// Cena wersji
+	Price float64 `bson:"price" json:"price"`
+	// Liczba dostępnych sztuk
+	Stock int `bson:"stock" json:"stock"`
+	// Waga przesyłki w gramach (0 dla wersji cyfrowej)
+	Weight int `bson:"weight" json:"weight"`
+}
+
+// DefaultSKU zwraca kod SKU wersji złożony z przedrostka formatu i jej ID, np. CD-65F1…
+func DefaultSKU(format string, id primitive.ObjectID) string {
+	return skuPrefixes[format] + "-" + strings.ToUpper(id.Hex())
+}
+
+// DefaultVariant zwraca wersję CD z ceną i liczbą sztuk albumu. Używana dla
+// albumów przesłanych lub zapisanych bez listy wersji.
+func DefaultVariant(album Album) Variant {
+	id := primitive.NewObjectID()
+	return Variant{
+		ID:     id,
+		Format: FormatCD,
+		SKU:    DefaultSKU(FormatCD, id),
+		Price:  album.Price,
+		Stock:  album.Quantity,
+	}
+}
+
+// Variant zwraca wersję albumu o podanym ID
+func (a Album) Variant(id primitive.ObjectID) (Variant, bool) {
+	for _, variant := range a.Variants {
+		if variant.ID == id {
+			return variant, true
+		}
+	}
+	return Variant{}, false
+}
+
+// LowestPrice zwraca najniższą cenę spośród wersji albumu
+func (a Album) LowestPrice() float64 {
+	lowest := 0.0
+	for i, variant := range a.Variants {
+		if i == 0 || variant.Price < lowest {
+			lowest = variant.Price
+		}
+	}
+	return lowest
+}
+
+// TotalStock zwraca łączną liczbę sztuk wszystkich wersji albumu
+func (a Album) TotalStock() int {
+	total := 0
+	for _, variant := range a.Variants {
+		total += variant.Stock
+	}
+	return total
+}
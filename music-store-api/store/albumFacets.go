@@ -0,0 +1,190 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-store-api/models"
+)
+
+// PriceRange opisuje przedział cen [Min, Max). Max równe 0 oznacza brak górnej granicy.
+type PriceRange struct {
+	Min float64
+	Max float64
+}
+
+// Dolne granice przedziałów cenowych zwracanych w facecie price.
+// Ostatni przedział nie ma górnej granicy.
+var albumPriceBuckets = []float64{0, 20, 30, 50}
+
+// priceBucketRange zwraca przedział cenowy o podanym indeksie
+func priceBucketRange(i int) PriceRange {
+	r := PriceRange{Min: albumPriceBuckets[i]}
+	if i+1 < len(albumPriceBuckets) {
+		r.Max = albumPriceBuckets[i+1]
+	}
+	return r
+}
+
+// String zwraca przedział w postaci używanej w facetach i parametrze price_range, np. "20-30" lub "50+"
+func (r PriceRange) String() string {
+	if r.Max == 0 {
+		return strconv.FormatFloat(r.Min, 'f', -1, 64) + "+"
+	}
+	return strconv.FormatFloat(r.Min, 'f', -1, 64) + "-" + strconv.FormatFloat(r.Max, 'f', -1, 64)
+}
+
+func (r PriceRange) contains(price float64) bool {
+	return price >= r.Min && (r.Max == 0 || price < r.Max)
+}
+
+// ParsePriceRange odczytuje przedział cenowy w postaci "20-30" lub "50+"
+func ParsePriceRange(s string) (PriceRange, error) {
+	if min, ok := strings.CutSuffix(s, "+"); ok {
+		value, err := strconv.ParseFloat(min, 64)
+		if err != nil || value < 0 {
+			return PriceRange{}, fmt.Errorf("niepoprawny przedział cenowy %q", s)
+		}
+		return PriceRange{Min: value}, nil
+	}
+
+	min, max, ok := strings.Cut(s, "-")
+	if !ok {
+		return PriceRange{}, fmt.Errorf("niepoprawny przedział cenowy %q (oczekiwano np. 20-30 lub 50+)", s)
+	}
+	r := PriceRange{}
+	var err1, err2 error
+	r.Min, err1 = strconv.ParseFloat(min, 64)
+	r.Max, err2 = strconv.ParseFloat(max, 64)
+	if err1 != nil || err2 != nil || r.Min < 0 || r.Max <= r.Min {
+		return PriceRange{}, fmt.Errorf("niepoprawny przedział cenowy %q", s)
+	}
+	return r, nil
+}
+
+// ParseDecade odczytuje dekadę wydania w postaci "1980" lub "1980s"
+func ParseDecade(s string) (int, error) {
+	decade, err := strconv.Atoi(strings.TrimSuffix(s, "s"))
+	if err != nil || decade < 1000 || decade%10 != 0 {
+		return 0, fmt.Errorf("niepoprawna dekada %q (oczekiwano np. 1980)", s)
+	}
+	return decade, nil
+}
+
+// decadeRange zwraca początek i koniec (wyłącznie) dekady
+func decadeRange(decade int) (time.Time, time.Time) {
+	return time.Date(decade, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(decade+10, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Albumy bez daty wydania (wartość zerowa) nie trafiają do facetu decade
+var minFacetReleaseDate = time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// sortFacetCounts porządkuje wartości facetu malejąco według liczności, a przy
+// równej liczności alfabetycznie
+func sortFacetCounts(counts []models.FacetCount) {
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+}
+
+// Nazwy facetów albumów, odpowiadające parametrom filtra
+const (
+	genreFacet  = "genre"
+	artistFacet = "artist"
+	decadeFacet = "decade"
+	priceFacet  = "price"
+)
+
+// facetFilter zwraca filtr bez kryterium, którego dotyczy facet. Liczności
+// facetu pokazują wtedy, ile albumów dałoby wybranie innej wartości w tym
+// samym parametrze, a nie tylko wartości już zaznaczone.
+func facetFilter(filter AlbumFilter, facet string) AlbumFilter {
+	switch facet {
+	case genreFacet:
+		filter.Genres = nil
+	case artistFacet:
+		filter.Artists = nil
+	case decadeFacet:
+		filter.Decades = nil
+	case priceFacet:
+		filter.PriceRanges = nil
+	}
+	return filter
+}
+
+// computeAlbumFacets wylicza facety dla listy albumów w backendzie w pamięci.
+// Każdy facet liczony jest z filtrem pozbawionym jego własnego kryterium.
+func computeAlbumFacets(albums []models.Album, filter AlbumFilter) (models.AlbumFacets, error) {
+	matchers := map[string]func(models.Album) bool{}
+	for _, facet := range []string{genreFacet, artistFacet, decadeFacet, priceFacet} {
+		match, err := albumMatcher(facetFilter(filter, facet))
+		if err != nil {
+			return models.AlbumFacets{}, err
+		}
+		matchers[facet] = match
+	}
+
+	genres := map[string]int64{}
+	artists := map[string]int64{}
+	decades := map[int]int64{}
+	prices := make([]int64, len(albumPriceBuckets))
+
+	for _, album := range albums {
+		if album.Genre != "" && matchers[genreFacet](album) {
+			genres[album.Genre]++
+		}
+		if album.Artist != "" && matchers[artistFacet](album) {
+			artists[album.Artist]++
+		}
+		if !album.ReleaseDate.Before(minFacetReleaseDate) && matchers[decadeFacet](album) {
+			year := album.ReleaseDate.UTC().Year()
+			decades[year-year%10]++
+		}
+		if matchers[priceFacet](album) {
+			for i := len(albumPriceBuckets) - 1; i >= 0; i-- {
+				if album.Price >= albumPriceBuckets[i] {
+					prices[i]++
+					break
+				}
+			}
+		}
+	}
+
+	facets := models.AlbumFacets{
+		Genre:  mapFacetCounts(genres),
+		Artist: mapFacetCounts(artists),
+		Decade: []models.FacetCount{},
+		Price:  []models.FacetCount{},
+	}
+
+	decadeKeys := make([]int, 0, len(decades))
+	for decade := range decades {
+		decadeKeys = append(decadeKeys, decade)
+	}
+	sort.Ints(decadeKeys)
+	for _, decade := range decadeKeys {
+		facets.Decade = append(facets.Decade, models.FacetCount{Value: strconv.Itoa(decade), Count: decades[decade]})
+	}
+
+	for i, count := range prices {
+		if count > 0 {
+			facets.Price = append(facets.Price, models.FacetCount{Value: priceBucketRange(i).String(), Count: count})
+		}
+	}
+	return facets, nil
+}
+
+func mapFacetCounts(values map[string]int64) []models.FacetCount {
+	counts := make([]models.FacetCount, 0, len(values))
+	for value, count := range values {
+		counts = append(counts, models.FacetCount{Value: value, Count: count})
+	}
+	sortFacetCounts(counts)
+	return counts
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// albumSearchWeights określa wagi pól albumu w wyszukiwaniu pełnotekstowym.
+// Te same wagi trafiają do indeksu tekstowego MongoDB, w którym pole
+// indeksowane jest pod ścieżką path.
+var albumSearchWeights = []struct {
+	field  string
+	path   string
+	weight int
+}{
+	{"title", "title", 10},
+	{"artist", "artist", 8},
+	{"tracks", "tracks.title", 4},
+	{"description", "description", 2},
+}
+
+// Pole z tekstem albumu po usunięciu znaków diakrytycznych, dołączone do
+// indeksu tekstowego. MongoDB nie sprowadza np. "ł" do "l", więc bez niego
+// zapytanie "Lodz" nie znalazłoby "Łódź".
+const albumSearchTextField = "search_text"
+
+// Litery, których dekompozycja Unicode nie sprowadza do liter łacińskich
+var foldReplacer = strings.NewReplacer(
+	"ł", "l", "đ", "d", "ø", "o", "ß", "ss", "æ", "ae", "œ", "oe", "þ", "th",
+)
+
+// foldText sprowadza tekst do małych liter bez znaków diakrytycznych
+func foldText(s string) string {
+	s = foldReplacer.Replace(strings.ToLower(s))
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		return s
+	}
+	return folded
+}
+
+// searchTokens dzieli tekst na słowa po sprowadzeniu go funkcją foldText
+func searchTokens(s string) []string {
+	return strings.FieldsFunc(foldText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// albumSearchFieldText zwraca tekst pola albumu uwzględnianego w wyszukiwaniu
+func albumSearchFieldText(album models.Album, field string) string {
+	switch field {
+	case "title":
+		return album.Title
+	case "artist":
+		return album.Artist
+	case "tracks":
+		return strings.Join(album.Tracks.Titles(), " ")
+	case "description":
+		return album.Description
+	}
+	return ""
+}
+
+// albumSearchText buduje wartość pola search_text albumu
+func albumSearchText(album models.Album) string {
+	var tokens []string
+	for _, w := range albumSearchWeights {
+		tokens = append(tokens, searchTokens(albumSearchFieldText(album, w.field))...)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// touchesComputedFields sprawdza, czy aktualizacja zmienia pola, z których
+// wyliczane są search_text i total_duration (utwory są objęte wyszukiwaniem)
+// albo price i quantity (wersje albumu)
+func touchesComputedFields(set bson.M) bool {
+	for _, w := range albumSearchWeights {
+		if _, ok := set[w.field]; ok {
+			return true
+		}
+	}
+	return touchesVariants(set)
+}
+
+// albumComputedFields zwraca pola albumu wyliczane przez repozytorium. Cena
+// i liczba sztuk albumu bez wersji (zapisanego przed ich wprowadzeniem) nie
+// są zmieniane.
+func albumComputedFields(album models.Album) bson.M {
+	fields := bson.M{
+		albumSearchTextField: albumSearchText(album),
+		"total_duration":     album.Tracks.TotalDuration(),
+	}
+	if len(album.Variants) > 0 {
+		fields["price"] = album.LowestPrice()
+		fields["quantity"] = album.TotalStock()
+	}
+	return fields
+}
+
+// withComputedFields zwraca album z uzupełnionymi polami wyliczanymi
+func withComputedFields(album models.Album) models.Album {
+	album.SearchText = albumSearchText(album)
+	album.TotalDuration = album.Tracks.TotalDuration()
+	if len(album.Variants) > 0 {
+		album.Price = album.LowestPrice()
+		album.Quantity = album.TotalStock()
+	}
+	return album
+}
+
+// withComputedFieldsAll zwraca kopie albumów z uzupełnionymi polami wyliczanymi
+func withComputedFieldsAll(albums []models.Album) []models.Album {
+	prepared := make([]models.Album, len(albums))
+	for i, album := range albums {
+		prepared[i] = withComputedFields(album)
+	}
+	return prepared
+}
+
+// albumSearchScore wylicza trafność albumu dla słów zapytania w backendzie
+// w pamięci: suma wag pól, w których występują słowa zapytania, ważona
+// udziałem trafień w długości pola. Zero oznacza brak dopasowania.
+func albumSearchScore(album models.Album, queryTokens []string) float64 {
+	var score float64
+	for _, w := range albumSearchWeights {
+		fieldTokens := searchTokens(albumSearchFieldText(album, w.field))
+		if len(fieldTokens) == 0 {
+			continue
+		}
+		matches := 0
+		for _, token := range fieldTokens {
+			for _, q := range queryTokens {
+				if token == q {
+					matches++
+				}
+			}
+		}
+		if matches > 0 {
+			score += float64(w.weight) * (1 + float64(matches)/float64(len(fieldTokens)))
+		}
+	}
+	return score
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrDuplicate zwracany jest, gdy zapis naruszyłby unikalność (np. nazwy wykonawcy)
+var ErrDuplicate = errors.New("dokument o tym kluczu już istnieje")
+
+// ArtistFilter opisuje kryteria filtrowania wykonawców
+type ArtistFilter struct {
+	// Fragment nazwy lub aliasu, bez wielkości liter
+	Name string
+	// Dowolny z kodów kraju
+	Countries []string
+}
+
+// Pole z kluczami nazwy i aliasów wykonawcy (wyliczane przez repozytorium)
+const artistNameKeysField = "name_keys"
+
+// ArtistNameKey sprowadza nazwę wykonawcy do klucza porównania: małe litery
+// bez znaków diakrytycznych, wyłącznie litery i cyfry. Dzięki temu "AC/DC"
+// i "ACDC" oznaczają tego samego wykonawcę.
+func ArtistNameKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return r
+		}
+		return -1
+	}, foldText(name))
+}
+
+// artistNameKeys zwraca klucze nazwy i aliasów wykonawcy bez powtórzeń
+func artistNameKeys(artist models.Artist) []string {
+	var keys []string
+	for _, name := range append([]string{artist.Name}, artist.Aliases...) {
+		if key := ArtistNameKey(name); key != "" && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// touchesArtistNames sprawdza, czy aktualizacja zmienia nazwę lub aliasy
+func touchesArtistNames(set bson.M) bool {
+	_, name := set["name"]
+	_, aliases := set["aliases"]
+	return name || aliases
+}
+
+// artistNameKeysAfter wylicza klucze nazw wykonawcy po zastosowaniu zmian set
+func artistNameKeysAfter(current models.Artist, set bson.M) ([]string, error) {
+	raw, err := bson.Marshal(set)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(raw, &current); err != nil {
+		return nil, err
+	}
+	return artistNameKeys(current), nil
+}
+
+// withNameKeys zwraca kopie wykonawców z uzupełnionym polem name_keys
+func withNameKeys(artists []models.Artist) []models.Artist {
+	prepared := make([]models.Artist, len(artists))
+	for i, artist := range artists {
+		artist.NameKeys = artistNameKeys(artist)
+		prepared[i] = artist
+	}
+	return prepared
+}
+
+// ResolveArtist zwraca wykonawcę, którego nazwa lub alias odpowiada name,
+// a gdy taki nie istnieje – tworzy go z tą nazwą
+func ResolveArtist(ctx context.Context, artists ArtistStore, name string) (models.Artist, error) {
+	artist, err := artists.FindByName(ctx, name)
+	if !errors.Is(err, ErrNotFound) {
+		return artist, err
+	}
+
+	now := time.Now()
+	artist = models.Artist{
+		ID:        primitive.NewObjectID(),
+		Name:      strings.TrimSpace(name),
+		SortName:  models.DefaultSortName(name),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+	}
+	err = artists.Create(ctx, artist)
+	if errors.Is(err, ErrDuplicate) {
+		// Wykonawca został w międzyczasie utworzony przez inne żądanie
+		return artists.FindByName(ctx, name)
+	}
+	return artist, err
+}
+
+// MigrateAlbumArtists przypisuje wykonawców albumom bez pola artist_id na
+// podstawie ich pola artist, tworząc brakujących wykonawców. Nazwa wykonawcy
+// w albumie ujednolicana jest do nazwy wykonawcy. Zwraca liczbę zmienionych
+// albumów; może być wykonywana wielokrotnie.
+func MigrateAlbumArtists(ctx context.Context, artists ArtistStore, albums AlbumStore) (int, error) {
+	all, err := albums.List(ctx, AlbumFilter{}, ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, album := range all {
+		if !album.ArtistID.IsZero() || strings.TrimSpace(album.Artist) == "" {
+			continue
+		}
+		artist, err := ResolveArtist(ctx, artists, album.Artist)
+		if err != nil {
+			return migrated, fmt.Errorf("wykonawca albumu %s: %w", album.ID.Hex(), err)
+		}
+		err = albums.Update(ctx, album.ID, AnyVersion, bson.M{"artist_id": artist.ID, "artist": artist.Name})
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
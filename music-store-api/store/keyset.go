@@ -0,0 +1,110 @@
+package store
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cursor wskazuje pozycję w liście posortowanej według ListOptions.Sort
+// i _id. Lista zaczyna się bezpośrednio za tą pozycją (lub przed nią, gdy
+// Before jest ustawione), a ListOptions.Skip jest pomijane.
+type Cursor struct {
+	// Wartości pól sortowania w kolejności ListOptions.Sort
+	Values []interface{}
+	// _id dokumentu na pozycji kursora, rozstrzyga remisy wartości sortowania
+	ID primitive.ObjectID
+	// Pobiera dokumenty poprzedzające pozycję kursora zamiast następujących po niej
+	Before bool
+}
+
+// sortWithID dopisuje _id jako ostatnie pole sortowania, aby kolejność była
+// jednoznaczna. Przy przeglądaniu wstecz (reverse) kierunki są odwracane.
+func sortWithID(fields []SortField, reverse bool) []SortField {
+	sorted := make([]SortField, 0, len(fields)+1)
+	hasID := false
+	for _, field := range fields {
+		hasID = hasID || field.Field == "_id"
+		sorted = append(sorted, SortField{Field: field.Field, Desc: field.Desc != reverse})
+	}
+	if !hasID {
+		sorted = append(sorted, SortField{Field: "_id", Desc: reverse})
+	}
+	return sorted
+}
+
+// cursorDocument zapisuje pozycję kursora jako dokument z polami sortowania,
+// który można porównać z dokumentami kolekcji
+func cursorDocument(fields []SortField, cursor *Cursor) (bson.Raw, error) {
+	doc := bson.D{}
+	for i, field := range fields {
+		if i < len(cursor.Values) {
+			doc = append(doc, bson.E{Key: field.Field, Value: cursor.Values[i]})
+		}
+	}
+	doc = append(doc, bson.E{Key: "_id", Value: cursor.ID})
+	return bson.Marshal(doc)
+}
+
+// keysetFilter buduje warunek MongoDB wybierający dokumenty leżące za
+// pozycją kursora w kolejności opts.Sort i _id
+func keysetFilter(opts ListOptions) bson.M {
+	fields := sortWithID(opts.Sort, opts.Cursor.Before)
+	values := append(append([]interface{}{}, opts.Cursor.Values...), opts.Cursor.ID)
+
+	var alternatives bson.A
+	for i, field := range fields {
+		if i >= len(values) {
+			break
+		}
+		condition := bson.M{}
+		for j := 0; j < i; j++ {
+			condition[fields[j].Field] = values[j]
+		}
+		after, ok := keysetCondition(values[i], field.Desc)
+		if !ok {
+			continue
+		}
+		condition[field.Field] = after
+		alternatives = append(alternatives, condition)
+	}
+	if len(alternatives) == 0 {
+		return bson.M{"_id": bson.M{"$exists": false}}
+	}
+	return bson.M{"$or": alternatives}
+}
+
+// keysetCondition zwraca warunek na pole leżące za wartością value w danym
+// kierunku. Brakujące pole i null są w MongoDB mniejsze od każdej wartości,
+// ale $gt i $lt porównują tylko wartości tego samego typu, więc null wymaga
+// osobnych warunków. ok=false oznacza, że za value nie ma żadnej wartości.
+func keysetCondition(value interface{}, desc bool) (condition bson.M, ok bool) {
+	switch {
+	case isNullValue(value) && desc:
+		return nil, false
+	case isNullValue(value):
+		return bson.M{"$ne": nil}, true
+	case desc:
+		// Obejmuje także dokumenty bez pola
+		return bson.M{"$not": bson.M{"$gte": value}}, true
+	default:
+		return bson.M{"$gt": value}, true
+	}
+}
+
+func isNullValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bson.RawValue:
+		return v.Type == 0 || v.Type == bsontype.Null || v.Type == bsontype.Undefined
+	}
+	return false
+}
+
+// reverseDocs odwraca kolejność dokumentów pobranych wstecz od kursora
+func reverseDocs[T any](docs []T) {
+	for i, j := 0, len(docs)-1; i < j; i, j = i+1, j-1 {
+		docs[i], docs[j] = docs[j], docs[i]
+	}
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryArtistStore struct {
+	db *memoryDB
+}
+
+// artistMatcher odwzorowuje artistFilterToBSON dla backendu w pamięci
+func artistMatcher(filter ArtistFilter) func(models.Artist) bool {
+	name := strings.ToLower(filter.Name)
+	return func(artist models.Artist) bool {
+		if name != "" && !slices.ContainsFunc(append([]string{artist.Name}, artist.Aliases...), func(n string) bool {
+			return strings.Contains(strings.ToLower(n), name)
+		}) {
+			return false
+		}
+		if len(filter.Countries) > 0 && !slices.Contains(filter.Countries, artist.Country) {
+			return false
+		}
+		return true
+	}
+}
+
+// checkNameKeys odwzorowuje indeks unikalny name_keys: zwraca ErrDuplicate,
+// gdy inny wykonawca niż id ma którykolwiek z kluczy. Wywołujący musi
+// trzymać blokadę.
+func (s *memoryArtistStore) checkNameKeys(id primitive.ObjectID, keys []string) error {
+	others, err := memQuery(s.db.artists, func(artist models.Artist) bool {
+		return artist.ID != id && slices.ContainsFunc(artist.NameKeys, func(key string) bool {
+			return slices.Contains(keys, key)
+		})
+	}, ListOptions{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(others) > 0 {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+func (s *memoryArtistStore) List(ctx context.Context, filter ArtistFilter, opts ListOptions) ([]models.Artist, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memQuery(s.db.artists, artistMatcher(filter), opts)
+}
+
+func (s *memoryArtistStore) Count(ctx context.Context, filter ArtistFilter) (int64, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memCount(s.db.artists, artistMatcher(filter))
+}
+
+func (s *memoryArtistStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.Artist, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memGet[models.Artist](s.db.artists, id)
+}
+
+func (s *memoryArtistStore) FindByName(ctx context.Context, name string) (models.Artist, error) {
+	key := ArtistNameKey(name)
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	found, err := memQuery(s.db.artists, func(artist models.Artist) bool {
+		return key != "" && slices.Contains(artist.NameKeys, key)
+	}, ListOptions{Limit: 1})
+	if err != nil {
+		return models.Artist{}, err
+	}
+	if len(found) == 0 {
+		return models.Artist{}, ErrNotFound
+	}
+	return found[0], nil
+}
+
+func (s *memoryArtistStore) Create(ctx context.Context, artist models.Artist) error {
+	artist.NameKeys = artistNameKeys(artist)
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if err := s.checkNameKeys(artist.ID, artist.NameKeys); err != nil {
+		return err
+	}
+	return s.db.artists.insert(artist)
+}
+
+func (s *memoryArtistStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if touchesArtistNames(set) {
+		current, err := memGet[models.Artist](s.db.artists, id)
+		if err != nil {
+			return err
+		}
+		keys, err := artistNameKeysAfter(current, set)
+		if err != nil {
+			return err
+		}
+		if err := s.checkNameKeys(id, keys); err != nil {
+			return err
+		}
+		set = maps.Clone(set)
+		set[artistNameKeysField] = keys
+	}
+	return s.db.artists.update(id, version, set)
+}
+
+func (s *memoryArtistStore) Delete(ctx context.Context, id primitive.ObjectID, version int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	raw, ok := s.db.artists.get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	if version != AnyVersion && documentVersion(raw) != version {
+		return ErrConflict
+	}
+	s.db.artists.remove(id)
+	return nil
+}
+
+func (s *memoryArtistStore) ReplaceAll(ctx context.Context, artists []models.Artist) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.artists.replace(func() error {
+		for _, artist := range withNameKeys(artists) {
+			if err := s.checkNameKeys(artist.ID, artist.NameKeys); err != nil {
+				return err
+			}
+			if err := s.db.artists.insert(artist); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
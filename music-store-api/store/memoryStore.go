@@ -0,0 +1,739 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryDB przechowuje wszystkie kolekcje backendu w pamięci. Jedna blokada
+// chroni wszystkie tabele, więc operacje obejmujące kilka kolekcji są atomowe.
+type memoryDB struct {
+	mu      sync.RWMutex
+	albums  *memTable
+	users   *memTable
+	orders  *memTable
+	reviews *memTable
+	carts   *memTable
+	artists *memTable
+	tokens  *memTable
+	// Tokeny odświeżania i unieważnione tokeny dostępu
+	refreshTokens *memTable
+	revokedTokens *memTable
+	// Pliki okładek według nazwy coverFilename
+	covers map[string]CoverImage
+}
+
+// NewMemoryStores tworzy repozytoria przechowujące dane w pamięci procesu.
+// Przeznaczone do uruchamiania API i testów bez klastra MongoDB.
+func NewMemoryStores() Stores {
+	db := &memoryDB{
+		albums:  newSoftDeleteTable(),
+		users:   newSoftDeleteTable(),
+		orders:  newSoftDeleteTable(),
+		reviews: newSoftDeleteTable(),
+		carts:   newMemTable(),
+		artists: newMemTable(),
+		tokens:  newMemTable(),
+		covers:  map[string]CoverImage{},
+
+		refreshTokens: newMemTable(),
+		revokedTokens: newMemTable(),
+	}
+	return Stores{
+		Albums:  &memoryAlbumStore{db: db, memoryTrash: memoryTrash[models.Album]{db: db, table: db.albums}},
+		Users:   &memoryUserStore{db: db, memoryTrash: memoryTrash[models.User]{db: db, table: db.users}},
+		Orders:  &memoryOrderStore{db: db, memoryTrash: memoryTrash[models.Order]{db: db, table: db.orders}},
+		Reviews: &memoryReviewStore{db: db, memoryTrash: memoryTrash[models.Review]{db: db, table: db.reviews}},
+		Carts:   &memoryCartStore{db: db},
+		Covers:  &memoryCoverStore{db: db},
+		Artists: &memoryArtistStore{db: db},
+		Tokens:  &memoryTokenStore{db: db},
+
+		RefreshTokens: &memoryRefreshTokenStore{db: db},
+		RevokedTokens: &memoryRevokedTokenStore{db: db},
+	}
+}
+
+// adjustStock zmienia stan magazynowy wersji albumów o ilości z pozycji
+// pomnożone przez sign. Najpierw sprawdza wszystkie pozycje, więc przy braku
+// sztuk nic nie zostaje zmienione. Zwrot sztuk do usuniętego albumu lub
+// wersji jest pomijany; album w koszu przyjmuje zwrot, ale nie może być
+// zamówiony. Wywołujący musi trzymać blokadę zapisu.
+func (db *memoryDB) adjustStock(items []models.OrderItem, sign int) error {
+	albums := db.albums
+	if sign > 0 {
+		albums = albums.withTrash()
+	}
+	updated := map[primitive.ObjectID]models.Album{}
+	for _, item := range items {
+		album, seen := updated[item.AlbumID]
+		if !seen {
+			var err error
+			album, err = memGet[models.Album](albums, item.AlbumID)
+			if errors.Is(err, ErrNotFound) {
+				if sign < 0 {
+					return &InsufficientStockError{AlbumID: item.AlbumID, SKU: item.SKU}
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		i := variantIndex(album, item.VariantID)
+		if i < 0 {
+			if sign < 0 {
+				return &InsufficientStockError{AlbumID: item.AlbumID, SKU: item.SKU}
+			}
+			continue
+		}
+		album.Variants[i].Stock += sign * item.Quantity
+		if album.Variants[i].Stock < 0 {
+			return &InsufficientStockError{AlbumID: item.AlbumID, SKU: item.SKU}
+		}
+		updated[item.AlbumID] = album
+	}
+
+	for albumID, album := range updated {
+		err := albums.update(albumID, AnyVersion, bson.M{"variants": album.Variants, "quantity": album.TotalStock()})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memoryAlbumStore struct {
+	memoryTrash[models.Album]
+	db *memoryDB
+}
+
+// albumMatcher odwzorowuje albumFilterToBSON dla backendu w pamięci
+func albumMatcher(filter AlbumFilter) (func(models.Album) bool, error) {
+	artists, err := compileAll(filter.Artists, filter.ExactMatch)
+	if err != nil {
+		return nil, err
+	}
+	genres, err := compileAll(filter.Genres, filter.ExactMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(album models.Album) bool {
+		if len(filter.ArtistIDs) > 0 && !slices.Contains(filter.ArtistIDs, album.ArtistID) {
+			return false
+		}
+		if len(artists) > 0 && !anyMatch(artists, album.Artist) {
+			return false
+		}
+		if len(genres) > 0 && !anyMatch(genres, album.Genre) {
+			return false
+		}
+		if len(filter.Decades) > 0 {
+			inDecade := false
+			for _, decade := range filter.Decades {
+				from, to := decadeRange(decade)
+				if !album.ReleaseDate.Before(from) && album.ReleaseDate.Before(to) {
+					inDecade = true
+					break
+				}
+			}
+			if !inDecade {
+				return false
+			}
+		}
+		if len(filter.PriceRanges) > 0 {
+			inRange := false
+			for _, r := range filter.PriceRanges {
+				if r.contains(album.Price) {
+					inRange = true
+					break
+				}
+			}
+			if !inRange {
+				return false
+			}
+		}
+		if filter.MinPrice != nil && album.Price < *filter.MinPrice {
+			return false
+		}
+		if filter.MaxPrice != nil && album.Price > *filter.MaxPrice {
+			return false
+		}
+		if filter.ReleasedAfter != nil && album.ReleaseDate.Before(*filter.ReleasedAfter) {
+			return false
+		}
+		if filter.ReleasedBefore != nil && album.ReleaseDate.After(*filter.ReleasedBefore) {
+			return false
+		}
+		if len(filter.Formats) > 0 && !slices.ContainsFunc(album.Variants, func(variant models.Variant) bool {
+			return slices.Contains(filter.Formats, variant.Format) && (!filter.InStock || variant.Stock > 0)
+		}) {
+			return false
+		}
+		if filter.InStock && album.Quantity <= 0 {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// compileAll kompiluje wartości filtra tekstowego jako wyrażenia regularne bez wielkości liter
+func compileAll(values []string, exact bool) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(values))
+	for _, value := range values {
+		re, err := regexp.Compile("(?i)" + textPattern(value, exact))
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func anyMatch(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryAlbumStore) List(ctx context.Context, filter AlbumFilter, opts ListOptions) ([]models.Album, error) {
+	match, err := albumMatcher(filter)
+	if err != nil {
+		return nil, err
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memQuery(s.db.albums, match, opts)
+}
+
+func (s *memoryAlbumStore) Count(ctx context.Context, filter AlbumFilter) (int64, error) {
+	match, err := albumMatcher(filter)
+	if err != nil {
+		return 0, err
+	}
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memCount(s.db.albums, match)
+}
+
+func (s *memoryAlbumStore) Facets(ctx context.Context, filter AlbumFilter) (models.AlbumFacets, error) {
+	s.db.mu.RLock()
+	albums, err := memQuery[models.Album](s.db.albums, nil, ListOptions{})
+	s.db.mu.RUnlock()
+	if err != nil {
+		return models.AlbumFacets{}, err
+	}
+	return computeAlbumFacets(albums, filter)
+}
+
+func (s *memoryAlbumStore) Search(ctx context.Context, query string, opts ListOptions) ([]models.AlbumSearchResult, int64, error) {
+	queryTokens := searchTokens(query)
+
+	s.db.mu.RLock()
+	albums, err := memQuery[models.Album](s.db.albums, nil, ListOptions{})
+	s.db.mu.RUnlock()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := []models.AlbumSearchResult{}
+	for _, album := range albums {
+		if score := albumSearchScore(album, queryTokens); score > 0 {
+			results = append(results, models.AlbumSearchResult{Album: album, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	total := int64(len(results))
+	if opts.Skip >= total {
+		return []models.AlbumSearchResult{}, total, nil
+	}
+	results = results[opts.Skip:]
+	if opts.Limit > 0 && opts.Limit < int64(len(results)) {
+		results = results[:opts.Limit]
+	}
+	return results, total, nil
+}
+
+func (s *memoryAlbumStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.Album, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memGet[models.Album](s.db.albums, id)
+}
+
+// checkSKUs odwzorowuje indeks unikalny variants.sku: zwraca ErrDuplicate,
+// gdy inny album niż id, również w koszu, ma wersję o którymkolwiek z kodów.
+// Wywołujący musi trzymać blokadę.
+func (s *memoryAlbumStore) checkSKUs(id primitive.ObjectID, skus []string) error {
+	others, err := memQuery(s.db.albums.withTrash(), func(album models.Album) bool {
+		return album.ID != id && slices.ContainsFunc(album.Variants, func(variant models.Variant) bool {
+			return slices.Contains(skus, variant.SKU)
+		})
+	}, ListOptions{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(others) > 0 {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+// checkSKUsAll sprawdza unikalność kodów SKU dodawanych albumów względem
+// zapisanych albumów i siebie nawzajem. Wywołujący musi trzymać blokadę.
+func (s *memoryAlbumStore) checkSKUsAll(albums []models.Album) error {
+	seen := map[string]bool{}
+	for _, album := range albums {
+		skus := albumSKUs(album)
+		for _, sku := range skus {
+			if seen[sku] {
+				return ErrDuplicate
+			}
+			seen[sku] = true
+		}
+		if err := s.checkSKUs(album.ID, skus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryAlbumStore) Create(ctx context.Context, album models.Album) error {
+	album = withComputedFields(album)
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if err := s.checkSKUs(album.ID, albumSKUs(album)); err != nil {
+		return err
+	}
+	return s.db.albums.insert(album)
+}
+
+func (s *memoryAlbumStore) CreateMany(ctx context.Context, albums []models.Album) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if err := s.checkSKUsAll(albums); err != nil {
+		return err
+	}
+	return memInsertMany(s.db.albums, withComputedFieldsAll(albums))
+}
+
+func (s *memoryAlbumStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if touchesVariants(set) {
+		current, err := memGet[models.Album](s.db.albums, id)
+		if err != nil {
+			return err
+		}
+		updated, err := albumAfter(current, set)
+		if err != nil {
+			return err
+		}
+		if err := s.checkSKUs(id, albumSKUs(updated)); err != nil {
+			return err
+		}
+	}
+	if err := s.db.albums.update(id, version, set); err != nil {
+		return err
+	}
+	if !touchesComputedFields(set) {
+		return nil
+	}
+	album, err := memGet[models.Album](s.db.albums, id)
+	if err != nil {
+		return err
+	}
+	return s.db.albums.set(id, albumComputedFields(album))
+}
+
+func (s *memoryAlbumStore) ReplaceAll(ctx context.Context, albums []models.Album) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.albums.replace(func() error {
+		if err := s.checkSKUsAll(albums); err != nil {
+			return err
+		}
+		return memInsertMany(s.db.albums, withComputedFieldsAll(albums))
+	})
+}
+
+type memoryUserStore struct {
+	memoryTrash[models.User]
+	db *memoryDB
+}
+
+// userMatcher odwzorowuje userFilterToBSON dla backendu w pamięci
+func userMatcher(filter UserFilter) func(models.User) bool {
+	return func(user models.User) bool {
+		if len(filter.Roles) > 0 && !slices.Contains(filter.Roles, user.Role) {
+			return false
+		}
+		if filter.IsActive != nil && user.IsActive != *filter.IsActive {
+			return false
+		}
+		return true
+	}
+}
+
+func (s *memoryUserStore) List(ctx context.Context, filter UserFilter, opts ListOptions) ([]models.User, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memQuery(s.db.users, userMatcher(filter), opts)
+}
+
+func (s *memoryUserStore) Count(ctx context.Context, filter UserFilter) (int64, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memCount(s.db.users, userMatcher(filter))
+}
+
+func (s *memoryUserStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.User, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memGet[models.User](s.db.users, id)
+}
+
+func (s *memoryUserStore) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	key := EmailKey(email)
+	users, err := memQuery(s.db.users, func(user models.User) bool {
+		return user.EmailKey == key
+	}, ListOptions{Limit: 1})
+	if err != nil {
+		return models.User{}, err
+	}
+	if len(users) == 0 {
+		return models.User{}, ErrNotFound
+	}
+	return users[0], nil
+}
+
+// checkEmail odwzorowuje indeks unikalny email_key: zwraca ErrDuplicate, gdy
+// inny użytkownik niż id, również w koszu, ma ten sam klucz adresu e-mail.
+// Wywołujący musi trzymać blokadę.
+func (s *memoryUserStore) checkEmail(id primitive.ObjectID, key string) error {
+	others, err := memQuery(s.db.users.withTrash(), func(user models.User) bool {
+		return user.ID != id && user.EmailKey == key
+	}, ListOptions{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(others) > 0 {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+func (s *memoryUserStore) Create(ctx context.Context, user models.User) error {
+	user.EmailKey = EmailKey(user.Email)
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if err := s.checkEmail(user.ID, user.EmailKey); err != nil {
+		return err
+	}
+	return s.db.users.insert(user)
+}
+
+func (s *memoryUserStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	set = withEmailKeyUpdate(set)
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if key, ok := set[userEmailKeyField].(string); ok {
+		if err := s.checkEmail(id, key); err != nil {
+			return err
+		}
+	}
+	return s.db.users.update(id, version, set)
+}
+
+func (s *memoryUserStore) Remove(ctx context.Context, id primitive.ObjectID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if !s.db.users.remove(id) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *memoryUserStore) ReplaceAll(ctx context.Context, users []models.User) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.users.replace(func() error {
+		for _, user := range withEmailKeys(users) {
+			if err := s.checkEmail(user.ID, user.EmailKey); err != nil {
+				return err
+			}
+			if err := s.db.users.insert(user); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type memoryOrderStore struct {
+	memoryTrash[models.Order]
+	db *memoryDB
+}
+
+// orderMatcher odwzorowuje orderFilterToBSON dla backendu w pamięci
+func orderMatcher(filter OrderFilter) func(models.Order) bool {
+	return func(order models.Order) bool {
+		if !filter.UserID.IsZero() && order.UserID != filter.UserID {
+			return false
+		}
+		if !filter.AlbumID.IsZero() && !slices.ContainsFunc(order.Items, func(item models.OrderItem) bool {
+			return item.AlbumID == filter.AlbumID
+		}) {
+			return false
+		}
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, order.Status) {
+			return false
+		}
+		if filter.CreatedAfter != nil && order.CreatedAt.Before(*filter.CreatedAfter) {
+			return false
+		}
+		if filter.CreatedBefore != nil && order.CreatedAt.After(*filter.CreatedBefore) {
+			return false
+		}
+		return true
+	}
+}
+
+func (s *memoryOrderStore) List(ctx context.Context, filter OrderFilter, opts ListOptions) ([]models.Order, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memQuery(s.db.orders, orderMatcher(filter), opts)
+}
+
+func (s *memoryOrderStore) Count(ctx context.Context, filter OrderFilter) (int64, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memCount(s.db.orders, orderMatcher(filter))
+}
+
+func (s *memoryOrderStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.Order, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memGet[models.Order](s.db.orders, id)
+}
+
+func (s *memoryOrderStore) Place(ctx context.Context, order models.Order) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if err := s.db.adjustStock(order.Items, -1); err != nil {
+		return err
+	}
+	return s.db.orders.insert(order)
+}
+
+func (s *memoryOrderStore) TransitionStatus(ctx context.Context, id primitive.ObjectID, version int64, change models.StatusHistory) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	order, err := memGet[models.Order](s.db.orders, id)
+	if err != nil {
+		return err
+	}
+	if order.Status != change.From || (version != AnyVersion && order.Version != version) {
+		return ErrConflict
+	}
+
+	if sign := stockDirection(change.From, change.To); sign != 0 {
+		if err := s.db.adjustStock(order.Items, sign); err != nil {
+			return err
+		}
+	}
+	return s.db.orders.update(id, version, bson.M{
+		"status":     change.To,
+		"updated_at": change.ChangedAt,
+		"history":    append(order.History, change),
+	})
+}
+
+func (s *memoryOrderStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.orders.update(id, version, set)
+}
+
+func (s *memoryOrderStore) AnonymizeShipping(ctx context.Context, userID primitive.ObjectID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	orders := s.db.orders.withTrash()
+	owned, err := memQuery(orders, func(order models.Order) bool { return order.UserID == userID }, ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, order := range owned {
+		if err := orders.update(order.ID, AnyVersion, bson.M{"shipping": order.Shipping.Anonymized()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryOrderStore) ReplaceAll(ctx context.Context, orders []models.Order) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.orders.replace(func() error {
+		return memInsertMany(s.db.orders, orders)
+	})
+}
+
+type memoryReviewStore struct {
+	memoryTrash[models.Review]
+	db *memoryDB
+}
+
+// reviewMatcher odwzorowuje reviewFilterToBSON dla backendu w pamięci
+func reviewMatcher(filter ReviewFilter) func(models.Review) bool {
+	return func(review models.Review) bool {
+		if !filter.AlbumID.IsZero() && review.AlbumID != filter.AlbumID {
+			return false
+		}
+		if !filter.UserID.IsZero() && review.UserID != filter.UserID {
+			return false
+		}
+		if filter.MinRating != nil && review.Rating < *filter.MinRating {
+			return false
+		}
+		if filter.MaxRating != nil && review.Rating > *filter.MaxRating {
+			return false
+		}
+		return true
+	}
+}
+
+func (s *memoryReviewStore) List(ctx context.Context, filter ReviewFilter, opts ListOptions) ([]models.Review, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memQuery(s.db.reviews, reviewMatcher(filter), opts)
+}
+
+func (s *memoryReviewStore) Count(ctx context.Context, filter ReviewFilter) (int64, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memCount(s.db.reviews, reviewMatcher(filter))
+}
+
+func (s *memoryReviewStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.Review, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memGet[models.Review](s.db.reviews, id)
+}
+
+func (s *memoryReviewStore) Create(ctx context.Context, review models.Review) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.reviews.insert(review)
+}
+
+func (s *memoryReviewStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.reviews.update(id, version, set)
+}
+
+func (s *memoryReviewStore) DetachUser(ctx context.Context, userID primitive.ObjectID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	reviews := s.db.reviews.withTrash()
+	owned, err := memQuery(reviews, func(review models.Review) bool { return review.UserID == userID }, ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, review := range owned {
+		if err := reviews.update(review.ID, AnyVersion, bson.M{"user_id": primitive.NilObjectID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memoryReviewStore) ReplaceAll(ctx context.Context, reviews []models.Review) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.reviews.replace(func() error {
+		return memInsertMany(s.db.reviews, reviews)
+	})
+}
+
+type memoryCartStore struct {
+	db *memoryDB
+}
+
+func (s *memoryCartStore) Get(ctx context.Context, userID primitive.ObjectID) (models.Cart, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memGet[models.Cart](s.db.carts, userID)
+}
+
+func (s *memoryCartStore) Save(ctx context.Context, cart models.Cart) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.carts.remove(cart.UserID)
+	return s.db.carts.insert(cart)
+}
+
+func (s *memoryCartStore) Delete(ctx context.Context, userID primitive.ObjectID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if !s.db.carts.remove(userID) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryCoverStore struct {
+	db *memoryDB
+}
+
+func (s *memoryCoverStore) Save(ctx context.Context, albumID primitive.ObjectID, size string, image CoverImage) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	image.Data = slices.Clone(image.Data)
+	s.db.covers[coverFilename(albumID, size)] = image
+	return nil
+}
+
+func (s *memoryCoverStore) Get(ctx context.Context, albumID primitive.ObjectID, size string) (CoverImage, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	image, ok := s.db.covers[coverFilename(albumID, size)]
+	if !ok {
+		return CoverImage{}, ErrNotFound
+	}
+	image.Data = slices.Clone(image.Data)
+	return image, nil
+}
+
+func (s *memoryCoverStore) Delete(ctx context.Context, albumID primitive.ObjectID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	prefix := coverFilename(albumID, "")
+	for name := range s.db.covers {
+		if strings.HasPrefix(name, prefix) {
+			delete(s.db.covers, name)
+		}
+	}
+	return nil
+}
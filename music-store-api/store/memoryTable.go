@@ -0,0 +1,403 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errDuplicateID = errors.New("dokument o podanym _id już istnieje")
+
+// memTable przechowuje dokumenty jednej kolekcji w postaci BSON, dzięki czemu
+// backend w pamięci zachowuje się jak MongoDB (kopie przy odczycie, precyzja dat itd.)
+type memTable struct {
+	docs  map[primitive.ObjectID]bson.Raw
+	order []primitive.ObjectID
+	// Dokumenty z polem deleted_at (w koszu) są pomijane przez get, all i update
+	softDelete bool
+}
+
+func newMemTable() *memTable {
+	return &memTable{docs: map[primitive.ObjectID]bson.Raw{}}
+}
+
+// newSoftDeleteTable tworzy tabelę kolekcji z koszem
+func newSoftDeleteTable() *memTable {
+	return &memTable{docs: map[primitive.ObjectID]bson.Raw{}, softDelete: true}
+}
+
+// hidden sprawdza, czy dokument jest w koszu tabeli z miękkim usuwaniem
+func (t *memTable) hidden(raw bson.Raw) bool {
+	return t.softDelete && isTrashed(raw)
+}
+
+// isTrashed sprawdza, czy dokument ma ustawione pole deleted_at
+func isTrashed(raw bson.Raw) bool {
+	value, err := raw.LookupErr(deletedAtField)
+	return err == nil && value.Type != bsontype.Null
+}
+
+// withTrash zwraca widok tabeli obejmujący również dokumenty w koszu.
+// Widok współdzieli dokumenty z tabelą; nie należy przez niego wstawiać ani usuwać.
+func (t *memTable) withTrash() *memTable {
+	return &memTable{docs: t.docs, order: t.order}
+}
+
+// trash zwraca tabelę z dokumentami w koszu do odczytu; zmiany w niej nie
+// wpływają na tabelę t
+func (t *memTable) trash() *memTable {
+	view := newMemTable()
+	for _, id := range t.order {
+		if raw := t.docs[id]; isTrashed(raw) {
+			view.docs[id] = raw
+			view.order = append(view.order, id)
+		}
+	}
+	return view
+}
+
+func (t *memTable) insert(doc interface{}) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	id, ok := bson.Raw(raw).Lookup("_id").ObjectIDOK()
+	if !ok {
+		var fields bson.D
+		if err := bson.Unmarshal(raw, &fields); err != nil {
+			return err
+		}
+		id = primitive.NewObjectID()
+		if raw, err = bson.Marshal(append(bson.D{{Key: "_id", Value: id}}, fields...)); err != nil {
+			return err
+		}
+	}
+	if _, exists := t.docs[id]; exists {
+		return errDuplicateID
+	}
+	t.docs[id] = raw
+	t.order = append(t.order, id)
+	return nil
+}
+
+func (t *memTable) get(id primitive.ObjectID) (bson.Raw, bool) {
+	raw, ok := t.docs[id]
+	if !ok || t.hidden(raw) {
+		return nil, false
+	}
+	return raw, true
+}
+
+// all zwraca dokumenty w kolejności wstawiania
+func (t *memTable) all() []bson.Raw {
+	raws := make([]bson.Raw, 0, len(t.order))
+	for _, id := range t.order {
+		if raw := t.docs[id]; !t.hidden(raw) {
+			raws = append(raws, raw)
+		}
+	}
+	return raws
+}
+
+// set działa jak operator $set na polach najwyższego poziomu;
+// pola z wartością nil są usuwane jak przez $unset
+func (t *memTable) set(id primitive.ObjectID, fields bson.M) error {
+	raw, ok := t.docs[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if fields[key] == nil {
+			doc = slices.DeleteFunc(doc, func(e bson.E) bool { return e.Key == key })
+			continue
+		}
+		replaced := false
+		for i := range doc {
+			if doc[i].Key == key {
+				doc[i].Value = fields[key]
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			doc = append(doc, bson.E{Key: key, Value: fields[key]})
+		}
+	}
+
+	updated, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	t.docs[id] = updated
+	return nil
+}
+
+// update działa jak set i zwiększa wersję dokumentu. Gdy version jest różne
+// od AnyVersion, a bieżąca wersja dokumentu jest inna, zwraca ErrConflict.
+func (t *memTable) update(id primitive.ObjectID, version int64, fields bson.M) error {
+	raw, ok := t.get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	current := documentVersion(raw)
+	if version != AnyVersion && current != version {
+		return ErrConflict
+	}
+
+	withVersion := bson.M{"version": current + 1}
+	for key, value := range fields {
+		withVersion[key] = value
+	}
+	return t.set(id, withVersion)
+}
+
+// documentVersion zwraca wersję dokumentu; dokumenty bez pola version mają wersję 0
+func documentVersion(raw bson.Raw) int64 {
+	value, err := raw.LookupErr("version")
+	if err != nil || !isNumber(value) {
+		return 0
+	}
+	return int64(numberValue(value))
+}
+
+func (t *memTable) remove(id primitive.ObjectID) bool {
+	if _, ok := t.docs[id]; !ok {
+		return false
+	}
+	delete(t.docs, id)
+	for i, existing := range t.order {
+		if existing == id {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// replace czyści tabelę i wypełnia ją przez fill. Gdy fill zwróci błąd,
+// przywracana jest poprzednia zawartość, więc nieudany import nie zostawia
+// pustej ani częściowo wypełnionej tabeli. Wywołujący musi trzymać blokadę.
+func (t *memTable) replace(fill func() error) error {
+	docs, order := t.docs, t.order
+	t.docs = map[primitive.ObjectID]bson.Raw{}
+	t.order = nil
+	if err := fill(); err != nil {
+		t.docs, t.order = docs, order
+		return err
+	}
+	return nil
+}
+
+// memQuery dekoduje dokumenty tabeli, filtruje je, sortuje i stronicuje
+func memQuery[T any](t *memTable, match func(T) bool, opts ListOptions) ([]T, error) {
+	type entry struct {
+		raw bson.Raw
+		doc T
+	}
+
+	var matched []entry
+	for _, raw := range t.all() {
+		var doc T
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		if match == nil || match(doc) {
+			matched = append(matched, entry{raw: raw, doc: doc})
+		}
+	}
+
+	if len(opts.Sort) > 0 || opts.Cursor != nil {
+		fields := sortWithID(opts.Sort, false)
+		sort.SliceStable(matched, func(i, j int) bool {
+			return compareDocs(matched[i].raw, matched[j].raw, fields) < 0
+		})
+	}
+
+	if opts.Cursor != nil {
+		fields := sortWithID(opts.Sort, false)
+		position, err := cursorDocument(opts.Sort, opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		var page []entry
+		for _, e := range matched {
+			c := compareDocs(e.raw, position, fields)
+			if (opts.Cursor.Before && c < 0) || (!opts.Cursor.Before && c > 0) {
+				page = append(page, e)
+			}
+		}
+		// Wstecz zwracane są dokumenty bezpośrednio poprzedzające kursor
+		if opts.Cursor.Before && opts.Limit > 0 && opts.Limit < int64(len(page)) {
+			page = page[int64(len(page))-opts.Limit:]
+		}
+		matched = page
+	} else if opts.Skip > 0 {
+		if opts.Skip >= int64(len(matched)) {
+			matched = nil
+		} else {
+			matched = matched[opts.Skip:]
+		}
+	}
+	if opts.Limit > 0 && opts.Limit < int64(len(matched)) {
+		matched = matched[:opts.Limit]
+	}
+
+	docs := make([]T, 0, len(matched))
+	for _, e := range matched {
+		docs = append(docs, e.doc)
+	}
+	return docs, nil
+}
+
+func memCount[T any](t *memTable, match func(T) bool) (int64, error) {
+	docs, err := memQuery(t, match, ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(docs)), nil
+}
+
+func memGet[T any](t *memTable, id primitive.ObjectID) (T, error) {
+	var doc T
+	raw, ok := t.get(id)
+	if !ok {
+		return doc, ErrNotFound
+	}
+	err := bson.Unmarshal(raw, &doc)
+	return doc, err
+}
+
+func memInsertMany[T any](t *memTable, docs []T) error {
+	for _, doc := range docs {
+		if err := t.insert(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compareDocs(a, b bson.Raw, fields []SortField) int {
+	for _, field := range fields {
+		path := strings.Split(field.Field, ".")
+		va, _ := a.LookupErr(path...)
+		vb, _ := b.LookupErr(path...)
+		c := compareValues(va, vb)
+		if field.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// typeRank odwzorowuje kolejność typów BSON stosowaną przez MongoDB przy sortowaniu
+func typeRank(t bsontype.Type) int {
+	switch t {
+	case 0, bsontype.Null, bsontype.Undefined:
+		return 1
+	case bsontype.Double, bsontype.Int32, bsontype.Int64, bsontype.Decimal128:
+		return 2
+	case bsontype.String, bsontype.Symbol:
+		return 3
+	case bsontype.EmbeddedDocument:
+		return 4
+	case bsontype.Array:
+		return 5
+	case bsontype.Binary:
+		return 6
+	case bsontype.ObjectID:
+		return 7
+	case bsontype.Boolean:
+		return 8
+	case bsontype.DateTime:
+		return 9
+	case bsontype.Timestamp:
+		return 10
+	default:
+		return 11
+	}
+}
+
+func compareValues(a, b bson.RawValue) int {
+	ra, rb := typeRank(a.Type), typeRank(b.Type)
+	if ra != rb {
+		return compareInts(int64(ra), int64(rb))
+	}
+
+	switch ra {
+	case 1:
+		return 0
+	case 2:
+		fa, fb := numberValue(a), numberValue(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case 3:
+		return strings.Compare(a.StringValue(), b.StringValue())
+	case 7:
+		oa, ob := a.ObjectID(), b.ObjectID()
+		return bytes.Compare(oa[:], ob[:])
+	case 8:
+		ba, bb := a.Boolean(), b.Boolean()
+		switch {
+		case ba == bb:
+			return 0
+		case !ba:
+			return -1
+		}
+		return 1
+	case 9:
+		return compareInts(a.DateTime(), b.DateTime())
+	}
+	return bytes.Compare(a.Value, b.Value)
+}
+
+func isNumber(v bson.RawValue) bool {
+	return typeRank(v.Type) == typeRank(bsontype.Double)
+}
+
+func numberValue(v bson.RawValue) float64 {
+	switch v.Type {
+	case bsontype.Double:
+		return v.Double()
+	case bsontype.Int32:
+		return float64(v.Int32())
+	case bsontype.Int64:
+		return float64(v.Int64())
+	}
+	return 0
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTokenStore struct {
+	db *memoryDB
+}
+
+func (s *memoryTokenStore) Create(ctx context.Context, token models.UserToken) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.tokens.insert(token)
+}
+
+func (s *memoryTokenStore) Consume(ctx context.Context, purpose, hash string) (models.UserToken, error) {
+	now := time.Now()
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	found, err := memQuery(s.db.tokens, func(token models.UserToken) bool {
+		return token.Purpose == purpose && token.Hash == hash && token.ExpiresAt.After(now)
+	}, ListOptions{Limit: 1})
+	if err != nil {
+		return models.UserToken{}, err
+	}
+	if len(found) == 0 {
+		return models.UserToken{}, ErrNotFound
+	}
+	s.db.tokens.remove(found[0].ID)
+	return found[0], nil
+}
+
+func (s *memoryTokenStore) DeleteByUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	// Wygasłe tokeny innych użytkowników usuwane są przy okazji, jak przez indeks TTL w MongoDB
+	now := time.Now()
+	stale, err := memQuery(s.db.tokens, func(token models.UserToken) bool {
+		return (token.UserID == userID && token.Purpose == purpose) || !token.ExpiresAt.After(now)
+	}, ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, token := range stale {
+		s.db.tokens.remove(token.ID)
+	}
+	return nil
+}
+
+type memoryRefreshTokenStore struct {
+	db *memoryDB
+}
+
+func (s *memoryRefreshTokenStore) Create(ctx context.Context, token models.RefreshToken) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.db.refreshTokens.insert(token)
+}
+
+func (s *memoryRefreshTokenStore) GetByHash(ctx context.Context, hash string) (models.RefreshToken, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	found, err := memQuery(s.db.refreshTokens, func(token models.RefreshToken) bool {
+		return token.Hash == hash
+	}, ListOptions{Limit: 1})
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	if len(found) == 0 {
+		return models.RefreshToken{}, ErrNotFound
+	}
+	return found[0], nil
+}
+
+func (s *memoryRefreshTokenStore) MarkUsed(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	token, err := memGet[models.RefreshToken](s.db.refreshTokens, id)
+	if err != nil {
+		return err
+	}
+	if token.UsedAt != nil || token.RevokedAt != nil {
+		return ErrConflict
+	}
+	return s.db.refreshTokens.set(id, bson.M{"used_at": at})
+}
+
+func (s *memoryRefreshTokenStore) RevokeFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	return s.revoke(func(token models.RefreshToken) bool { return token.FamilyID == familyID })
+}
+
+func (s *memoryRefreshTokenStore) RevokeUser(ctx context.Context, userID primitive.ObjectID) error {
+	return s.revoke(func(token models.RefreshToken) bool { return token.UserID == userID })
+}
+
+// revoke unieważnia tokeny spełniające match, pomijając już unieważnione
+func (s *memoryRefreshTokenStore) revoke(match func(models.RefreshToken) bool) error {
+	now := time.Now()
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	tokens, err := memQuery(s.db.refreshTokens, func(token models.RefreshToken) bool {
+		return token.RevokedAt == nil && match(token)
+	}, ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := s.db.refreshTokens.set(token.ID, bson.M{"revoked_at": now}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type memoryRevokedTokenStore struct {
+	db *memoryDB
+}
+
+func (s *memoryRevokedTokenStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	// Wpisy wygasłych tokenów usuwane są przy okazji, jak przez indeks TTL w MongoDB
+	now := time.Now()
+	stale, err := memQuery(s.db.revokedTokens, func(token models.RevokedToken) bool {
+		return token.JTI == jti || !token.ExpiresAt.After(now)
+	}, ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, token := range stale {
+		s.db.revokedTokens.remove(token.ID)
+	}
+	return s.db.revokedTokens.insert(models.RevokedToken{ID: primitive.NewObjectID(), JTI: jti, ExpiresAt: expiresAt})
+}
+
+func (s *memoryRevokedTokenStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	count, err := memCount(s.db.revokedTokens, func(token models.RevokedToken) bool {
+		return token.JTI == jti
+	})
+	return count > 0, err
+}
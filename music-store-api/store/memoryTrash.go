@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryTrash implementuje miękkie usuwanie i kosz dla tabeli backendu
+// w pamięci; osadzany w repozytoriach kolekcji z koszem
+type memoryTrash[T any] struct {
+	db    *memoryDB
+	table *memTable
+}
+
+func (s memoryTrash[T]) Delete(ctx context.Context, id primitive.ObjectID, version int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.table.update(id, version, bson.M{deletedAtField: time.Now()})
+}
+
+func (s memoryTrash[T]) ListTrash(ctx context.Context, opts ListOptions) ([]T, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memQuery[T](s.table.trash(), nil, opts)
+}
+
+func (s memoryTrash[T]) CountTrash(ctx context.Context) (int64, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+	return memCount[T](s.table.trash(), nil)
+}
+
+func (s memoryTrash[T]) Restore(ctx context.Context, id primitive.ObjectID) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if raw, ok := s.table.docs[id]; !ok || !isTrashed(raw) {
+		return ErrNotFound
+	}
+	return s.table.withTrash().update(id, AnyVersion, bson.M{deletedAtField: nil})
+}
+
+func (s memoryTrash[T]) Purge(ctx context.Context, before time.Time) ([]primitive.ObjectID, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	purged := []primitive.ObjectID{}
+	trash := s.table.trash()
+	for _, id := range trash.order {
+		if trash.docs[id].Lookup(deletedAtField).Time().Before(before) {
+			s.table.remove(id)
+			purged = append(purged, id)
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"maps"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoArtistStore struct {
+	coll *mongo.Collection
+}
+
+func artistFilterToBSON(filter ArtistFilter) bson.M {
+	query := bson.M{}
+	if filter.Name != "" {
+		pattern := bson.M{"$regex": textPattern(filter.Name, false), "$options": "i"}
+		query["$or"] = bson.A{bson.M{"name": pattern}, bson.M{"aliases": pattern}}
+	}
+	if len(filter.Countries) > 0 {
+		query["country"] = bson.M{"$in": filter.Countries}
+	}
+	return query
+}
+
+// duplicateAsErr zamienia błąd naruszenia indeksu unikalnego na ErrDuplicate
+func duplicateAsErr(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (s *mongoArtistStore) List(ctx context.Context, filter ArtistFilter, opts ListOptions) ([]models.Artist, error) {
+	return findPage[models.Artist](ctx, s.coll, artistFilterToBSON(filter), opts)
+}
+
+func (s *mongoArtistStore) Count(ctx context.Context, filter ArtistFilter) (int64, error) {
+	return s.coll.CountDocuments(ctx, artistFilterToBSON(filter))
+}
+
+func (s *mongoArtistStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.Artist, error) {
+	return findOne[models.Artist](ctx, s.coll, bson.M{"_id": id})
+}
+
+func (s *mongoArtistStore) FindByName(ctx context.Context, name string) (models.Artist, error) {
+	key := ArtistNameKey(name)
+	if key == "" {
+		return models.Artist{}, ErrNotFound
+	}
+	return findOne[models.Artist](ctx, s.coll, bson.M{artistNameKeysField: key})
+}
+
+func (s *mongoArtistStore) Create(ctx context.Context, artist models.Artist) error {
+	artist.NameKeys = artistNameKeys(artist)
+	_, err := s.coll.InsertOne(ctx, artist)
+	return duplicateAsErr(err)
+}
+
+func (s *mongoArtistStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	if touchesArtistNames(set) {
+		current, err := findOne[models.Artist](ctx, s.coll, bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		keys, err := artistNameKeysAfter(current, set)
+		if err != nil {
+			return err
+		}
+		// Klucze zapisywane są w tej samej operacji, a indeks unikalny
+		// odrzuca nazwy zajęte przez innego wykonawcę
+		set = maps.Clone(set)
+		set[artistNameKeysField] = keys
+	}
+	return duplicateAsErr(updateByID(ctx, s.coll, id, version, set))
+}
+
+func (s *mongoArtistStore) Delete(ctx context.Context, id primitive.ObjectID, version int64) error {
+	return deleteByID(ctx, s.coll, id, version)
+}
+
+func (s *mongoArtistStore) ReplaceAll(ctx context.Context, artists []models.Artist) error {
+	return duplicateAsErr(replaceAll(ctx, s.coll, withNameKeys(artists)))
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nazwa kubełka GridFS z okładkami (kolekcje covers.files i covers.chunks)
+const coverBucketName = "covers"
+
+// coverFile odwzorowuje dokument kolekcji covers.files
+type coverFile struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	UploadDate time.Time          `bson:"uploadDate"`
+	Metadata   struct {
+		ContentType string `bson:"content_type"`
+	} `bson:"metadata"`
+}
+
+type mongoCoverStore struct {
+	db *mongo.Database
+}
+
+// bucket otwiera kubełek GridFS z limitami czasu wynikającymi z ctx. Kubełek
+// tworzony jest dla każdej operacji, ponieważ limity czasu są jego stanem.
+func (s *mongoCoverStore) bucket(ctx context.Context) (*gridfs.Bucket, error) {
+	bucket, err := gridfs.NewBucket(s.db, options.GridFSBucket().SetName(coverBucketName))
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := bucket.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+		if err := bucket.SetWriteDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	return bucket, nil
+}
+
+func (s *mongoCoverStore) Save(ctx context.Context, albumID primitive.ObjectID, size string, image CoverImage) error {
+	bucket, err := s.bucket(ctx)
+	if err != nil {
+		return err
+	}
+	name := coverFilename(albumID, size)
+	previous, err := findCoverFiles(ctx, bucket, bson.M{"filename": name})
+	if err != nil {
+		return err
+	}
+
+	metadata := bson.M{"album_id": albumID, "size": size, "content_type": image.ContentType}
+	if _, err := bucket.UploadFromStream(name, bytes.NewReader(image.Data), options.GridFSUpload().SetMetadata(metadata)); err != nil {
+		return err
+	}
+	// Poprzednie wersje usuwane są dopiero po zapisaniu nowej
+	for _, file := range previous {
+		if err := bucket.DeleteContext(ctx, file.ID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *mongoCoverStore) Get(ctx context.Context, albumID primitive.ObjectID, size string) (CoverImage, error) {
+	bucket, err := s.bucket(ctx)
+	if err != nil {
+		return CoverImage{}, err
+	}
+	files, err := findCoverFiles(ctx, bucket, bson.M{"filename": coverFilename(albumID, size)},
+		options.GridFSFind().SetSort(bson.D{{Key: "uploadDate", Value: -1}}).SetLimit(1))
+	if err != nil {
+		return CoverImage{}, err
+	}
+	if len(files) == 0 {
+		return CoverImage{}, ErrNotFound
+	}
+
+	var data bytes.Buffer
+	if _, err := bucket.DownloadToStream(files[0].ID, &data); err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return CoverImage{}, ErrNotFound
+		}
+		return CoverImage{}, err
+	}
+	return CoverImage{
+		ContentType: files[0].Metadata.ContentType,
+		Data:        data.Bytes(),
+		UploadedAt:  files[0].UploadDate,
+	}, nil
+}
+
+func (s *mongoCoverStore) Delete(ctx context.Context, albumID primitive.ObjectID) error {
+	bucket, err := s.bucket(ctx)
+	if err != nil {
+		return err
+	}
+	prefix := "^" + regexp.QuoteMeta(coverFilename(albumID, ""))
+	files, err := findCoverFiles(ctx, bucket, bson.M{"filename": bson.M{"$regex": prefix}})
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := bucket.DeleteContext(ctx, file.ID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func findCoverFiles(ctx context.Context, bucket *gridfs.Bucket, filter bson.M, opts ...*options.GridFSFindOptions) ([]coverFile, error) {
+	cursor, err := bucket.FindContext(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var files []coverFile
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureMongoIndexes tworzy indeksy wymagane przez repozytoria MongoDB
+// i uzupełnia pola wyliczane oraz wersje albumów, a także klucze adresów
+// e-mail użytkowników w dokumentach zapisanych przed ich wprowadzeniem.
+// Wywoływana przy starcie aplikacji, może być wykonywana wielokrotnie.
+func EnsureMongoIndexes(ctx context.Context, db *mongo.Database) error {
+	albums := db.Collection("albums")
+
+	keys := bson.D{}
+	weights := bson.D{}
+	for _, w := range albumSearchWeights {
+		keys = append(keys, bson.E{Key: w.path, Value: "text"})
+		weights = append(weights, bson.E{Key: w.path, Value: w.weight})
+	}
+	keys = append(keys, bson.E{Key: albumSearchTextField, Value: "text"})
+	weights = append(weights, bson.E{Key: albumSearchTextField, Value: 1})
+
+	// Język "none" wyłącza stemming i listy stop-słów, które nie obsługują języka polskiego
+	textIndex := mongo.IndexModel{
+		Keys: keys,
+		Options: options.Index().
+			SetName("albums_text").
+			SetWeights(weights).
+			SetDefaultLanguage("none"),
+	}
+	_, err := albums.Indexes().CreateOne(ctx, textIndex)
+	if isIndexConflict(err) {
+		// Indeks o tej nazwie ma inną definicję (np. sprzed zmiany pól) – tworzony jest od nowa
+		if _, err := albums.Indexes().DropOne(ctx, "albums_text"); err != nil {
+			return fmt.Errorf("usuwanie indeksu tekstowego albumów: %w", err)
+		}
+		_, err = albums.Indexes().CreateOne(ctx, textIndex)
+	}
+	if err != nil {
+		return fmt.Errorf("tworzenie indeksu tekstowego albumów: %w", err)
+	}
+
+	_, err = albums.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "artist_id", Value: 1}},
+		Options: options.Index().SetName("albums_artist_id"),
+	})
+	if err != nil {
+		return fmt.Errorf("tworzenie indeksu wykonawców albumów: %w", err)
+	}
+	// Nazwy i aliasy wykonawców są unikalne po sprowadzeniu do klucza
+	_, err = db.Collection("artists").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: artistNameKeysField, Value: 1}},
+		Options: options.Index().SetName("artists_name_keys").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("tworzenie indeksu nazw wykonawców: %w", err)
+	}
+
+	missing, err := findAll[models.Album](ctx, albums, bson.M{"$or": bson.A{
+		bson.M{albumSearchTextField: bson.M{"$exists": false}},
+		bson.M{"total_duration": bson.M{"$exists": false}},
+		bson.M{"variants": bson.M{"$exists": false}},
+	}})
+	if err != nil {
+		return err
+	}
+	for _, album := range missing {
+		// Cena i liczba sztuk albumu sprzed wprowadzenia wersji przechodzą na jedną wersję CD
+		if len(album.Variants) == 0 {
+			album.Variants = []models.Variant{models.DefaultVariant(album)}
+		}
+		set := albumComputedFields(album)
+		set["variants"] = album.Variants
+		if _, err := albums.UpdateByID(ctx, album.ID, bson.M{"$set": set}); err != nil {
+			return err
+		}
+	}
+
+	// Kody SKU są unikalne w całym sklepie
+	_, err = albums.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "variants.sku", Value: 1}},
+		Options: options.Index().
+			SetName("albums_variants_sku").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"variants.sku": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return fmt.Errorf("tworzenie indeksu kodów SKU: %w", err)
+	}
+
+	// Adresy e-mail są unikalne bez względu na wielkość liter, także w koszu;
+	// klucz uzupełniany jest w kontach zapisanych przed jego wprowadzeniem
+	users := db.Collection("users")
+	legacyUsers, err := findAll[models.User](ctx, users, bson.M{userEmailKeyField: bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	for _, user := range legacyUsers {
+		if _, err := users.UpdateByID(ctx, user.ID, bson.M{"$set": bson.M{userEmailKeyField: EmailKey(user.Email)}}); err != nil {
+			return err
+		}
+	}
+	_, err = users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: userEmailKeyField, Value: 1}},
+		Options: options.Index().SetName("users_email_key").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("tworzenie indeksu adresów e-mail użytkowników: %w", err)
+	}
+
+	// Tokeny użytkowników wyszukiwane są po hashu, a wygasłe usuwa MongoDB
+	tokens := db.Collection("user_tokens")
+	_, err = tokens.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetName("user_tokens_hash").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+			Options: options.Index().SetName("user_tokens_user_purpose"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("user_tokens_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("tworzenie indeksów tokenów użytkowników: %w", err)
+	}
+
+	_, err = db.Collection("refresh_tokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetName("refresh_tokens_hash").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "family_id", Value: 1}},
+			Options: options.Index().SetName("refresh_tokens_family_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("refresh_tokens_user_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("refresh_tokens_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("tworzenie indeksów tokenów odświeżania: %w", err)
+	}
+	_, err = db.Collection("revoked_tokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "jti", Value: 1}},
+			Options: options.Index().SetName("revoked_tokens_jti").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("revoked_tokens_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("tworzenie indeksów unieważnionych tokenów: %w", err)
+	}
+	return nil
+}
+
+// isIndexConflict sprawdza, czy indeks o tej nazwie istnieje już z inną definicją
+// (kody IndexOptionsConflict i IndexKeySpecsConflict)
+func isIndexConflict(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 85 || cmdErr.Code == 86)
+}
@@ -0,0 +1,751 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NewMongoStores tworzy repozytoria korzystające z kolekcji bazy MongoDB.
+// Klient służy do otwierania sesji dla transakcji obejmujących kilka kolekcji.
+func NewMongoStores(client *mongo.Client, db *mongo.Database) Stores {
+	return Stores{
+		Albums: &mongoAlbumStore{
+			mongoTrash: mongoTrash[models.Album]{coll: db.Collection("albums")},
+			coll:       db.Collection("albums"),
+		},
+		Users: &mongoUserStore{
+			mongoTrash: mongoTrash[models.User]{coll: db.Collection("users")},
+			coll:       db.Collection("users"),
+		},
+		Orders: &mongoOrderStore{
+			mongoTrash: mongoTrash[models.Order]{coll: db.Collection("orders")},
+			client:     client,
+			coll:       db.Collection("orders"),
+			albums:     db.Collection("albums"),
+		},
+		Reviews: &mongoReviewStore{
+			mongoTrash: mongoTrash[models.Review]{coll: db.Collection("reviews")},
+			coll:       db.Collection("reviews"),
+		},
+		Carts:   &mongoCartStore{coll: db.Collection("carts")},
+		Covers:  &mongoCoverStore{db: db},
+		Artists: &mongoArtistStore{coll: db.Collection("artists")},
+		Tokens:  &mongoTokenStore{coll: db.Collection("user_tokens")},
+
+		RefreshTokens: &mongoRefreshTokenStore{coll: db.Collection("refresh_tokens")},
+		RevokedTokens: &mongoRevokedTokenStore{coll: db.Collection("revoked_tokens")},
+	}
+}
+
+type mongoAlbumStore struct {
+	mongoTrash[models.Album]
+	coll *mongo.Collection
+}
+
+func albumFilterToBSON(filter AlbumFilter) bson.M {
+	var clauses []bson.M
+	if len(filter.ArtistIDs) > 0 {
+		clauses = append(clauses, bson.M{"artist_id": bson.M{"$in": filter.ArtistIDs}})
+	}
+	if len(filter.Artists) > 0 {
+		clauses = append(clauses, anyText("artist", filter.Artists, filter.ExactMatch))
+	}
+	if len(filter.Genres) > 0 {
+		clauses = append(clauses, anyText("genre", filter.Genres, filter.ExactMatch))
+	}
+	if len(filter.Decades) > 0 {
+		var decades []bson.M
+		for _, decade := range filter.Decades {
+			from, to := decadeRange(decade)
+			decades = append(decades, bson.M{"release_date": bson.M{"$gte": from, "$lt": to}})
+		}
+		clauses = append(clauses, bson.M{"$or": decades})
+	}
+	if len(filter.PriceRanges) > 0 {
+		var prices []bson.M
+		for _, r := range filter.PriceRanges {
+			price := bson.M{"$gte": r.Min}
+			if r.Max > 0 {
+				price["$lt"] = r.Max
+			}
+			prices = append(prices, bson.M{"price": price})
+		}
+		clauses = append(clauses, bson.M{"$or": prices})
+	}
+	if price := rangeFilter(filter.MinPrice, filter.MaxPrice); price != nil {
+		clauses = append(clauses, bson.M{"price": price})
+	}
+	if released := rangeFilter(filter.ReleasedAfter, filter.ReleasedBefore); released != nil {
+		clauses = append(clauses, bson.M{"release_date": released})
+	}
+	if len(filter.Formats) > 0 {
+		variant := bson.M{"format": bson.M{"$in": filter.Formats}}
+		if filter.InStock {
+			variant["stock"] = bson.M{"$gt": 0}
+		}
+		clauses = append(clauses, bson.M{"variants": bson.M{"$elemMatch": variant}})
+	}
+	if filter.InStock {
+		clauses = append(clauses, bson.M{"quantity": bson.M{"$gt": 0}})
+	}
+
+	switch len(clauses) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return clauses[0]
+	}
+	return bson.M{"$and": clauses}
+}
+
+// rangeFilter buduje warunek zakresu włącznie z podanymi granicami; nil, gdy obu brak
+func rangeFilter[T any](min, max *T) bson.M {
+	if min == nil && max == nil {
+		return nil
+	}
+	condition := bson.M{}
+	if min != nil {
+		condition["$gte"] = *min
+	}
+	if max != nil {
+		condition["$lte"] = *max
+	}
+	return condition
+}
+
+// anyText dopasowuje pole do dowolnej z wartości, bez wielkości liter
+func anyText(field string, values []string, exact bool) bson.M {
+	var alternatives []bson.M
+	for _, value := range values {
+		alternatives = append(alternatives, bson.M{field: bson.M{"$regex": textPattern(value, exact), "$options": "i"}})
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	return bson.M{"$or": alternatives}
+}
+
+func (s *mongoAlbumStore) List(ctx context.Context, filter AlbumFilter, opts ListOptions) ([]models.Album, error) {
+	return findPage[models.Album](ctx, s.coll, notTrashed(albumFilterToBSON(filter)), opts)
+}
+
+func (s *mongoAlbumStore) Count(ctx context.Context, filter AlbumFilter) (int64, error) {
+	return s.coll.CountDocuments(ctx, notTrashed(albumFilterToBSON(filter)))
+}
+
+// facetBucket jest wynikiem grupowania w jednym facecie agregacji
+type facetBucket struct {
+	ID    bson.RawValue `bson:"_id"`
+	Count int64         `bson:"count"`
+}
+
+func (s *mongoAlbumStore) Facets(ctx context.Context, filter AlbumFilter) (models.AlbumFacets, error) {
+	// Wspólne kryteria zawęża pierwszy etap, a kryterium własnego wymiaru
+	// każdego facetu pomija jego pierwszy $match
+	common := filter
+	for _, facet := range []string{genreFacet, artistFacet, decadeFacet, priceFacet} {
+		common = facetFilter(common, facet)
+	}
+	facetMatch := func(facet string) bson.M {
+		return bson.M{"$match": albumFilterToBSON(facetFilter(filter, facet))}
+	}
+
+	countByField := func(field string) bson.A {
+		return bson.A{
+			facetMatch(field),
+			bson.M{"$match": bson.M{field: bson.M{"$nin": bson.A{"", nil}}}},
+			bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		}
+	}
+	year := bson.M{"$year": "$release_date"}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: notTrashed(albumFilterToBSON(common))}},
+		{{Key: "$facet", Value: bson.M{
+			genreFacet:  countByField(genreFacet),
+			artistFacet: countByField(artistFacet),
+			decadeFacet: bson.A{
+				facetMatch(decadeFacet),
+				bson.M{"$match": bson.M{"release_date": bson.M{"$gte": minFacetReleaseDate}}},
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$subtract": bson.A{year, bson.M{"$mod": bson.A{year, 10}}}},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+			priceFacet: bson.A{
+				facetMatch(priceFacet),
+				bson.M{"$bucket": bson.M{
+					"groupBy":    "$price",
+					"boundaries": albumPriceBuckets,
+					"default":    "other",
+					"output":     bson.M{"count": bson.M{"$sum": 1}},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := s.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return models.AlbumFacets{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Genre  []facetBucket `bson:"genre"`
+		Artist []facetBucket `bson:"artist"`
+		Decade []facetBucket `bson:"decade"`
+		Price  []facetBucket `bson:"price"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return models.AlbumFacets{}, err
+	}
+
+	facets := models.AlbumFacets{
+		Genre:  []models.FacetCount{},
+		Artist: []models.FacetCount{},
+		Decade: []models.FacetCount{},
+		Price:  []models.FacetCount{},
+	}
+	if len(results) == 0 {
+		return facets, nil
+	}
+	result := results[0]
+
+	for _, b := range result.Genre {
+		facets.Genre = append(facets.Genre, models.FacetCount{Value: b.ID.StringValue(), Count: b.Count})
+	}
+	for _, b := range result.Artist {
+		facets.Artist = append(facets.Artist, models.FacetCount{Value: b.ID.StringValue(), Count: b.Count})
+	}
+	for _, b := range result.Decade {
+		if isNumber(b.ID) {
+			decade := int64(numberValue(b.ID))
+			facets.Decade = append(facets.Decade, models.FacetCount{Value: strconv.FormatInt(decade, 10), Count: b.Count})
+		}
+	}
+	// Ostatnia granica nie zamyka przedziału, więc ceny od niej wzwyż trafiają
+	// do przedziału "default" i odpowiadają ostatniemu przedziałowi facetu
+	for _, b := range result.Price {
+		index := len(albumPriceBuckets) - 1
+		if isNumber(b.ID) {
+			lower := numberValue(b.ID)
+			for i, boundary := range albumPriceBuckets {
+				if boundary == lower {
+					index = i
+				}
+			}
+		}
+		facets.Price = append(facets.Price, models.FacetCount{Value: priceBucketRange(index).String(), Count: b.Count})
+	}
+	return facets, nil
+}
+
+func (s *mongoAlbumStore) Search(ctx context.Context, query string, opts ListOptions) ([]models.AlbumSearchResult, int64, error) {
+	filter := notTrashed(bson.M{"$text": bson.M{"$search": foldText(query)}})
+
+	total, err := s.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	findOpts := findOptions(ListOptions{Skip: opts.Skip, Limit: opts.Limit}).
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}})
+	results, err := findAll[models.AlbumSearchResult](ctx, s.coll, filter, findOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, total, nil
+}
+
+func (s *mongoAlbumStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.Album, error) {
+	return findOne[models.Album](ctx, s.coll, notTrashed(bson.M{"_id": id}))
+}
+
+func (s *mongoAlbumStore) Create(ctx context.Context, album models.Album) error {
+	album = withComputedFields(album)
+	_, err := s.coll.InsertOne(ctx, album)
+	return duplicateAsErr(err)
+}
+
+func (s *mongoAlbumStore) CreateMany(ctx context.Context, albums []models.Album) error {
+	return duplicateAsErr(insertMany(ctx, s.coll, withComputedFieldsAll(albums)))
+}
+
+func (s *mongoAlbumStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	if err := updateByID(ctx, s.coll, id, version, set); err != nil {
+		return duplicateAsErr(err)
+	}
+	if !touchesComputedFields(set) {
+		return nil
+	}
+	album, err := findOne[models.Album](ctx, s.coll, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	// Pola wyliczane – zapis bez zmiany wersji
+	_, err = s.coll.UpdateByID(ctx, id, bson.M{"$set": albumComputedFields(album)})
+	return err
+}
+
+func (s *mongoAlbumStore) ReplaceAll(ctx context.Context, albums []models.Album) error {
+	return duplicateAsErr(replaceAll(ctx, s.coll, withComputedFieldsAll(albums)))
+}
+
+type mongoUserStore struct {
+	mongoTrash[models.User]
+	coll *mongo.Collection
+}
+
+func userFilterToBSON(filter UserFilter) bson.M {
+	query := bson.M{}
+	if len(filter.Roles) > 0 {
+		query["role"] = bson.M{"$in": filter.Roles}
+	}
+	if filter.IsActive != nil {
+		query["is_active"] = *filter.IsActive
+	}
+	return query
+}
+
+func (s *mongoUserStore) List(ctx context.Context, filter UserFilter, opts ListOptions) ([]models.User, error) {
+	return findPage[models.User](ctx, s.coll, notTrashed(userFilterToBSON(filter)), opts)
+}
+
+func (s *mongoUserStore) Count(ctx context.Context, filter UserFilter) (int64, error) {
+	return s.coll.CountDocuments(ctx, notTrashed(userFilterToBSON(filter)))
+}
+
+func (s *mongoUserStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.User, error) {
+	return findOne[models.User](ctx, s.coll, notTrashed(bson.M{"_id": id}))
+}
+
+func (s *mongoUserStore) GetByEmail(ctx context.Context, email string) (models.User, error) {
+	return findOne[models.User](ctx, s.coll, notTrashed(bson.M{userEmailKeyField: EmailKey(email)}))
+}
+
+func (s *mongoUserStore) Create(ctx context.Context, user models.User) error {
+	// Indeks unikalny email_key odrzuca adres zajęty przez innego użytkownika, także w koszu
+	user.EmailKey = EmailKey(user.Email)
+	_, err := s.coll.InsertOne(ctx, user)
+	return duplicateAsErr(err)
+}
+
+func (s *mongoUserStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return duplicateAsErr(updateByID(ctx, s.coll, id, version, withEmailKeyUpdate(set)))
+}
+
+func (s *mongoUserStore) Remove(ctx context.Context, id primitive.ObjectID) error {
+	return deleteByID(ctx, s.coll, id, AnyVersion)
+}
+
+func (s *mongoUserStore) ReplaceAll(ctx context.Context, users []models.User) error {
+	return duplicateAsErr(replaceAll(ctx, s.coll, withEmailKeys(users)))
+}
+
+type mongoOrderStore struct {
+	mongoTrash[models.Order]
+	client *mongo.Client
+	coll   *mongo.Collection
+	albums *mongo.Collection
+}
+
+func orderFilterToBSON(filter OrderFilter) bson.M {
+	query := bson.M{}
+	if !filter.UserID.IsZero() {
+		query["user_id"] = filter.UserID
+	}
+	if !filter.AlbumID.IsZero() {
+		query["items.album_id"] = filter.AlbumID
+	}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if created := rangeFilter(filter.CreatedAfter, filter.CreatedBefore); created != nil {
+		query["created_at"] = created
+	}
+	return query
+}
+
+func (s *mongoOrderStore) List(ctx context.Context, filter OrderFilter, opts ListOptions) ([]models.Order, error) {
+	return findPage[models.Order](ctx, s.coll, notTrashed(orderFilterToBSON(filter)), opts)
+}
+
+func (s *mongoOrderStore) Count(ctx context.Context, filter OrderFilter) (int64, error) {
+	return s.coll.CountDocuments(ctx, notTrashed(orderFilterToBSON(filter)))
+}
+
+func (s *mongoOrderStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.Order, error) {
+	return findOne[models.Order](ctx, s.coll, notTrashed(bson.M{"_id": id}))
+}
+
+func (s *mongoOrderStore) Place(ctx context.Context, order models.Order) error {
+	return withTransaction(ctx, s.client, func(sc mongo.SessionContext) error {
+		for _, item := range order.Items {
+			if err := adjustStock(sc, s.albums, item, -item.Quantity); err != nil {
+				return err
+			}
+		}
+		_, err := s.coll.InsertOne(sc, order)
+		return err
+	})
+}
+
+func (s *mongoOrderStore) TransitionStatus(ctx context.Context, id primitive.ObjectID, version int64, change models.StatusHistory) error {
+	return withTransaction(ctx, s.client, func(sc mongo.SessionContext) error {
+		order, err := findOne[models.Order](sc, s.coll, notTrashed(bson.M{"_id": id}))
+		if err != nil {
+			return err
+		}
+		if order.Status != change.From || (version != AnyVersion && order.Version != version) {
+			return ErrConflict
+		}
+
+		if sign := stockDirection(change.From, change.To); sign != 0 {
+			for _, item := range order.Items {
+				if err := adjustStock(sc, s.albums, item, sign*item.Quantity); err != nil {
+					return err
+				}
+			}
+		}
+
+		filter := bson.M{"_id": id, "status": change.From}
+		if version != AnyVersion {
+			filter["version"] = versionMatch(version)
+		}
+		result, err := s.coll.UpdateOne(sc, filter,
+			bson.M{
+				"$set":  bson.M{"status": change.To, "updated_at": change.ChangedAt},
+				"$push": bson.M{"history": change},
+				"$inc":  bson.M{"version": 1},
+			})
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return ErrConflict
+		}
+		return nil
+	})
+}
+
+func (s *mongoOrderStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return updateByID(ctx, s.coll, id, version, set)
+}
+
+func (s *mongoOrderStore) AnonymizeShipping(ctx context.Context, userID primitive.ObjectID) error {
+	orders, err := findAll[models.Order](ctx, s.coll, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		_, err := s.coll.UpdateByID(ctx, order.ID, bson.M{
+			"$set": bson.M{"shipping": order.Shipping.Anonymized()},
+			"$inc": bson.M{"version": 1},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *mongoOrderStore) ReplaceAll(ctx context.Context, orders []models.Order) error {
+	return replaceAll(ctx, s.coll, orders)
+}
+
+type mongoReviewStore struct {
+	mongoTrash[models.Review]
+	coll *mongo.Collection
+}
+
+func reviewFilterToBSON(filter ReviewFilter) bson.M {
+	query := bson.M{}
+	if !filter.AlbumID.IsZero() {
+		query["album_id"] = filter.AlbumID
+	}
+	if !filter.UserID.IsZero() {
+		query["user_id"] = filter.UserID
+	}
+	if rating := rangeFilter(filter.MinRating, filter.MaxRating); rating != nil {
+		query["rating"] = rating
+	}
+	return query
+}
+
+func (s *mongoReviewStore) List(ctx context.Context, filter ReviewFilter, opts ListOptions) ([]models.Review, error) {
+	return findPage[models.Review](ctx, s.coll, notTrashed(reviewFilterToBSON(filter)), opts)
+}
+
+func (s *mongoReviewStore) Count(ctx context.Context, filter ReviewFilter) (int64, error) {
+	return s.coll.CountDocuments(ctx, notTrashed(reviewFilterToBSON(filter)))
+}
+
+func (s *mongoReviewStore) GetByID(ctx context.Context, id primitive.ObjectID) (models.Review, error) {
+	return findOne[models.Review](ctx, s.coll, notTrashed(bson.M{"_id": id}))
+}
+
+func (s *mongoReviewStore) Create(ctx context.Context, review models.Review) error {
+	_, err := s.coll.InsertOne(ctx, review)
+	return err
+}
+
+func (s *mongoReviewStore) Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return updateByID(ctx, s.coll, id, version, set)
+}
+
+func (s *mongoReviewStore) DetachUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := s.coll.UpdateMany(ctx, bson.M{"user_id": userID}, bson.M{
+		"$set": bson.M{"user_id": primitive.NilObjectID},
+		"$inc": bson.M{"version": 1},
+	})
+	return err
+}
+
+func (s *mongoReviewStore) ReplaceAll(ctx context.Context, reviews []models.Review) error {
+	return replaceAll(ctx, s.coll, reviews)
+}
+
+type mongoCartStore struct {
+	coll *mongo.Collection
+}
+
+func (s *mongoCartStore) Get(ctx context.Context, userID primitive.ObjectID) (models.Cart, error) {
+	return findOne[models.Cart](ctx, s.coll, bson.M{"_id": userID})
+}
+
+func (s *mongoCartStore) Save(ctx context.Context, cart models.Cart) error {
+	_, err := s.coll.ReplaceOne(ctx, bson.M{"_id": cart.UserID}, cart, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (s *mongoCartStore) Delete(ctx context.Context, userID primitive.ObjectID) error {
+	return deleteByID(ctx, s.coll, userID, AnyVersion)
+}
+
+// withTransaction wykonuje fn w transakcji wielodokumentowej. Sterownik
+// ponawia transakcję przy konfliktach zapisu z równoległymi transakcjami.
+func withTransaction(ctx context.Context, client *mongo.Client, fn func(sc mongo.SessionContext) error) error {
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// adjustStock zmienia o delta stan magazynowy wersji albumu z pozycji
+// zamówienia oraz łączną liczbę sztuk albumu. Zmniejszenie wykonywane jest
+// warunkowo, więc stan nigdy nie spada poniżej zera. Zwrot sztuk do
+// usuniętego albumu lub wersji jest pomijany; album w koszu przyjmuje zwrot,
+// ale nie może być zamówiony.
+func adjustStock(ctx context.Context, albums *mongo.Collection, item models.OrderItem, delta int) error {
+	filter := bson.M{"_id": item.AlbumID}
+	if delta < 0 {
+		filter = notTrashed(filter)
+	}
+	stockField := "variants.$.stock"
+	if item.VariantID.IsZero() {
+		// Pozycje sprzed wprowadzenia wersji dotyczą jedynej wersji utworzonej przy migracji albumu
+		stockField = "variants.0.stock"
+		filter[stockField] = bson.M{"$exists": true}
+		if delta < 0 {
+			filter[stockField] = bson.M{"$gte": -delta}
+		}
+	} else {
+		variant := bson.M{"_id": item.VariantID}
+		if delta < 0 {
+			variant["stock"] = bson.M{"$gte": -delta}
+		}
+		filter["variants"] = bson.M{"$elemMatch": variant}
+	}
+
+	result, err := albums.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{stockField: delta, "quantity": delta, "version": 1}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 && delta < 0 {
+		return &InsufficientStockError{AlbumID: item.AlbumID, SKU: item.SKU}
+	}
+	return nil
+}
+
+// findOptions przekłada opcje listy na opcje zapytania MongoDB. Przy
+// sortowaniu _id dopisywane jest jako ostatni klucz, aby kolejność była
+// jednoznaczna; przy kursorze wstecz kierunki są odwracane.
+func findOptions(opts ListOptions) *options.FindOptions {
+	findOpts := options.Find()
+	if len(opts.Sort) > 0 || opts.Cursor != nil {
+		sortFields := bson.D{}
+		for _, field := range sortWithID(opts.Sort, opts.Cursor != nil && opts.Cursor.Before) {
+			direction := 1
+			if field.Desc {
+				direction = -1
+			}
+			sortFields = append(sortFields, bson.E{Key: field.Field, Value: direction})
+		}
+		findOpts.SetSort(sortFields)
+	}
+	if opts.Skip > 0 && opts.Cursor == nil {
+		findOpts.SetSkip(opts.Skip)
+	}
+	if opts.Limit > 0 {
+		findOpts.SetLimit(opts.Limit)
+	}
+	return findOpts
+}
+
+// findPage pobiera stronę dokumentów spełniających filtr, uwzględniając kursor z opts
+func findPage[T any](ctx context.Context, coll *mongo.Collection, filter bson.M, opts ListOptions) ([]T, error) {
+	if opts.Cursor != nil {
+		filter = bson.M{"$and": bson.A{filter, keysetFilter(opts)}}
+	}
+	docs, err := findAll[T](ctx, coll, filter, findOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	if opts.Cursor != nil && opts.Cursor.Before {
+		reverseDocs(docs)
+	}
+	return docs, nil
+}
+
+func findAll[T any](ctx context.Context, coll *mongo.Collection, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := coll.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	docs := []T{}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+func findOne[T any](ctx context.Context, coll *mongo.Collection, filter interface{}) (T, error) {
+	var doc T
+	err := coll.FindOne(ctx, filter).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return doc, ErrNotFound
+	}
+	return doc, err
+}
+
+func insertMany[T any](ctx context.Context, coll *mongo.Collection, docs []T) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	items := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		items = append(items, doc)
+	}
+	_, err := coll.InsertMany(ctx, items)
+	return err
+}
+
+// updateByID ustawia pola dokumentu i zwiększa jego wersję; pola z wartością
+// nil są usuwane ($unset). Gdy version jest różne od AnyVersion, dokument
+// musi mieć tę wersję, inaczej zwracany jest ErrConflict. Dokumenty w koszu
+// traktowane są jak nieistniejące.
+func updateByID(ctx context.Context, coll *mongo.Collection, id primitive.ObjectID, version int64, set bson.M) error {
+	filter := notTrashed(bson.M{"_id": id})
+	if version != AnyVersion {
+		filter["version"] = versionMatch(version)
+	}
+
+	update := bson.M{"$inc": bson.M{"version": 1}}
+	fields, unset := bson.M{}, bson.M{}
+	for key, value := range set {
+		if value == nil {
+			unset[key] = ""
+		} else {
+			fields[key] = value
+		}
+	}
+	if len(fields) > 0 {
+		update["$set"] = fields
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+	if version != AnyVersion {
+		count, err := coll.CountDocuments(ctx, notTrashed(bson.M{"_id": id}))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrConflict
+		}
+	}
+	return ErrNotFound
+}
+
+// versionMatch dopasowuje wersję dokumentu; dokumenty bez pola version mają wersję 0
+func versionMatch(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// deleteByID usuwa dokument; wersja sprawdzana jest tak jak w updateByID
+func deleteByID(ctx context.Context, coll *mongo.Collection, id primitive.ObjectID, version int64) error {
+	filter := bson.M{"_id": id}
+	if version != AnyVersion {
+		filter["version"] = versionMatch(version)
+	}
+	result, err := coll.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount > 0 {
+		return nil
+	}
+	if version != AnyVersion {
+		count, err := coll.CountDocuments(ctx, bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrConflict
+		}
+	}
+	return ErrNotFound
+}
+
+// replaceAll zastępuje wszystkie dokumenty kolekcji. Kolekcja jest czyszczona,
+// a nie usuwana, aby zachować jej indeksy; czyszczenie i wstawianie wykonywane
+// są w jednej transakcji, więc nieudany import nie usuwa istniejących danych.
+func replaceAll[T any](ctx context.Context, coll *mongo.Collection, docs []T) error {
+	return withTransaction(ctx, coll.Database().Client(), func(sc mongo.SessionContext) error {
+		if _, err := coll.DeleteMany(sc, bson.M{}); err != nil {
+			return err
+		}
+		return insertMany(sc, coll, docs)
+	})
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoTokenStore struct {
+	coll *mongo.Collection
+}
+
+func (s *mongoTokenStore) Create(ctx context.Context, token models.UserToken) error {
+	_, err := s.coll.InsertOne(ctx, token)
+	return err
+}
+
+func (s *mongoTokenStore) Consume(ctx context.Context, purpose, hash string) (models.UserToken, error) {
+	// Wyszukanie i usunięcie w jednej operacji gwarantuje jednokrotne użycie tokenu
+	var token models.UserToken
+	err := s.coll.FindOneAndDelete(ctx, bson.M{
+		"purpose":    purpose,
+		"hash":       hash,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return token, ErrNotFound
+	}
+	return token, err
+}
+
+func (s *mongoTokenStore) DeleteByUser(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	_, err := s.coll.DeleteMany(ctx, bson.M{"user_id": userID, "purpose": purpose})
+	return err
+}
+
+type mongoRefreshTokenStore struct {
+	coll *mongo.Collection
+}
+
+func (s *mongoRefreshTokenStore) Create(ctx context.Context, token models.RefreshToken) error {
+	_, err := s.coll.InsertOne(ctx, token)
+	return err
+}
+
+func (s *mongoRefreshTokenStore) GetByHash(ctx context.Context, hash string) (models.RefreshToken, error) {
+	return findOne[models.RefreshToken](ctx, s.coll, bson.M{"hash": hash})
+}
+
+func (s *mongoRefreshTokenStore) MarkUsed(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	// Warunek w filtrze sprawia, że z dwóch równoczesnych wymian tego samego
+	// tokenu powiedzie się tylko jedna
+	result, err := s.coll.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": nil, "revoked_at": nil},
+		bson.M{"$set": bson.M{"used_at": at}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		count, err := s.coll.CountDocuments(ctx, bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrNotFound
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *mongoRefreshTokenStore) RevokeFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	return s.revoke(ctx, bson.M{"family_id": familyID})
+}
+
+func (s *mongoRefreshTokenStore) RevokeUser(ctx context.Context, userID primitive.ObjectID) error {
+	return s.revoke(ctx, bson.M{"user_id": userID})
+}
+
+// revoke unieważnia tokeny spełniające filter, pomijając już unieważnione
+func (s *mongoRefreshTokenStore) revoke(ctx context.Context, filter bson.M) error {
+	filter["revoked_at"] = nil
+	_, err := s.coll.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	return err
+}
+
+type mongoRevokedTokenStore struct {
+	coll *mongo.Collection
+}
+
+func (s *mongoRevokedTokenStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := s.coll.UpdateOne(ctx,
+		bson.M{"jti": jti},
+		bson.M{
+			"$set":         bson.M{"expires_at": expiresAt},
+			"$setOnInsert": bson.M{"_id": primitive.NewObjectID()},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *mongoRevokedTokenStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	count, err := s.coll.CountDocuments(ctx, bson.M{"jti": jti}, options.Count().SetLimit(1))
+	return count > 0, err
+}
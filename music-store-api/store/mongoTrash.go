@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// notTrashed dopisuje do filtra warunek pomijający dokumenty w koszu
+func notTrashed(filter bson.M) bson.M {
+	filter[deletedAtField] = nil
+	return filter
+}
+
+// trashed dopasowuje dokumenty w koszu
+func trashed() bson.M {
+	return bson.M{deletedAtField: bson.M{"$ne": nil}}
+}
+
+// mongoTrash implementuje miękkie usuwanie i kosz dla kolekcji MongoDB;
+// osadzany w repozytoriach kolekcji z koszem
+type mongoTrash[T any] struct {
+	coll *mongo.Collection
+}
+
+func (s mongoTrash[T]) Delete(ctx context.Context, id primitive.ObjectID, version int64) error {
+	return updateByID(ctx, s.coll, id, version, bson.M{deletedAtField: time.Now()})
+}
+
+func (s mongoTrash[T]) ListTrash(ctx context.Context, opts ListOptions) ([]T, error) {
+	return findPage[T](ctx, s.coll, trashed(), opts)
+}
+
+func (s mongoTrash[T]) CountTrash(ctx context.Context) (int64, error) {
+	return s.coll.CountDocuments(ctx, trashed())
+}
+
+func (s mongoTrash[T]) Restore(ctx context.Context, id primitive.ObjectID) error {
+	filter := trashed()
+	filter["_id"] = id
+	result, err := s.coll.UpdateOne(ctx, filter, bson.M{
+		"$unset": bson.M{deletedAtField: ""},
+		"$inc":   bson.M{"version": 1},
+	})
+	if err != nil {
+		return duplicateAsErr(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s mongoTrash[T]) Purge(ctx context.Context, before time.Time) ([]primitive.ObjectID, error) {
+	filter := bson.M{deletedAtField: bson.M{"$lt": before}}
+	docs, err := findAll[struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}](ctx, s.coll, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	// Usuwanie pojedynczo z warunkiem na deleted_at pomija dokumenty
+	// przywrócone w międzyczasie, więc zwracane są tylko faktycznie usunięte
+	purged := []primitive.ObjectID{}
+	for _, doc := range docs {
+		filter["_id"] = doc.ID
+		result, err := s.coll.DeleteOne(ctx, filter)
+		if err != nil {
+			return purged, err
+		}
+		if result.DeletedCount > 0 {
+			purged = append(purged, doc.ID)
+		}
+	}
+	return purged, nil
+}
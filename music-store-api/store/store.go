@@ -0,0 +1,334 @@
+// Package store definiuje repozytoria danych używane przez kontrolery
+// oraz ich implementacje: MongoDB oraz w pamięci.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotFound zwracany jest, gdy dokument o podanym identyfikatorze nie istnieje
+var ErrNotFound = errors.New("dokument nie znaleziony")
+
+// ErrConflict zwracany jest, gdy dokument został w międzyczasie zmieniony przez inną operację
+var ErrConflict = errors.New("dokument został zmieniony równolegle")
+
+// InsufficientStockError zwracany jest, gdy stan magazynowy wersji albumu nie pokrywa zamawianej ilości
+type InsufficientStockError struct {
+	AlbumID primitive.ObjectID
+	// Kod SKU wersji (pusty dla pozycji sprzed wprowadzenia wersji)
+	SKU string
+}
+
+func (e *InsufficientStockError) Error() string {
+	if e.SKU == "" {
+		return fmt.Sprintf("niewystarczająca liczba sztuk albumu %s", e.AlbumID.Hex())
+	}
+	return fmt.Sprintf("niewystarczająca liczba sztuk albumu %s w wersji %s", e.AlbumID.Hex(), e.SKU)
+}
+
+// stockDirection określa, jak zmiana statusu zamówienia wpływa na magazyn:
+// -1 gdy sztuki trzeba ponownie zarezerwować, 1 gdy wracają do magazynu, 0 bez zmian
+func stockDirection(from, to string) int {
+	switch {
+	case from != models.OrderStatusCancelled && to == models.OrderStatusCancelled:
+		return 1
+	case from == models.OrderStatusCancelled && to != models.OrderStatusCancelled:
+		return -1
+	}
+	return 0
+}
+
+// SortField opisuje pojedyncze pole sortowania
+type SortField struct {
+	// Nazwa pola w dokumencie (klucz BSON)
+	Field string
+	// Sortowanie malejące
+	Desc bool
+}
+
+// ListOptions opisuje sortowanie i stronicowanie listy dokumentów
+type ListOptions struct {
+	Sort  []SortField
+	Skip  int64
+	Limit int64
+	// Stronicowanie według klucza zamiast Skip
+	Cursor *Cursor
+}
+
+// AlbumFilter opisuje kryteria filtrowania albumów. Album musi spełniać
+// wszystkie podane kryteria; w obrębie jednego kryterium wystarczy zgodność
+// z dowolną z wartości.
+type AlbumFilter struct {
+	// Dowolny z identyfikatorów wykonawcy
+	ArtistIDs []primitive.ObjectID
+	// Zgodność z wykonawcą, bez wielkości liter
+	Artists []string
+	// Zgodność z gatunkiem, bez wielkości liter
+	Genres []string
+	// Wykonawca i gatunek muszą być równe wartości filtra zamiast ją zawierać
+	ExactMatch bool
+	// Dekady wydania, np. 1980 dla lat 1980-1989
+	Decades []int
+	// Przedziały cenowe
+	PriceRanges []PriceRange
+	// Minimalna i maksymalna cena (włącznie)
+	MinPrice *float64
+	MaxPrice *float64
+	// Najwcześniejsza i najpóźniejsza data wydania (włącznie)
+	ReleasedAfter  *time.Time
+	ReleasedBefore *time.Time
+	// Dowolny z formatów wersji albumu (np. vinyl, cd)
+	Formats []string
+	// Tylko albumy dostępne w magazynie; razem z Formats – dostępne w którymś z tych formatów
+	InStock bool
+}
+
+// textPattern zwraca wyrażenie regularne dopasowujące wartość filtra tekstowego.
+// Wartość jest cytowana, więc znaki specjalne wyrażeń regularnych traktowane są dosłownie.
+func textPattern(value string, exact bool) string {
+	if exact {
+		return "^" + regexp.QuoteMeta(value) + "$"
+	}
+	return regexp.QuoteMeta(value)
+}
+
+// UserFilter opisuje kryteria filtrowania użytkowników
+type UserFilter struct {
+	// Dowolna z ról
+	Roles    []string
+	IsActive *bool
+}
+
+// OrderFilter opisuje kryteria filtrowania zamówień
+type OrderFilter struct {
+	UserID primitive.ObjectID
+	// Zamówienia z pozycją dotyczącą albumu
+	AlbumID primitive.ObjectID
+	// Dowolny ze statusów
+	Statuses []string
+	// Zakres daty utworzenia (włącznie)
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// ReviewFilter opisuje kryteria filtrowania recenzji
+type ReviewFilter struct {
+	AlbumID primitive.ObjectID
+	UserID  primitive.ObjectID
+	// Zakres oceny (włącznie)
+	MinRating *int
+	MaxRating *int
+}
+
+// AnyVersion przekazany jako wersja do metod Update wyłącza sprawdzanie wersji dokumentu
+const AnyVersion int64 = -1
+
+// Metody Update przyjmują mapę pól najwyższego poziomu działającą jak
+// operator $set; pole z wartością nil jest usuwane z dokumentu ($unset).
+// Każda zmiana dokumentu zwiększa jego pole version o 1. Gdy version jest
+// różne od AnyVersion, aktualizacja wykonywana jest tylko wtedy, gdy bieżąca
+// wersja dokumentu jest równa version; w przeciwnym razie zwracany jest ErrConflict.
+//
+// Metody Delete przyjmują wersję tak jak metody Update: gdy version jest
+// różne od AnyVersion, a bieżąca wersja dokumentu inna, zwracany jest ErrConflict.
+
+// deletedAtField jest polem z datą przeniesienia dokumentu do kosza
+const deletedAtField = "deleted_at"
+
+// Trash jest koszem repozytorium z miękkim usuwaniem. Metoda Delete takiego
+// repozytorium ustawia w dokumencie deleted_at zamiast go usuwać, a wszystkie
+// pozostałe metody traktują dokumenty w koszu jak nieistniejące. Delete
+// i Restore zwiększają wersję dokumentu.
+type Trash[T any] interface {
+	// ListTrash zwraca dokumenty w koszu
+	ListTrash(ctx context.Context, opts ListOptions) ([]T, error)
+	CountTrash(ctx context.Context) (int64, error)
+	// Restore przywraca dokument z kosza; ErrNotFound, gdy dokumentu nie ma
+	// w koszu, a ErrDuplicate, gdy przywrócenie narusza indeks unikalny
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	// Purge trwale usuwa dokumenty przeniesione do kosza przed before
+	// i zwraca ich identyfikatory
+	Purge(ctx context.Context, before time.Time) ([]primitive.ObjectID, error)
+}
+
+// AlbumStore jest repozytorium albumów
+type AlbumStore interface {
+	Trash[models.Album]
+	List(ctx context.Context, filter AlbumFilter, opts ListOptions) ([]models.Album, error)
+	Count(ctx context.Context, filter AlbumFilter) (int64, error)
+	// Facets zlicza albumy spełniające filtr według gatunku, wykonawcy,
+	// dekady wydania i przedziału cenowego. Każdy facet liczony jest bez
+	// kryterium filtra dotyczącego jego własnego wymiaru.
+	Facets(ctx context.Context, filter AlbumFilter) (models.AlbumFacets, error)
+	// Search wyszukuje albumy pełnotekstowo w tytule, wykonawcy, opisie
+	// i utworach, bez uwzględniania wielkości liter i znaków diakrytycznych.
+	// Wyniki sortowane są malejąco według trafności; sortowanie z opts jest
+	// pomijane. Zwraca również łączną liczbę trafień.
+	Search(ctx context.Context, query string, opts ListOptions) ([]models.AlbumSearchResult, int64, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Album, error)
+	Create(ctx context.Context, album models.Album) error
+	CreateMany(ctx context.Context, albums []models.Album) error
+	Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID, version int64) error
+	ReplaceAll(ctx context.Context, albums []models.Album) error
+}
+
+// UserStore jest repozytorium użytkowników. Adres e-mail jest unikalny po
+// sprowadzeniu funkcją EmailKey wśród wszystkich użytkowników, także w koszu;
+// zapis naruszający tę zasadę zwraca ErrDuplicate.
+type UserStore interface {
+	Trash[models.User]
+	List(ctx context.Context, filter UserFilter, opts ListOptions) ([]models.User, error)
+	Count(ctx context.Context, filter UserFilter) (int64, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.User, error)
+	// GetByEmail zwraca użytkownika spoza kosza o adresie odpowiadającym email
+	// (bez wielkości liter i otaczających spacji)
+	GetByEmail(ctx context.Context, email string) (models.User, error)
+	Create(ctx context.Context, user models.User) error
+	Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID, version int64) error
+	// Remove trwale usuwa użytkownika z pominięciem kosza, zwalniając jego
+	// adres e-mail (np. konto, na które nie udało się wysłać linku aktywacyjnego)
+	Remove(ctx context.Context, id primitive.ObjectID) error
+	ReplaceAll(ctx context.Context, users []models.User) error
+}
+
+// OrderStore jest repozytorium zamówień
+type OrderStore interface {
+	Trash[models.Order]
+	List(ctx context.Context, filter OrderFilter, opts ListOptions) ([]models.Order, error)
+	Count(ctx context.Context, filter OrderFilter) (int64, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Order, error)
+	// Place zapisuje zamówienie i w tej samej transakcji zmniejsza stan
+	// magazynowy albumów. Zwraca *InsufficientStockError, gdy brakuje sztuk.
+	Place(ctx context.Context, order models.Order) error
+	// TransitionStatus zmienia status zamówienia z change.From na change.To
+	// i dopisuje change do historii. Zwraca ErrConflict, gdy bieżący status
+	// jest inny niż change.From albo, tak jak w Update, wersja jest inna niż
+	// version (AnyVersion pomija to sprawdzenie). Anulowanie zwraca sztuki do
+	// magazynu w tej samej transakcji.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, version int64, change models.StatusHistory) error
+	Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID, version int64) error
+	// AnonymizeShipping zastępuje dane wysyłki wszystkich zamówień użytkownika
+	// userID, także tych w koszu, ich wersją bez danych osobowych
+	// (models.ShippingDetails.Anonymized)
+	AnonymizeShipping(ctx context.Context, userID primitive.ObjectID) error
+	ReplaceAll(ctx context.Context, orders []models.Order) error
+}
+
+// ReviewStore jest repozytorium recenzji
+type ReviewStore interface {
+	Trash[models.Review]
+	List(ctx context.Context, filter ReviewFilter, opts ListOptions) ([]models.Review, error)
+	Count(ctx context.Context, filter ReviewFilter) (int64, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Review, error)
+	Create(ctx context.Context, review models.Review) error
+	Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID, version int64) error
+	// DetachUser zeruje user_id we wszystkich recenzjach użytkownika userID,
+	// także tych w koszu
+	DetachUser(ctx context.Context, userID primitive.ObjectID) error
+	ReplaceAll(ctx context.Context, reviews []models.Review) error
+}
+
+// ArtistStore jest repozytorium wykonawców. Nazwa i aliasy wykonawcy są
+// unikalne po sprowadzeniu funkcją ArtistNameKey; zapis naruszający tę
+// zasadę zwraca ErrDuplicate.
+type ArtistStore interface {
+	List(ctx context.Context, filter ArtistFilter, opts ListOptions) ([]models.Artist, error)
+	Count(ctx context.Context, filter ArtistFilter) (int64, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (models.Artist, error)
+	// FindByName zwraca wykonawcę, którego nazwa lub alias odpowiada name
+	// (bez wielkości liter, znaków diakrytycznych i interpunkcji)
+	FindByName(ctx context.Context, name string) (models.Artist, error)
+	Create(ctx context.Context, artist models.Artist) error
+	Update(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID, version int64) error
+	ReplaceAll(ctx context.Context, artists []models.Artist) error
+}
+
+// CartStore jest repozytorium koszyków, po jednym na użytkownika
+type CartStore interface {
+	Get(ctx context.Context, userID primitive.ObjectID) (models.Cart, error)
+	// Save zapisuje koszyk, tworząc go, jeśli jeszcze nie istnieje
+	Save(ctx context.Context, cart models.Cart) error
+	Delete(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// TokenStore jest repozytorium jednorazowych tokenów użytkowników
+type TokenStore interface {
+	Create(ctx context.Context, token models.UserToken) error
+	// Consume usuwa i zwraca ważny token o podanym przeznaczeniu i hashu;
+	// ErrNotFound, gdy tokenu nie ma, został już użyty lub wygasł
+	Consume(ctx context.Context, purpose, hash string) (models.UserToken, error)
+	// DeleteByUser usuwa tokeny użytkownika o podanym przeznaczeniu
+	DeleteByUser(ctx context.Context, userID primitive.ObjectID, purpose string) error
+}
+
+// RefreshTokenStore jest repozytorium tokenów odświeżania sesji
+type RefreshTokenStore interface {
+	Create(ctx context.Context, token models.RefreshToken) error
+	// GetByHash zwraca token o podanym hashu, również użyty lub unieważniony
+	GetByHash(ctx context.Context, hash string) (models.RefreshToken, error)
+	// MarkUsed oznacza token jako wymieniony; ErrConflict, gdy token został
+	// już użyty lub unieważniony
+	MarkUsed(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// RevokeFamily unieważnia wszystkie tokeny rodziny (sesji)
+	RevokeFamily(ctx context.Context, familyID primitive.ObjectID) error
+	// RevokeUser unieważnia wszystkie tokeny użytkownika
+	RevokeUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// RevokedTokenStore jest listą unieważnionych tokenów dostępu (jti)
+type RevokedTokenStore interface {
+	// Revoke unieważnia token jti do chwili jego wygaśnięcia expiresAt
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// CoverImage jest plikiem okładki albumu w jednym rozmiarze
+type CoverImage struct {
+	ContentType string
+	Data        []byte
+	UploadedAt  time.Time
+}
+
+// CoverStore przechowuje pliki okładek albumów w kilku rozmiarach
+// (np. "original", "150", "600")
+type CoverStore interface {
+	// Save zapisuje okładkę albumu w podanym rozmiarze, zastępując poprzednią
+	Save(ctx context.Context, albumID primitive.ObjectID, size string, image CoverImage) error
+	Get(ctx context.Context, albumID primitive.ObjectID, size string) (CoverImage, error)
+	// Delete usuwa wszystkie rozmiary okładki albumu
+	Delete(ctx context.Context, albumID primitive.ObjectID) error
+}
+
+// coverFilename zwraca nazwę pliku okładki albumu w danym rozmiarze
+func coverFilename(albumID primitive.ObjectID, size string) string {
+	return albumID.Hex() + "/" + size
+}
+
+// Stores grupuje wszystkie repozytoria jednego backendu
+type Stores struct {
+	Albums  AlbumStore
+	Users   UserStore
+	Orders  OrderStore
+	Reviews ReviewStore
+	Carts   CartStore
+	Covers  CoverStore
+	Artists ArtistStore
+	Tokens  TokenStore
+	// Tokeny odświeżania i unieważnione tokeny dostępu
+	RefreshTokens RefreshTokenStore
+	RevokedTokens RevokedTokenStore
+}
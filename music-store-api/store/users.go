@@ -0,0 +1,42 @@
+package store
+
+import (
+	"maps"
+	"strings"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Pole z kluczem adresu e-mail objęte indeksem unikalnym
+const userEmailKeyField = "email_key"
+
+// EmailKey sprowadza adres e-mail do klucza porównania: bez otaczających
+// spacji i małymi literami, więc "Jan@Example.com" i "jan@example.com"
+// oznaczają to samo konto
+func EmailKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// withEmailKeys zwraca kopie użytkowników z uzupełnionym polem email_key
+func withEmailKeys(users []models.User) []models.User {
+	prepared := make([]models.User, len(users))
+	for i, user := range users {
+		user.EmailKey = EmailKey(user.Email)
+		prepared[i] = user
+	}
+	return prepared
+}
+
+// withEmailKeyUpdate dopisuje do zmian set klucz nowego adresu e-mail;
+// zmiany bez adresu zwracane są bez kopiowania
+func withEmailKeyUpdate(set bson.M) bson.M {
+	email, ok := set["email"].(string)
+	if !ok {
+		return set
+	}
+	set = maps.Clone(set)
+	set[userEmailKeyField] = EmailKey(email)
+	return set
+}
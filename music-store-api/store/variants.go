@@ -0,0 +1,52 @@
+package store
+
+import (
+	"slices"
+
+	"music-store-api/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// touchesVariants sprawdza, czy aktualizacja zmienia wersje albumu
+func touchesVariants(set bson.M) bool {
+	_, ok := set["variants"]
+	return ok
+}
+
+// albumAfter zwraca album current po zastosowaniu zmian set
+func albumAfter(current models.Album, set bson.M) (models.Album, error) {
+	raw, err := bson.Marshal(set)
+	if err != nil {
+		return models.Album{}, err
+	}
+	if err := bson.Unmarshal(raw, &current); err != nil {
+		return models.Album{}, err
+	}
+	return current, nil
+}
+
+// albumSKUs zwraca kody SKU wersji albumu
+func albumSKUs(album models.Album) []string {
+	skus := make([]string, len(album.Variants))
+	for i, variant := range album.Variants {
+		skus[i] = variant.SKU
+	}
+	return skus
+}
+
+// variantIndex zwraca indeks wersji o podanym ID lub -1, gdy album jej nie ma.
+// Pozycje zamówień sprzed wprowadzenia wersji (bez variant_id) dotyczą
+// pierwszej wersji – jedynej utworzonej przy migracji albumu.
+func variantIndex(album models.Album, variantID primitive.ObjectID) int {
+	if variantID.IsZero() {
+		if len(album.Variants) == 0 {
+			return -1
+		}
+		return 0
+	}
+	return slices.IndexFunc(album.Variants, func(variant models.Variant) bool {
+		return variant.ID == variantID
+	})
+}
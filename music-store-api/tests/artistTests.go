@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Testy wykonawców i przypisywania albumów do wykonawców
+func RunArtistTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. POST /artists z aliasem
+	artistJSON := `{"name": "Testowy Zespół", "aliases": ["Zespół Testowy"], "country": "pl", "formed_year": 1990}`
+	resp := doRequest(router, "POST", "/artists", artistJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /artists expected 201, got %d", resp.Code)
+	}
+	var artist struct {
+		ID      string `json:"id"`
+		Country string `json:"country"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &artist); err != nil || artist.ID == "" {
+		return fmt.Errorf("parsing created artist failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/artists/"+artist.ID, "", token)
+	if artist.Country != "PL" {
+		return fmt.Errorf("artist country expected PL, got %q", artist.Country)
+	}
+
+	// 2. Nazwa różniąca się wielkością liter i interpunkcją jest zajęta
+	resp = doRequest(router, "POST", "/artists", `{"name": "TESTOWY-ZESPÓŁ"}`, token)
+	if resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /artists with duplicate name expected 409, got %d", resp.Code)
+	}
+
+	// 3. Album z aliasem wykonawcy jest do niego przypisywany
+	albumJSON := `{
+    "title": "Album wykonawcy",
+    "artist": "zespol testowy",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 15,
+    "quantity": 3}`
+	resp = doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+
+	resp = doRequest(router, "GET", "/artists/"+artist.ID+"/albums", "", "")
+	var albums struct {
+		Total int64 `json:"total"`
+		Data  []struct {
+			Artist   string `json:"artist"`
+			ArtistID string `json:"artist_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &albums); err != nil {
+		return fmt.Errorf("parsing artist albums failed: %v", err)
+	}
+	if albums.Total != 1 || albums.Data[0].Artist != "Testowy Zespół" || albums.Data[0].ArtistID != artist.ID {
+		return fmt.Errorf("GET /artists/:id/albums expected the album linked to the artist, got %+v", albums)
+	}
+
+	// 4. Wykonawcy z albumami, także w koszu, nie można usunąć
+	resp = doRequest(router, "DELETE", "/artists/"+artist.ID, "", token)
+	if resp.Code != http.StatusConflict {
+		return fmt.Errorf("DELETE /artists/:id with albums expected 409, got %d", resp.Code)
+	}
+	resp = doRequest(router, "DELETE", "/albums/"+createdAlbum.ID, "", token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /albums/:id expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "DELETE", "/artists/"+artist.ID, "", token)
+	if resp.Code != http.StatusConflict {
+		return fmt.Errorf("DELETE /artists/:id with trashed album expected 409, got %d", resp.Code)
+	}
+	// Po trwałym usunięciu albumu z kosza wykonawcę można usunąć
+	if err := purgeTrash(); err != nil {
+		return fmt.Errorf("purging trash failed: %v", err)
+	}
+	if resp = doRequest(router, "GET", "/artists/"+artist.ID, "", ""); resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /artists/:id before delete expected 200, got %d", resp.Code)
+	}
+
+	// 5. Stronicowanie kursorem po roku założenia, także dla wykonawców bez roku
+	for _, body := range []string{`{"name": "Testowy Zespół Bez Roku"}`, `{"name": "Testowy Zespół Rówieśnik", "formed_year": 1990}`} {
+		resp = doRequest(router, "POST", "/artists", body, token)
+		var other struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &other); err != nil || resp.Code != http.StatusCreated {
+			return fmt.Errorf("POST /artists expected 201, got %d %v", resp.Code, err)
+		}
+		defer doRequest(router, "DELETE", "/artists/"+other.ID, "", token)
+	}
+	for _, sort := range []string{"formed_year", "-formed_year"} {
+		items, err := walkCursorPages(router, "/artists?sort="+sort+"&limit=2", "id", "")
+		if err != nil {
+			return err
+		}
+		for i := 1; i < len(items); i++ {
+			// Wykonawcy bez roku są sortowani jak null – przed wszystkimi latami
+			previous, _ := items[i-1]["formed_year"].(float64)
+			current, _ := items[i]["formed_year"].(float64)
+			if (sort == "formed_year" && previous > current) || (sort == "-formed_year" && previous < current) {
+				return fmt.Errorf("GET /artists?sort=%s returned formed_year %v before %v", sort, previous, current)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"music-store-api/models"
+)
+
+// getCart pobiera wyceniony koszyk użytkownika
+func getCart(router http.Handler, token string) (models.CartView, error) {
+	var cart models.CartView
+	resp := doRequest(router, "GET", "/cart", "", token)
+	if resp.Code != http.StatusOK {
+		return cart, fmt.Errorf("GET /cart expected 200, got %d", resp.Code)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &cart); err != nil {
+		return cart, fmt.Errorf("parsing cart failed: %v", err)
+	}
+	return cart, nil
+}
+
+// Testy koszyka: wyceny, limitów ilości i składania zamówienia
+func RunCartTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Klient z danymi wysyłki w profilu i album z trzema sztukami
+	userJSON := `{"first_name": "Koszyk", "last_name": "Test", "email": "cart.test@example.com",
+    "password": "password12", "role": "customer", "is_active": true,
+    "shipping_details": {"address": "ul. Koszykowa 1", "city": "Łódź", "postal_code": "90-001", "country": "Polska", "phone_number": "+48100200300"}}`
+	resp := doRequest(router, "POST", "/users", userJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /users expected 201, got %d", resp.Code)
+	}
+	var createdUser struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdUser); err != nil || createdUser.ID == "" {
+		return fmt.Errorf("parsing created user failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/users/"+createdUser.ID+"?force=true", "", token)
+
+	customerToken, err := loginAs(router, "cart.test@example.com", "password12")
+	if err != nil {
+		return err
+	}
+
+	albumID, err := createAlbum(router, `{
+    "title": "Album w koszyku",
+    "artist": "Cart Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 20,
+    "quantity": 3}`, token)
+	if err != nil {
+		return err
+	}
+	defer doRequest(router, "DELETE", "/albums/"+albumID, "", token)
+	itemPath := "/cart/items/" + albumID
+
+	// 2. Ilość musi być dodatnia
+	for _, quantity := range []int{0, -1} {
+		body := fmt.Sprintf(`{"album_id": %q, "quantity": %d}`, albumID, quantity)
+		if resp = doRequest(router, "POST", "/cart/items", body, customerToken); resp.Code != http.StatusBadRequest {
+			return fmt.Errorf("POST /cart/items with quantity %d expected 400, got %d", quantity, resp.Code)
+		}
+	}
+	resp = doRequest(router, "POST", "/cart/items", fmt.Sprintf(`{"album_id": %q, "quantity": 2}`, albumID), customerToken)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /cart/items expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "PUT", itemPath, `{"quantity": 0}`, customerToken); resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("PUT /cart/items/:albumID with quantity 0 expected 400, got %d", resp.Code)
+	}
+
+	cart, err := getCart(router, customerToken)
+	if err != nil {
+		return err
+	}
+	if cart.ItemCount != 2 || cart.Total != 40 || !cart.CanCheckout || len(cart.Warnings) != 0 {
+		return fmt.Errorf("cart expected 2 items for 40 without warnings, got %+v", cart)
+	}
+
+	// 3. Koszyk wyceniany jest według aktualnej ceny albumu
+	if resp = doRequest(router, "PATCH", "/albums/"+albumID, `{"price": 25}`, token); resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /albums/:id expected 200, got %d", resp.Code)
+	}
+	if cart, err = getCart(router, customerToken); err != nil {
+		return err
+	}
+	if len(cart.Items) != 1 || cart.Items[0].Price != 25 || cart.Items[0].LineTotal != 50 || cart.Total != 50 {
+		return fmt.Errorf("cart expected to be repriced to 2 × 25, got %+v", cart)
+	}
+
+	// 4. Więcej sztuk niż w magazynie: ostrzeżenie i odrzucone zamówienie
+	if resp = doRequest(router, "PUT", itemPath, `{"quantity": 5}`, customerToken); resp.Code != http.StatusOK {
+		return fmt.Errorf("PUT /cart/items/:albumID expected 200, got %d", resp.Code)
+	}
+	if cart, err = getCart(router, customerToken); err != nil {
+		return err
+	}
+	if cart.CanCheckout || cart.Items[0].InStock || cart.Items[0].Available != 3 || len(cart.Warnings) != 1 || !strings.Contains(cart.Warnings[0], "3 szt.") {
+		return fmt.Errorf("cart with 5 of 3 available expected a stock warning, got %+v", cart)
+	}
+	if resp = doRequest(router, "POST", "/cart/checkout", "", customerToken); resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /cart/checkout with insufficient stock expected 409, got %d", resp.Code)
+	}
+	if err := expectAlbumQuantity(router, albumID, 3); err != nil {
+		return err
+	}
+
+	// 5. Zamówienie bez danych wysyłki używa danych z profilu i opróżnia koszyk
+	if resp = doRequest(router, "PUT", itemPath, `{"quantity": 2}`, customerToken); resp.Code != http.StatusOK {
+		return fmt.Errorf("PUT /cart/items/:albumID expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "POST", "/cart/checkout", "", customerToken)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /cart/checkout expected 201, got %d", resp.Code)
+	}
+	var order models.Order
+	if err := json.Unmarshal(resp.Body.Bytes(), &order); err != nil || order.ID.IsZero() {
+		return fmt.Errorf("parsing checkout order failed: %v", err)
+	}
+	orderPath := "/orders/" + order.ID.Hex()
+	defer doRequest(router, "DELETE", orderPath, "", token)
+	defer doRequest(router, "PATCH", orderPath+"/status", `{"status": "cancelled"}`, token)
+
+	if order.Total != 50 || order.Shipping.Address != "ul. Koszykowa 1" || order.Shipping.City != "Łódź" {
+		return fmt.Errorf("checkout order expected total 50 shipped to the profile address, got %v to %+v", order.Total, order.Shipping)
+	}
+	if err := expectAlbumQuantity(router, albumID, 1); err != nil {
+		return err
+	}
+	if cart, err = getCart(router, customerToken); err != nil {
+		return err
+	}
+	if len(cart.Items) != 0 || cart.ItemCount != 0 || cart.CanCheckout {
+		return fmt.Errorf("cart expected to be empty after checkout, got %+v", cart)
+	}
+	if resp = doRequest(router, "POST", "/cart/checkout", "", customerToken); resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("POST /cart/checkout with empty cart expected 400, got %d", resp.Code)
+	}
+
+	return nil
+}
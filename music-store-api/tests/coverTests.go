@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+)
+
+// Testy przesyłania i pobierania okładki albumu
+func RunCoverTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. POST /albums
+	albumJSON := `{
+    "title": "Z okładką",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 15,
+    "quantity": 3}`
+	resp := doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/albums/"+createdAlbum.ID, "", token)
+	coverPath := "/albums/" + createdAlbum.ID + "/cover"
+
+	// 2. Plik, który nie jest obrazem, jest odrzucany
+	resp = doUpload(router, coverPath, []byte("to nie jest obraz"), token)
+	if resp.Code != http.StatusUnsupportedMediaType {
+		return fmt.Errorf("POST /albums/:id/cover with text file expected 415, got %d", resp.Code)
+	}
+
+	// 3. Przesłanie obrazu PNG 800x400 ustawia cover_url
+	var buf bytes.Buffer
+	src := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for x := 0; x < 800; x++ {
+		for y := 0; y < 400; y++ {
+			src.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	if err := png.Encode(&buf, src); err != nil {
+		return fmt.Errorf("encoding test image failed: %v", err)
+	}
+	resp = doUpload(router, coverPath, buf.Bytes(), token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums/:id/cover expected 201, got %d", resp.Code)
+	}
+	resp = doRequest(router, "GET", "/albums/"+createdAlbum.ID, "", "")
+	var album struct {
+		CoverURL string `json:"cover_url"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil {
+		return fmt.Errorf("parsing album failed: %v", err)
+	}
+	if album.CoverURL != coverPath {
+		return fmt.Errorf("album cover_url expected %q, got %q", coverPath, album.CoverURL)
+	}
+
+	// 4. Miniatura 150 px zachowuje proporcje i jest JPEG-iem
+	resp = doRequest(router, "GET", coverPath+"?size=150", "", "")
+	if resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != "image/jpeg" {
+		return fmt.Errorf("GET /albums/:id/cover?size=150 expected 200 image/jpeg, got %d %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	thumbnail, err := jpeg.DecodeConfig(bytes.NewReader(resp.Body.Bytes()))
+	if err != nil {
+		return fmt.Errorf("decoding thumbnail failed: %v", err)
+	}
+	if thumbnail.Width != 150 || thumbnail.Height != 75 {
+		return fmt.Errorf("thumbnail expected 150x75, got %dx%d", thumbnail.Width, thumbnail.Height)
+	}
+
+	// 5. Oryginał ma ETag i Cache-Control, a warunkowy GET zwraca 304
+	resp = doRequest(router, "GET", coverPath, "", "")
+	tag := resp.Header().Get("ETag")
+	if resp.Code != http.StatusOK || tag == "" || resp.Header().Get("Cache-Control") == "" {
+		return fmt.Errorf("GET /albums/:id/cover expected 200 with caching headers, got %d", resp.Code)
+	}
+	if !bytes.Equal(resp.Body.Bytes(), buf.Bytes()) {
+		return fmt.Errorf("GET /albums/:id/cover returned different data than uploaded")
+	}
+	resp = doConditional(router, "GET", coverPath, "If-None-Match", tag, "", "")
+	if resp.Code != http.StatusNotModified {
+		return fmt.Errorf("GET /albums/:id/cover with If-None-Match expected 304, got %d", resp.Code)
+	}
+
+	// 6. Nieznany rozmiar jest odrzucany
+	resp = doRequest(router, "GET", coverPath+"?size=42", "", "")
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("GET /albums/:id/cover?size=42 expected 400, got %d", resp.Code)
+	}
+	return nil
+}
+
+func doUpload(router http.Handler, path string, data []byte, token string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "cover")
+	part.Write(data)
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", path, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"music-store-api/models"
+)
+
+// getAlbumFacets pobiera listę albumów z facetami dla podanego zapytania
+func getAlbumFacets(router http.Handler, query string) (int64, models.AlbumFacets, error) {
+	var list struct {
+		Total  int64              `json:"total"`
+		Facets models.AlbumFacets `json:"facets"`
+	}
+	resp := doRequest(router, "GET", "/albums?facets=true&"+query, "", "")
+	if resp.Code != http.StatusOK {
+		return 0, list.Facets, fmt.Errorf("GET /albums?%s expected 200, got %d", query, resp.Code)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil {
+		return 0, list.Facets, fmt.Errorf("parsing GET /albums?%s failed: %v", query, err)
+	}
+	return list.Total, list.Facets, nil
+}
+
+// expectFacet sprawdza liczności wybranych wartości facetu
+func expectFacet(name string, counts []models.FacetCount, expected map[string]int64) error {
+	got := map[string]int64{}
+	for _, count := range counts {
+		got[count.Value] = count.Count
+	}
+	for value, count := range expected {
+		if got[value] != count {
+			return fmt.Errorf("facet %s expected %s (%d), got %v", name, value, count, counts)
+		}
+	}
+	return nil
+}
+
+// Testy facetów listy albumów
+func RunFacetTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Albumy o unikalnych gatunkach i wykonawcach
+	albums := []string{
+		`{"title": "Facet 1", "artist": "FacetArtistA", "genre": "FacetRock", "release_date": "1985-05-01T00:00:00Z", "price": 15, "quantity": 1}`,
+		`{"title": "Facet 2", "artist": "FacetArtistA", "genre": "FacetJazz", "release_date": "1995-05-01T00:00:00Z", "price": 25, "quantity": 1}`,
+		`{"title": "Facet 3", "artist": "FacetArtistB", "genre": "FacetRock", "release_date": "1996-05-01T00:00:00Z", "price": 25, "quantity": 1}`,
+	}
+	for _, albumJSON := range albums {
+		id, err := createAlbum(router, albumJSON, token)
+		if err != nil {
+			return err
+		}
+		defer doRequest(router, "DELETE", "/albums/"+id, "", token)
+	}
+
+	// 2. Facet nie uwzględnia filtra własnego wymiaru, a uwzględnia pozostałe
+	total, facets, err := getAlbumFacets(router, "artist=FacetArtistA&genre=FacetRock")
+	if err != nil {
+		return err
+	}
+	if total != 1 {
+		return fmt.Errorf("GET /albums by artist and genre expected total 1, got %d", total)
+	}
+	if err := expectFacet("genre", facets.Genre, map[string]int64{"FacetRock": 1, "FacetJazz": 1}); err != nil {
+		return err
+	}
+	if err := expectFacet("artist", facets.Artist, map[string]int64{"FacetArtistA": 1, "FacetArtistB": 1}); err != nil {
+		return err
+	}
+	if err := expectFacet("decade", facets.Decade, map[string]int64{"1980": 1, "1990": 0}); err != nil {
+		return err
+	}
+	if err := expectFacet("price", facets.Price, map[string]int64{"0-20": 1, "20-30": 0}); err != nil {
+		return err
+	}
+
+	// 3. Wybrana dekada i przedział cenowy nie ukrywają pozostałych wartości swoich facetów
+	total, facets, err = getAlbumFacets(router, "artist=FacetArtist&decade=1990&price_range=20-30")
+	if err != nil {
+		return err
+	}
+	if total != 2 {
+		return fmt.Errorf("GET /albums by decade and price range expected total 2, got %d", total)
+	}
+	if err := expectFacet("decade", facets.Decade, map[string]int64{"1980": 0, "1990": 2}); err != nil {
+		return err
+	}
+	if err := expectFacet("price", facets.Price, map[string]int64{"0-20": 0, "20-30": 2}); err != nil {
+		return err
+	}
+	if err := expectFacet("genre", facets.Genre, map[string]int64{"FacetRock": 1, "FacetJazz": 1}); err != nil {
+		return err
+	}
+	_, facets, err = getAlbumFacets(router, "artist=FacetArtist&genre=FacetRock&price_range=0-20")
+	if err != nil {
+		return err
+	}
+	if err := expectFacet("decade", facets.Decade, map[string]int64{"1980": 1, "1990": 0}); err != nil {
+		return err
+	}
+	if err := expectFacet("price", facets.Price, map[string]int64{"0-20": 1, "20-30": 1}); err != nil {
+		return err
+	}
+
+	return nil
+}
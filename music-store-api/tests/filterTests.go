@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"music-store-api/models"
+)
+
+// albumTotal zwraca liczbę albumów spełniających filtr z zapytania
+func albumTotal(router http.Handler, query string) (int64, error) {
+	resp := doRequest(router, "GET", "/albums?"+query, "", "")
+	var list struct {
+		Total int64 `json:"total"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || resp.Code != http.StatusOK {
+		return 0, fmt.Errorf("GET /albums?%s failed: %d %v", query, resp.Code, err)
+	}
+	return list.Total, nil
+}
+
+// expectParamError sprawdza, że zapytanie jest odrzucane kodem 400 z błędem parametru param
+func expectParamError(router http.Handler, path, param, token string) error {
+	resp := doRequest(router, "GET", path, "", token)
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("GET %s expected 400, got %d", path, resp.Code)
+	}
+	var body models.ValidationErrorResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		return fmt.Errorf("parsing GET %s error failed: %v", path, err)
+	}
+	for _, detail := range body.Details {
+		if detail.Param == param {
+			return nil
+		}
+	}
+	return fmt.Errorf("GET %s expected an error for parameter %s, got %+v", path, param, body.Details)
+}
+
+// Testy filtrów listy albumów i walidacji ich parametrów
+func RunFilterTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Albumy o unikalnych gatunkach, różnych cenach, datach wydania i stanach
+	albums := []string{
+		`{"title": "Filtr 1", "artist": "Filter Test Artist", "genre": "FilterA", "release_date": "1990-06-01T00:00:00Z", "price": 10, "quantity": 1}`,
+		`{"title": "Filtr 2", "artist": "Filter Test Artist", "genre": "FilterB", "release_date": "2005-06-01T00:00:00Z", "price": 25, "quantity": 0}`,
+		`{"title": "Filtr 3", "artist": "Filter Other Artist", "genre": "FilterC", "release_date": "2015-06-01T00:00:00Z", "price": 40, "quantity": 2}`,
+	}
+	for _, albumJSON := range albums {
+		id, err := createAlbum(router, albumJSON, token)
+		if err != nil {
+			return err
+		}
+		defer doRequest(router, "DELETE", "/albums/"+id, "", token)
+	}
+
+	// 2. Filtry zawężają listę; granice zakresów są włączne
+	const scope = "genre=FilterA,FilterB,FilterC"
+	cases := map[string]int64{
+		scope:                                3,
+		"genre=FilterA,filterc":              2,
+		scope + "&min_price=25&max_price=40": 2,
+		scope + "&max_price=24.99":           1,
+		scope + "&released_after=2000-01-01&released_before=2015-06-01":     2,
+		scope + "&released_before=2005-06-01T00:00:00Z":                     2,
+		scope + "&in_stock=true":                                            2,
+		scope + "&in_stock=false":                                           3,
+		"artist=filter%20test%20artist&match=exact":                         2,
+		"artist=Filter%20Test%20Artist,Filter%20Other%20Artist&match=exact": 3,
+		"artist=Filter%20Test&match=exact":                                  0,
+		"artist=Filter%20Test":                                              2,
+		"genre=FilterA,FilterB&in_stock=true&max_price=30":                  1,
+	}
+	for query, expected := range cases {
+		total, err := albumTotal(router, query)
+		if err != nil {
+			return err
+		}
+		if total != expected {
+			return fmt.Errorf("GET /albums?%s expected total %d, got %d", query, expected, total)
+		}
+	}
+
+	// 3. Niepoprawne wartości filtrów dają 400 ze wskazaniem parametru
+	invalid := map[string]string{
+		"min_price=abc":             "min_price",
+		"max_price=-5":              "max_price",
+		"min_price=50&max_price=10": "max_price",
+		"released_after=2020-13-45": "released_after",
+		"released_after=2020-01-01&released_before=2019-01-01": "released_before",
+		"in_stock=maybe":   "in_stock",
+		"match=fuzzy":      "match",
+		"artist_id=nie-id": "artist_id",
+	}
+	for query, param := range invalid {
+		if err := expectParamError(router, "/albums?"+query, param, ""); err != nil {
+			return err
+		}
+	}
+
+	// 4. Wszystkie niepoprawne parametry są zgłaszane naraz
+	for _, param := range []string{"min_price", "in_stock"} {
+		if err := expectParamError(router, "/albums?min_price=abc&in_stock=maybe", param, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
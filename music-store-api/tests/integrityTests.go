@@ -0,0 +1,228 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"music-store-api/controllers"
+)
+
+// Testy reguł spójności przy usuwaniu albumów i użytkowników
+func RunIntegrityTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Użytkownik z zamówieniem i recenzją albumu
+	userJSON := `{"first_name": "Spójność", "last_name": "Test", "email": "integrity.test@example.com",
+    "password": "password12", "role": "customer", "is_active": true}`
+	resp := doRequest(router, "POST", "/users", userJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /users expected 201, got %d", resp.Code)
+	}
+	var createdUser struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdUser); err != nil || createdUser.ID == "" {
+		return fmt.Errorf("parsing created user failed: %v", err)
+	}
+	userPath := "/users/" + createdUser.ID
+	defer doRequest(router, "DELETE", userPath+"?force=true", "", token)
+
+	resp = doRequest(router, "POST", "/login", `{"email": "integrity.test@example.com", "password": "password12"}`, "")
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &login); err != nil || login.Token == "" {
+		return fmt.Errorf("POST /login as created user failed: %d %v", resp.Code, err)
+	}
+
+	albumJSON := `{
+    "title": "Album w zamówieniu",
+    "artist": "Integrity Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 30,
+    "quantity": 5}`
+	resp = doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+	albumPath := "/albums/" + createdAlbum.ID
+	defer doRequest(router, "DELETE", albumPath+"?force=true", "", token)
+
+	orderJSON := `{"items": [{"album_id": "` + createdAlbum.ID + `", "quantity": 1}],
+    "shipping": {"address": "ul. Testowa 1", "city": "Kraków", "postal_code": "30-001", "country": "Polska", "phone_number": "+48100200300"}}`
+	resp = doRequest(router, "POST", "/orders/", orderJSON, login.Token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /orders expected 201, got %d", resp.Code)
+	}
+	var order struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &order); err != nil || order.ID == "" {
+		return fmt.Errorf("parsing created order failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/orders/"+order.ID, "", token)
+
+	reviewJSON := `{"album_id": "` + createdAlbum.ID + `", "user_id": "` + createdUser.ID + `", "rating": 5, "comment": "Świetny"}`
+	resp = doRequest(router, "POST", "/reviews", reviewJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /reviews expected 201, got %d", resp.Code)
+	}
+	var review struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &review); err != nil || review.ID == "" {
+		return fmt.Errorf("parsing created review failed: %v", err)
+	}
+
+	// 2. Otwarte zamówienie blokuje usunięcie albumu i użytkownika
+	for _, path := range []string{albumPath, userPath} {
+		resp = doRequest(router, "DELETE", path, "", token)
+		if resp.Code != http.StatusConflict {
+			return fmt.Errorf("DELETE %s with open order expected 409, got %d", path, resp.Code)
+		}
+		var conflict struct {
+			References []struct {
+				Resource string   `json:"resource"`
+				Count    int      `json:"count"`
+				IDs      []string `json:"ids"`
+			} `json:"references"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &conflict); err != nil {
+			return fmt.Errorf("parsing reference conflict failed: %v", err)
+		}
+		if len(conflict.References) != 1 || conflict.References[0].Resource != "orders" ||
+			conflict.References[0].Count != 1 || conflict.References[0].IDs[0] != order.ID {
+			return fmt.Errorf("DELETE %s expected the open order as blocking reference, got %+v", path, conflict.References)
+		}
+	}
+
+	// 3. Wymuszenie wymaga roli administratora
+	resp = doRequest(router, "DELETE", albumPath+"?force=true", "", login.Token)
+	if resp.Code != http.StatusForbidden {
+		return fmt.Errorf("DELETE /albums/:id?force=true as customer expected 403, got %d", resp.Code)
+	}
+
+	// 4. Po zakończeniu zamówienia użytkownika można przenieść do kosza;
+	// zamówienie i recenzja pozostają bez zmian, więc przywrócenie niczego nie traci
+	resp = doRequest(router, "PATCH", "/orders/"+order.ID+"/status", `{"status": "cancelled"}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /orders/:id/status expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "DELETE", userPath, "", token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /users/:id without open orders expected 200, got %d", resp.Code)
+	}
+	if err := expectOrderShipping(router, order.ID, createdUser.ID, "ul. Testowa 1", token); err != nil {
+		return err
+	}
+	if err := expectReviewUser(router, review.ID, createdUser.ID); err != nil {
+		return err
+	}
+	if resp = doRequest(router, "POST", userPath+"/restore", "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /users/:id/restore expected 200, got %d", resp.Code)
+	}
+
+	// 5. Wymuszone usunięcie z otwartym zamówieniem i trwałe usunięcie z kosza:
+	// wszystkie zamówienia, także otwarte i w koszu, tracą dane wysyłki,
+	// a recenzja w koszu traci powiązanie z kontem
+	resp = doRequest(router, "POST", "/orders/", orderJSON, login.Token)
+	var openOrder struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &openOrder); err != nil || resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /orders expected 201, got %d %v", resp.Code, err)
+	}
+	defer doRequest(router, "DELETE", "/orders/"+openOrder.ID, "", token)
+	resp = doRequest(router, "DELETE", userPath+"?force=true", "", token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /users/:id?force=true expected 200, got %d", resp.Code)
+	}
+	// Zamówienie i recenzja trafiają do kosza po użytkowniku, więc czyszczenie
+	// kosza do chwili purgedBefore usuwa tylko użytkownika
+	time.Sleep(10 * time.Millisecond)
+	purgedBefore := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	for _, path := range []string{"/orders/" + order.ID, "/reviews/" + review.ID} {
+		if resp = doRequest(router, "DELETE", path, "", token); resp.Code != http.StatusOK {
+			return fmt.Errorf("DELETE %s expected 200, got %d", path, resp.Code)
+		}
+	}
+	if err := controllers.PurgeTrash(context.Background(), purgedBefore); err != nil {
+		return fmt.Errorf("purging trash failed: %v", err)
+	}
+	for _, path := range []string{"/orders/" + order.ID, "/reviews/" + review.ID} {
+		if resp = doRequest(router, "POST", path+"/restore", "", token); resp.Code != http.StatusOK {
+			return fmt.Errorf("POST %s/restore expected 200, got %d", path, resp.Code)
+		}
+	}
+	if err := expectOrderShipping(router, order.ID, createdUser.ID, "", token); err != nil {
+		return err
+	}
+	if err := expectOrderShipping(router, openOrder.ID, createdUser.ID, "", token); err != nil {
+		return err
+	}
+	if err := expectReviewUser(router, review.ID, "000000000000000000000000"); err != nil {
+		return err
+	}
+	resp = doRequest(router, "PATCH", "/orders/"+openOrder.ID+"/status", `{"status": "cancelled"}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /orders/:id/status expected 200, got %d", resp.Code)
+	}
+
+	// 6. Usunięcie albumu przenosi jego recenzje do kosza
+	resp = doRequest(router, "DELETE", albumPath, "", token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /albums/:id without open orders expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", "/reviews/"+review.ID, "", ""); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("GET /reviews/:id of deleted album expected 404, got %d", resp.Code)
+	}
+	return nil
+}
+
+// expectOrderShipping sprawdza właściciela i adres wysyłki zamówienia; kraj
+// zostaje zachowany także po anonimizacji
+func expectOrderShipping(router http.Handler, orderID, userID, address, token string) error {
+	resp := doRequest(router, "GET", "/orders/"+orderID, "", token)
+	var order struct {
+		UserID   string `json:"user_id"`
+		Shipping struct {
+			Address     string `json:"address"`
+			PhoneNumber string `json:"phone_number"`
+			Country     string `json:"country"`
+		} `json:"shipping"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &order); err != nil || resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /orders/:id failed: %d %v", resp.Code, err)
+	}
+	if order.UserID != userID || order.Shipping.Address != address ||
+		(address == "") != (order.Shipping.PhoneNumber == "") || order.Shipping.Country != "Polska" {
+		return fmt.Errorf("order %s expected user %s and address %q, got %+v", orderID, userID, address, order)
+	}
+	return nil
+}
+
+// expectReviewUser sprawdza powiązanie recenzji z kontem
+func expectReviewUser(router http.Handler, reviewID, userID string) error {
+	resp := doRequest(router, "GET", "/reviews/"+reviewID, "", "")
+	var review struct {
+		UserID string `json:"user_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &review); err != nil || resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /reviews/:id failed: %d %v", resp.Code, err)
+	}
+	if review.UserID != userID {
+		return fmt.Errorf("review %s expected user_id %s, got %s", reviewID, userID, review.UserID)
+	}
+	return nil
+}
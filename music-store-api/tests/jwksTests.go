@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"music-store-api/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenHeader odczytuje nagłówek tokenu JWT bez sprawdzania podpisu
+func tokenHeader(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	var header map[string]any
+	return header, json.Unmarshal(data, &header)
+}
+
+// generateTokenWith wydaje token przy zmienionych ustawieniach JWT,
+// przywracając je od razu po podpisaniu
+func generateTokenWith(claims *config.Claims, change func(cfg *config.JWTConfig)) (string, error) {
+	previous := config.App.JWT
+	change(&config.App.JWT)
+	defer func() { config.App.JWT = previous }()
+	return config.GenerateJWT(claims.UserID, claims.Role, claims.SessionID)
+}
+
+// Testy publikacji kluczy (JWKS) i weryfikacji podpisu, wystawcy i odbiorcy tokenów
+func RunJWKSTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Zbiór kluczy publicznych
+	resp := doRequest(router, "GET", "/.well-known/jwks.json", "", "")
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /.well-known/jwks.json expected 200, got %d", resp.Code)
+	}
+	var jwks config.JWKSet
+	if err := json.Unmarshal(resp.Body.Bytes(), &jwks); err != nil || jwks.Keys == nil {
+		return fmt.Errorf("parsing JWKS failed: %v", err)
+	}
+
+	// 2. Token podpisany kluczem asymetrycznym wskazuje opublikowany klucz
+	header, err := tokenHeader(token)
+	if err != nil {
+		return fmt.Errorf("parsing token header failed: %v", err)
+	}
+	if kid, ok := header["kid"]; ok {
+		found := false
+		for _, key := range jwks.Keys {
+			if key.Kid == kid && key.Alg == header["alg"] {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("JWKS does not contain key %v (%v) used to sign the token", kid, header["alg"])
+		}
+	} else if header["alg"] != jwt.SigningMethodHS256.Alg() {
+		return fmt.Errorf("token without kid expected HS256, got %v", header["alg"])
+	}
+
+	claims, err := config.ValidateJWT(token)
+	if err != nil {
+		return fmt.Errorf("ValidateJWT rejected a valid token: %v", err)
+	}
+	if claims.Issuer != config.App.JWT.Issuer || len(claims.Audience) != 1 || claims.Audience[0] != config.App.JWT.Audience {
+		return fmt.Errorf("token expected iss %q and aud %q, got %q and %v", config.App.JWT.Issuer, config.App.JWT.Audience, claims.Issuer, claims.Audience)
+	}
+
+	// 3. Token dla innego odbiorcy lub od innego wystawcy jest odrzucany
+	otherAudience, err := generateTokenWith(claims, func(cfg *config.JWTConfig) { cfg.Audience = "other-service" })
+	if err != nil {
+		return err
+	}
+	if resp = doRequest(router, "GET", "/me", "", otherAudience); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me with token for another audience expected 401, got %d", resp.Code)
+	}
+	otherIssuer, err := generateTokenWith(claims, func(cfg *config.JWTConfig) { cfg.Issuer = "other-issuer" })
+	if err != nil {
+		return err
+	}
+	if resp = doRequest(router, "GET", "/me", "", otherIssuer); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me with token from another issuer expected 401, got %d", resp.Code)
+	}
+
+	// 4. Token bez podpisu (alg none) jest odrzucany
+	parts := strings.Split(token, ".")
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + "."
+	if resp = doRequest(router, "GET", "/me", "", unsigned); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me with unsigned token expected 401, got %d", resp.Code)
+	}
+
+	// 5. Token wskazujący nieznany klucz jest odrzucany
+	header["kid"] = "unknown-key"
+	headerJSON, _ := json.Marshal(header)
+	unknownKey := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + parts[1] + "." + parts[2]
+	if resp = doRequest(router, "GET", "/me", "", unknownKey); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me with token for unknown key expected 401, got %d", resp.Code)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"music-store-api/models"
+)
+
+// Pola koperty zwracanej przez każdą listę
+var listEnvelopeFields = []string{"page", "limit", "total", "data", "next_cursor", "prev_cursor"}
+
+// expectListEnvelope sprawdza, że lista path zwraca kopertę stronicowania
+// z tablicą data i zwraca jej zawartość
+func expectListEnvelope(router http.Handler, path, token string) (cursorPage, error) {
+	resp := doRequest(router, "GET", path, "", token)
+	if resp.Code != http.StatusOK {
+		return cursorPage{}, fmt.Errorf("GET %s expected 200, got %d", path, resp.Code)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(resp.Body.Bytes(), &fields); err != nil {
+		return cursorPage{}, fmt.Errorf("parsing GET %s failed: %v", path, err)
+	}
+	for _, field := range listEnvelopeFields {
+		if _, ok := fields[field]; !ok {
+			return cursorPage{}, fmt.Errorf("GET %s response is missing field %s", path, field)
+		}
+	}
+	if data := string(fields["data"]); len(data) == 0 || data[0] != '[' {
+		return cursorPage{}, fmt.Errorf("GET %s expected data to be an array, got %s", path, data)
+	}
+	var page cursorPage
+	if err := json.Unmarshal(resp.Body.Bytes(), &page); err != nil {
+		return page, fmt.Errorf("parsing GET %s failed: %v", path, err)
+	}
+	if page.Page != 1 || len(page.Data) > page.Limit || int64(len(page.Data)) > page.Total {
+		return page, fmt.Errorf("GET %s expected the first page within limit and total, got page %d, limit %d, %d of %d", path, page.Page, page.Limit, len(page.Data), page.Total)
+	}
+	return page, nil
+}
+
+// expectListTotal sprawdza liczbę elementów listy spełniających filtr
+func expectListTotal(router http.Handler, path, token string, expected int64) error {
+	page, err := expectListEnvelope(router, path, token)
+	if err != nil {
+		return err
+	}
+	if page.Total != expected {
+		return fmt.Errorf("GET %s expected total %d, got %d", path, expected, page.Total)
+	}
+	return nil
+}
+
+// Testy wspólnej koperty list i filtrów użytkowników, zamówień i recenzji
+func RunListTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Klient z zamówieniem i recenzją albumu oraz anulowane zamówienie admina
+	customerID, customerToken, err := createCustomer(router, "list.test@example.com", token)
+	if err != nil {
+		return err
+	}
+	defer doRequest(router, "DELETE", "/users/"+customerID+"?force=true", "", token)
+
+	albumID, err := createAlbum(router, `{
+    "title": "Album z listami",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 10,
+    "quantity": 5}`, token)
+	if err != nil {
+		return err
+	}
+	defer doRequest(router, "DELETE", "/albums/"+albumID+"?force=true", "", token)
+
+	customerOrderID, err := placeTestOrder(router, albumID, customerToken)
+	if err != nil {
+		return err
+	}
+	adminOrderID, err := placeTestOrder(router, albumID, token)
+	if err != nil {
+		return err
+	}
+	for _, orderID := range []string{customerOrderID, adminOrderID} {
+		defer doRequest(router, "DELETE", "/orders/"+orderID, "", token)
+		defer doRequest(router, "PATCH", "/orders/"+orderID+"/status", `{"status": "cancelled"}`, token)
+	}
+	if err := setOrderStatus(router, adminOrderID, `{"status": "cancelled"}`, http.StatusOK, token); err != nil {
+		return err
+	}
+	resp := doRequest(router, "GET", "/orders/"+adminOrderID, "", token)
+	var adminOrder models.Order
+	if err := json.Unmarshal(resp.Body.Bytes(), &adminOrder); err != nil || adminOrder.UserID.IsZero() {
+		return fmt.Errorf("GET /orders/:id expected the admin order, got %d %v", resp.Code, err)
+	}
+
+	for userID, rating := range map[string]int{customerID: 2, adminOrder.UserID.Hex(): 5} {
+		reviewJSON := fmt.Sprintf(`{"album_id": %q, "user_id": %q, "rating": %d, "comment": "Test list"}`, albumID, userID, rating)
+		if resp = doRequest(router, "POST", "/reviews", reviewJSON, token); resp.Code != http.StatusCreated {
+			return fmt.Errorf("POST /reviews expected 201, got %d", resp.Code)
+		}
+	}
+
+	// 2. Każda lista zwraca tę samą kopertę
+	lists := []string{"/albums", "/artists", "/users", "/orders/", "/orders/me", "/orders/user/" + customerID,
+		"/reviews", "/reviews/album/" + albumID, "/reviews/user/" + customerID, "/trash/albums"}
+	for _, path := range lists {
+		if _, err := expectListEnvelope(router, path, token); err != nil {
+			return err
+		}
+	}
+
+	// 3. Filtry użytkowników według roli i aktywności
+	page, err := expectListEnvelope(router, "/users?role=customer&is_active=true&limit=100", token)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, user := range page.Data {
+		if user["role"] != "customer" || user["is_active"] != true {
+			return fmt.Errorf("GET /users?role=customer&is_active=true returned %v", user)
+		}
+		found = found || user["id"] == customerID
+	}
+	if !found {
+		return fmt.Errorf("GET /users?role=customer&is_active=true expected the created customer")
+	}
+
+	// 4. Filtry zamówień według statusu i daty utworzenia oraz recenzji według oceny
+	checks := map[string]int64{
+		"/orders/user/" + customerID + "?status=pending":            1,
+		"/orders/user/" + customerID + "?status=cancelled":          0,
+		"/orders/user/" + customerID + "?created_after=2000-01-01":  1,
+		"/orders/user/" + customerID + "?created_before=2000-01-01": 0,
+		"/reviews/album/" + albumID + "?min_rating=4":               1,
+		"/reviews/album/" + albumID + "?max_rating=3":               1,
+		"/reviews/album/" + albumID + "?min_rating=2&max_rating=5":  2,
+		"/reviews/user/" + customerID + "?min_rating=3":             0,
+	}
+	for path, expected := range checks {
+		if err := expectListTotal(router, path, token, expected); err != nil {
+			return err
+		}
+	}
+
+	// 5. Niepoprawne wartości filtrów dają 400 ze wskazaniem parametru
+	invalid := map[string]string{
+		"/users?role=superuser":  "role",
+		"/users?is_active=maybe": "is_active",
+		"/orders/?status=lost":   "status",
+		"/orders/?created_after=2020-02-01&created_before=2020-01-01": "created_before",
+		"/reviews?min_rating=6":              "min_rating",
+		"/reviews?min_rating=4&max_rating=2": "max_rating",
+	}
+	for path, param := range invalid {
+		if err := expectParamError(router, path, param, token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"music-store-api/models"
+)
+
+// loginAs loguje użytkownika i zwraca jego token
+func loginAs(router http.Handler, email, password string) (string, error) {
+	resp := doRequest(router, "POST", "/login", `{"email": "`+email+`", "password": "`+password+`"}`, "")
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &login); err != nil || resp.Code != http.StatusOK || login.Token == "" {
+		return "", fmt.Errorf("POST /login as %s expected 200 with token, got %d %v", email, resp.Code, err)
+	}
+	return login.Token, nil
+}
+
+// createCustomer zakłada aktywnego klienta i zwraca jego ID oraz token
+func createCustomer(router http.Handler, email, token string) (string, string, error) {
+	userJSON := `{"first_name": "Klient", "last_name": "Test", "email": "` + email + `",
+    "password": "password12", "role": "customer", "is_active": true}`
+	resp := doRequest(router, "POST", "/users", userJSON, token)
+	if resp.Code != http.StatusCreated {
+		return "", "", fmt.Errorf("POST /users expected 201, got %d", resp.Code)
+	}
+	var user struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &user); err != nil || user.ID == "" {
+		return "", "", fmt.Errorf("parsing created user failed: %v", err)
+	}
+	customerToken, err := loginAs(router, email, "password12")
+	return user.ID, customerToken, err
+}
+
+// placeTestOrder składa zamówienie na jedną sztukę albumu i zwraca jego ID
+func placeTestOrder(router http.Handler, albumID, token string) (string, error) {
+	resp := doRequest(router, "POST", "/orders/", `{"items": [{"album_id": "`+albumID+`", "quantity": 1}]}`, token)
+	if resp.Code != http.StatusCreated {
+		return "", fmt.Errorf("POST /orders expected 201, got %d", resp.Code)
+	}
+	var order models.Order
+	if err := json.Unmarshal(resp.Body.Bytes(), &order); err != nil || order.ID.IsZero() {
+		return "", fmt.Errorf("parsing created order failed: %v", err)
+	}
+	return order.ID.Hex(), nil
+}
+
+// Testy zamówień klienta pod /orders/me
+func RunMyOrderTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Dwóch klientów; pierwszy ma dwa zamówienia, drugi jedno
+	ownerID, ownerToken, err := createCustomer(router, "my.orders@example.com", token)
+	if err != nil {
+		return err
+	}
+	defer doRequest(router, "DELETE", "/users/"+ownerID+"?force=true", "", token)
+	otherID, otherToken, err := createCustomer(router, "other.orders@example.com", token)
+	if err != nil {
+		return err
+	}
+	defer doRequest(router, "DELETE", "/users/"+otherID+"?force=true", "", token)
+
+	albumID, err := createAlbum(router, `{
+    "title": "Moje zamówienia",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 10,
+    "quantity": 5}`, token)
+	if err != nil {
+		return err
+	}
+	defer doRequest(router, "DELETE", "/albums/"+albumID, "", token)
+
+	var orderIDs []string
+	for _, customerToken := range []string{ownerToken, ownerToken, otherToken} {
+		orderID, err := placeTestOrder(router, albumID, customerToken)
+		if err != nil {
+			return err
+		}
+		orderIDs = append(orderIDs, orderID)
+		defer doRequest(router, "DELETE", "/orders/"+orderID, "", token)
+		defer doRequest(router, "PATCH", "/orders/"+orderID+"/status", `{"status": "cancelled"}`, token)
+	}
+	pendingID, processingID, otherOrderID := orderIDs[0], orderIDs[1], orderIDs[2]
+
+	// 2. Lista zawiera tylko własne zamówienia, a lista wszystkich jest niedostępna
+	page, err := getCursorPage(router, "/orders/me?status=pending", ownerToken)
+	if err != nil {
+		return err
+	}
+	if page.Total != 2 || len(page.Data) != 2 {
+		return fmt.Errorf("GET /orders/me expected 2 own orders, got %d", page.Total)
+	}
+	for _, order := range page.Data {
+		if order["user_id"] != ownerID {
+			return fmt.Errorf("GET /orders/me returned an order of user %v", order["user_id"])
+		}
+	}
+	if resp := doRequest(router, "GET", "/orders/me", "", ""); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /orders/me without token expected 401, got %d", resp.Code)
+	}
+	if resp := doRequest(router, "GET", "/orders/", "", ownerToken); resp.Code != http.StatusForbidden {
+		return fmt.Errorf("GET /orders as customer expected 403, got %d", resp.Code)
+	}
+
+	// 3. Cudze zamówienie jest niewidoczne
+	if resp := doRequest(router, "GET", "/orders/me/"+pendingID, "", ownerToken); resp.Code != http.StatusOK || resp.Header().Get("ETag") == "" {
+		return fmt.Errorf("GET /orders/me/:id expected 200 with ETag, got %d", resp.Code)
+	}
+	if resp := doRequest(router, "GET", "/orders/me/"+otherOrderID, "", ownerToken); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("GET /orders/me/:id of another customer expected 404, got %d", resp.Code)
+	}
+	if resp := doRequest(router, "POST", "/orders/me/"+otherOrderID+"/cancel", "", ownerToken); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("POST /orders/me/:id/cancel of another customer expected 404, got %d", resp.Code)
+	}
+
+	// 4. Anulować można tylko zamówienie pending; sztuka wraca do magazynu
+	if err := expectAlbumQuantity(router, albumID, 2); err != nil {
+		return err
+	}
+	if resp := doRequest(router, "POST", "/orders/me/"+pendingID+"/cancel", "", ownerToken); resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /orders/me/:id/cancel expected 200, got %d", resp.Code)
+	}
+	if err := expectAlbumQuantity(router, albumID, 3); err != nil {
+		return err
+	}
+	if resp := doRequest(router, "POST", "/orders/me/"+pendingID+"/cancel", "", ownerToken); resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /orders/me/:id/cancel of cancelled order expected 409, got %d", resp.Code)
+	}
+	if err := setOrderStatus(router, processingID, `{"status": "processing"}`, http.StatusOK, token); err != nil {
+		return err
+	}
+	if resp := doRequest(router, "POST", "/orders/me/"+processingID+"/cancel", "", ownerToken); resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /orders/me/:id/cancel of processing order expected 409, got %d", resp.Code)
+	}
+	return expectAlbumQuantity(router, albumID, 3)
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"music-store-api/models"
+)
+
+// setOrderStatus zmienia status zamówienia i sprawdza kod odpowiedzi
+func setOrderStatus(router http.Handler, orderID, body string, expected int, token string) error {
+	resp := doRequest(router, "PATCH", "/orders/"+orderID+"/status", body, token)
+	if resp.Code != expected {
+		return fmt.Errorf("PATCH /orders/:id/status %s expected %d, got %d", body, expected, resp.Code)
+	}
+	return nil
+}
+
+// Testy zmian statusu zamówienia i jego historii
+func RunOrderStatusTests(token string) error {
+	router := SetupTestRouter()
+
+	albumID, err := createAlbum(router, `{
+    "title": "Statusy zamówień",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 10,
+    "quantity": 3}`, token)
+	if err != nil {
+		return err
+	}
+	defer doRequest(router, "DELETE", "/albums/"+albumID, "", token)
+
+	// 1. Dwa zamówienia po jednej sztuce; ich właścicielem jest zalogowany administrator
+	var actorID string
+	orderIDs := make([]string, 2)
+	for i := range orderIDs {
+		resp := doRequest(router, "POST", "/orders/", `{"items": [{"album_id": "`+albumID+`", "quantity": 1}]}`, token)
+		if resp.Code != http.StatusCreated {
+			return fmt.Errorf("POST /orders expected 201, got %d", resp.Code)
+		}
+		var order models.Order
+		if err := json.Unmarshal(resp.Body.Bytes(), &order); err != nil || order.ID.IsZero() {
+			return fmt.Errorf("parsing created order failed: %v", err)
+		}
+		orderIDs[i] = order.ID.Hex()
+		actorID = order.UserID.Hex()
+		defer doRequest(router, "DELETE", "/orders/"+orderIDs[i], "", token)
+	}
+	completed, cancelled := orderIDs[0], orderIDs[1]
+
+	// 2. Nieznany status daje 400, a pominięcie etapów 409
+	if err := setOrderStatus(router, completed, `{"status": "lost"}`, http.StatusBadRequest, token); err != nil {
+		return err
+	}
+	for _, status := range []string{"shipped", "completed", "pending"} {
+		if err := setOrderStatus(router, completed, `{"status": "`+status+`"}`, http.StatusConflict, token); err != nil {
+			return err
+		}
+	}
+
+	// 3. Pełna ścieżka realizacji; cofnięcie i anulowanie po wysyłce są odrzucane
+	steps := []struct {
+		body     string
+		expected int
+	}{
+		{`{"status": "processing", "note": "Pakowanie"}`, http.StatusOK},
+		{`{"status": "pending"}`, http.StatusConflict},
+		{`{"status": "shipped"}`, http.StatusOK},
+		{`{"status": "cancelled"}`, http.StatusConflict},
+		{`{"status": "completed"}`, http.StatusOK},
+		{`{"status": "processing"}`, http.StatusConflict},
+		{`{"status": "cancelled"}`, http.StatusConflict},
+	}
+	for _, step := range steps {
+		if err := setOrderStatus(router, completed, step.body, step.expected, token); err != nil {
+			return err
+		}
+	}
+
+	// 4. Historia zawiera każdą zmianę z autorem i notatką
+	resp := doRequest(router, "GET", "/orders/"+completed+"/history", "", token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /orders/:id/history expected 200, got %d", resp.Code)
+	}
+	var history []models.StatusHistory
+	if err := json.Unmarshal(resp.Body.Bytes(), &history); err != nil {
+		return fmt.Errorf("parsing order history failed: %v", err)
+	}
+	expected := []struct{ from, to string }{
+		{"", models.OrderStatusPending},
+		{models.OrderStatusPending, models.OrderStatusProcessing},
+		{models.OrderStatusProcessing, models.OrderStatusShipped},
+		{models.OrderStatusShipped, models.OrderStatusCompleted},
+	}
+	if len(history) != len(expected) {
+		return fmt.Errorf("order history expected %d entries, got %+v", len(expected), history)
+	}
+	for i, entry := range history {
+		if entry.From != expected[i].from || entry.To != expected[i].to || entry.ActorID.Hex() != actorID || entry.ChangedAt.IsZero() {
+			return fmt.Errorf("order history entry %d expected %s→%s by %s, got %+v", i, expected[i].from, expected[i].to, actorID, entry)
+		}
+		if i > 0 && entry.ChangedAt.Before(history[i-1].ChangedAt) {
+			return fmt.Errorf("order history entries out of order: %+v", history)
+		}
+	}
+	if history[1].Note != "Pakowanie" {
+		return fmt.Errorf("order history expected note %q, got %q", "Pakowanie", history[1].Note)
+	}
+	if resp = doRequest(router, "GET", "/orders/000000000000000000000001/history", "", token); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("GET /orders/:id/history of unknown order expected 404, got %d", resp.Code)
+	}
+
+	// 5. Anulowanie przed wysyłką zwraca sztukę do magazynu i jest końcowe
+	if err := expectAlbumQuantity(router, albumID, 1); err != nil {
+		return err
+	}
+	if err := setOrderStatus(router, cancelled, `{"status": "cancelled"}`, http.StatusOK, token); err != nil {
+		return err
+	}
+	if err := expectAlbumQuantity(router, albumID, 2); err != nil {
+		return err
+	}
+	return setOrderStatus(router, cancelled, `{"status": "processing"}`, http.StatusConflict, token)
+}
@@ -0,0 +1,257 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"music-store-api/models"
+)
+
+// Liczba równoległych zamówień na ostatnią sztukę albumu
+const concurrentOrders = 10
+
+// Testy rezerwacji stanu magazynowego przy składaniu i anulowaniu zamówień
+func RunStockReservationTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. POST /albums z jedną sztuką w magazynie
+	albumJSON := `{
+    "title": "Ostatnia sztuka",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 10.50,
+    "quantity": 1}`
+	resp := doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/albums/"+createdAlbum.ID, "", token)
+
+	// 2. Równoległe POST /orders na ostatnią sztukę – dokładnie jedno może się udać
+	orderJSON := `{"items":[{"album_id":"` + createdAlbum.ID + `","quantity":1}]}`
+	var wg sync.WaitGroup
+	codes := make([]int, concurrentOrders)
+	bodies := make([][]byte, concurrentOrders)
+	for i := 0; i < concurrentOrders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp := doRequest(router, "POST", "/orders/", orderJSON, token)
+			codes[i] = resp.Code
+			bodies[i] = resp.Body.Bytes()
+		}(i)
+	}
+	wg.Wait()
+
+	var orderID string
+	created, conflicts := 0, 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+			var order struct {
+				ID    string  `json:"id"`
+				Total float64 `json:"total"`
+			}
+			if err := json.Unmarshal(bodies[i], &order); err != nil {
+				return fmt.Errorf("parsing created order failed: %v", err)
+			}
+			if order.Total != 10.50 {
+				return fmt.Errorf("order total expected 10.50, got %v", order.Total)
+			}
+			orderID = order.ID
+		case http.StatusConflict:
+			conflicts++
+		default:
+			return fmt.Errorf("concurrent POST /orders returned unexpected status %d", code)
+		}
+	}
+	if created != 1 || conflicts != concurrentOrders-1 {
+		return fmt.Errorf("concurrent POST /orders expected 1 created and %d conflicts, got %d and %d", concurrentOrders-1, created, conflicts)
+	}
+	defer doRequest(router, "DELETE", "/orders/"+orderID, "", token)
+
+	// 3. Stan magazynowy po zamówieniu wynosi 0
+	if err := expectAlbumQuantity(router, createdAlbum.ID, 0); err != nil {
+		return err
+	}
+
+	// 4. PUT /orders/:id nie zmienia pozycji, sumy ani stanu magazynowego
+	putJSON := `{"user_id":"000000000000000000000000","total":1,"shipping":{"city":"Kraków"},
+    "items":[{"album_id":"` + createdAlbum.ID + `","quantity":100,"price":0.01}]}`
+	resp = doRequest(router, "PUT", "/orders/"+orderID, putJSON, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PUT /orders/:id expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "GET", "/orders/"+orderID, "", token)
+	var updated struct {
+		UserID string  `json:"user_id"`
+		Total  float64 `json:"total"`
+		Items  []struct {
+			Quantity int `json:"quantity"`
+		} `json:"items"`
+		Shipping struct {
+			City string `json:"city"`
+		} `json:"shipping"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &updated); err != nil || resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /orders/:id expected 200, got %d %v", resp.Code, err)
+	}
+	if updated.Total != 10.50 || len(updated.Items) != 1 || updated.Items[0].Quantity != 1 || updated.UserID == "000000000000000000000000" {
+		return fmt.Errorf("PUT /orders/:id changed items, total or owner: total %v, items %v, user %s", updated.Total, updated.Items, updated.UserID)
+	}
+	if updated.Shipping.City != "Kraków" {
+		return fmt.Errorf("PUT /orders/:id expected shipping city Kraków, got %q", updated.Shipping.City)
+	}
+	if err := expectAlbumQuantity(router, createdAlbum.ID, 0); err != nil {
+		return err
+	}
+
+	// 5. PUT /orders/:id nie przenosi zamówienia do kosza, nie zmienia jego ID i wymaga danych wysyłki
+	resp = doRequest(router, "PUT", "/orders/"+orderID, `{"deleted_at":"2000-01-01T00:00:00Z","shipping":{"city":"Gdańsk"}}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PUT /orders/:id with deleted_at expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", "/orders/"+orderID, "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /orders/:id after PUT with deleted_at expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "PUT", "/orders/"+orderID, `{"id":"000000000000000000000001","shipping":{"city":"Gdańsk"}}`, token)
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("PUT /orders/:id with a different body ID expected 400, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "PUT", "/orders/"+orderID, `{"total":1}`, token); resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("PUT /orders/:id without shipping expected 400, got %d", resp.Code)
+	}
+
+	// 6. Zamówienia w realizacji nie można usunąć – jego sztuki wróciłyby do magazynu dopiero po anulowaniu
+	if resp = doRequest(router, "DELETE", "/orders/"+orderID, "", token); resp.Code != http.StatusConflict {
+		return fmt.Errorf("DELETE /orders/:id of pending order expected 409, got %d", resp.Code)
+	}
+
+	// 7. Anulowanie zamówienia zwraca sztukę do magazynu
+	resp = doRequest(router, "PATCH", "/orders/"+orderID+"/status", `{"status":"cancelled"}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /orders/:id/status expected 200, got %d", resp.Code)
+	}
+	return expectAlbumQuantity(router, createdAlbum.ID, 1)
+}
+
+func expectAlbumQuantity(router http.Handler, albumID string, expected int) error {
+	resp := doRequest(router, "GET", "/albums/"+albumID, "", "")
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /albums/:id expected 200, got %d", resp.Code)
+	}
+	var album struct {
+		Quantity int `json:"quantity"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil {
+		return fmt.Errorf("parsing album failed: %v", err)
+	}
+	if album.Quantity != expected {
+		return fmt.Errorf("album quantity expected %d, got %d", expected, album.Quantity)
+	}
+	return nil
+}
+
+func doRequest(router http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// Testy wyceny zamówienia po stronie serwera
+func RunOrderPricingTests(token string) error {
+	router := SetupTestRouter()
+
+	albumID, err := createAlbum(router, `{
+    "title": "Wyceniany",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 12.50,
+    "quantity": 5}`, token)
+	if err != nil {
+		return err
+	}
+	defer doRequest(router, "DELETE", "/albums/"+albumID, "", token)
+
+	// 1. Cena, suma, status i właściciel z żądania są pomijane, a pozycje tego samego albumu łączone
+	orderJSON := `{"user_id": "000000000000000000000000", "status": "completed", "total": 1,
+    "items": [{"album_id": "` + albumID + `", "quantity": 2, "price": 0.01}, {"album_id": "` + albumID + `", "quantity": 1, "price": 0.01}],
+    "shipping": {"address": "ul. Testowa 1", "city": "Kraków", "postal_code": "30-001", "country": "Polska", "phone_number": "+48100200300"}}`
+	resp := doRequest(router, "POST", "/orders/", orderJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /orders expected 201, got %d", resp.Code)
+	}
+	var order models.Order
+	if err := json.Unmarshal(resp.Body.Bytes(), &order); err != nil || order.ID.IsZero() {
+		return fmt.Errorf("parsing created order failed: %v", err)
+	}
+	orderPath := "/orders/" + order.ID.Hex()
+	defer doRequest(router, "DELETE", orderPath, "", token)
+	defer doRequest(router, "PATCH", orderPath+"/status", `{"status": "cancelled"}`, token)
+
+	if order.Total != 37.50 || order.Status != models.OrderStatusPending || order.UserID.IsZero() {
+		return fmt.Errorf("order expected total 37.50, status pending and owner from the token, got %v, %s and %s", order.Total, order.Status, order.UserID.Hex())
+	}
+	if len(order.Items) != 1 || order.Items[0].Quantity != 3 || order.Items[0].Price != 12.50 {
+		return fmt.Errorf("order expected one line of 3 × 12.50, got %+v", order.Items)
+	}
+	if err := expectAlbumQuantity(router, albumID, 2); err != nil {
+		return err
+	}
+
+	// 2. Nieznany album, ilość niedodatnia i brak pozycji są odrzucane
+	invalid := map[string]string{
+		"unknown album":     `{"items": [{"album_id": "000000000000000000000001", "quantity": 1}]}`,
+		"zero quantity":     `{"items": [{"album_id": "` + albumID + `", "quantity": 0}]}`,
+		"no items":          `{"items": []}`,
+		"missing album ID":  `{"items": [{"quantity": 1}]}`,
+		"negative quantity": `{"items": [{"album_id": "` + albumID + `", "quantity": -1}]}`,
+	}
+	for name, body := range invalid {
+		if resp = doRequest(router, "POST", "/orders/", body, token); resp.Code != http.StatusBadRequest {
+			return fmt.Errorf("POST /orders with %s expected 400, got %d", name, resp.Code)
+		}
+	}
+
+	// 3. Więcej sztuk niż w magazynie daje 409 bez zmiany stanu
+	resp = doRequest(router, "POST", "/orders/", `{"items": [{"album_id": "`+albumID+`", "quantity": 3}]}`, token)
+	if resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /orders above stock expected 409, got %d", resp.Code)
+	}
+	return expectAlbumQuantity(router, albumID, 2)
+}
+
+// createAlbum dodaje album i zwraca jego identyfikator
+func createAlbum(router http.Handler, albumJSON, token string) (string, error) {
+	resp := doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return "", fmt.Errorf("POST /albums expected 201, got %d", resp.Code)
+	}
+	var album struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil || album.ID == "" {
+		return "", fmt.Errorf("parsing created album failed: %v", err)
+	}
+	return album.ID, nil
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Limit stron przeglądanych przez walkCursorPages, chroniący przed zapętleniem kursorów
+const maxWalkPages = 200
+
+// cursorPage to koperta listy z tokenami sąsiednich stron
+type cursorPage struct {
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+	Total      int64            `json:"total"`
+	Data       []map[string]any `json:"data"`
+	NextCursor *string          `json:"next_cursor"`
+	PrevCursor *string          `json:"prev_cursor"`
+}
+
+func getCursorPage(router http.Handler, path, token string) (cursorPage, error) {
+	var page cursorPage
+	resp := doRequest(router, "GET", path, "", token)
+	if resp.Code != http.StatusOK {
+		return page, fmt.Errorf("GET %s expected 200, got %d", path, resp.Code)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &page); err != nil {
+		return page, fmt.Errorf("parsing GET %s failed: %v", path, err)
+	}
+	return page, nil
+}
+
+// withCursor dopisuje token kursora do ścieżki listy
+func withCursor(path, cursor string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "cursor=" + url.QueryEscape(cursor)
+}
+
+// pageIDs zwraca identyfikatory elementów strony (pole idKey)
+func pageIDs(page cursorPage, idKey string) []string {
+	ids := make([]string, 0, len(page.Data))
+	for _, item := range page.Data {
+		id, _ := item[idKey].(string)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// walkCursorPages przegląda listę path kursorem next_cursor do końca, a potem
+// wraca kursorem prev_cursor do początku, sprawdzając, że obie drogi dają te
+// same strony, a lista nie ma powtórzeń ani braków względem total.
+// Zwraca elementy listy w kolejności.
+func walkCursorPages(router http.Handler, path, idKey, token string) ([]map[string]any, error) {
+	first, err := getCursorPage(router, path, token)
+	if err != nil {
+		return nil, err
+	}
+	if first.PrevCursor != nil {
+		return nil, fmt.Errorf("GET %s: first page expected no prev_cursor", path)
+	}
+
+	pages := []cursorPage{first}
+	for pages[len(pages)-1].NextCursor != nil {
+		if len(pages) > maxWalkPages {
+			return nil, fmt.Errorf("GET %s: more than %d pages", path, maxWalkPages)
+		}
+		next, err := getCursorPage(router, withCursor(path, *pages[len(pages)-1].NextCursor), token)
+		if err != nil {
+			return nil, err
+		}
+		if len(next.Data) == 0 {
+			return nil, fmt.Errorf("GET %s: next_cursor led to an empty page", path)
+		}
+		if next.PrevCursor == nil {
+			return nil, fmt.Errorf("GET %s: page %d expected prev_cursor", path, len(pages)+1)
+		}
+		pages = append(pages, next)
+	}
+
+	var items []map[string]any
+	seen := map[string]bool{}
+	for _, page := range pages {
+		for i, id := range pageIDs(page, idKey) {
+			if id == "" || seen[id] {
+				return nil, fmt.Errorf("GET %s: item %q missing or repeated across pages", path, id)
+			}
+			seen[id] = true
+			items = append(items, page.Data[i])
+		}
+	}
+	if int64(len(items)) != first.Total {
+		return nil, fmt.Errorf("GET %s: walked %d items, total is %d", path, len(items), first.Total)
+	}
+
+	for i := len(pages) - 1; i > 0; i-- {
+		back, err := getCursorPage(router, withCursor(path, *pages[i].PrevCursor), token)
+		if err != nil {
+			return nil, err
+		}
+		got, want := pageIDs(back, idKey), pageIDs(pages[i-1], idKey)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			return nil, fmt.Errorf("GET %s: prev_cursor of page %d returned %v, expected %v", path, i+1, got, want)
+		}
+		if (i > 1) != (back.PrevCursor != nil) || back.NextCursor == nil {
+			return nil, fmt.Errorf("GET %s: page %d reached backwards has wrong neighbour cursors", path, i)
+		}
+	}
+	return items, nil
+}
+
+// Testy stronicowania kursorem, sortowania i limitu stron
+func RunPaginationTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Albumy o powtarzających się cenach i wspólnym gatunku
+	prices := []float64{10, 10, 10, 20, 20, 30, 30}
+	for i, price := range prices {
+		albumJSON := fmt.Sprintf(`{"title": "Strona %d", "artist": "Pagination Test Artist", "genre": "PaginationTie",
+    "release_date": "2000-01-01T00:00:00Z", "price": %v, "quantity": 1}`, i, price)
+		id, err := createAlbum(router, albumJSON, token)
+		if err != nil {
+			return err
+		}
+		defer doRequest(router, "DELETE", "/albums/"+id, "", token)
+	}
+
+	// 2. Kursor przechodzi w przód i w tył przez remisy klucza sortowania
+	for _, sort := range []string{"price", "-price", "genre", "-genre,price"} {
+		items, err := walkCursorPages(router, "/albums?genre=PaginationTie&limit=2&sort="+sort, "id", "")
+		if err != nil {
+			return err
+		}
+		if len(items) != len(prices) {
+			return fmt.Errorf("sort=%s expected %d albums, got %d", sort, len(prices), len(items))
+		}
+		for i := 1; i < len(items); i++ {
+			previous, current := items[i-1]["price"].(float64), items[i]["price"].(float64)
+			if ((sort == "price" || sort == "-genre,price") && previous > current) || (sort == "-price" && previous < current) {
+				return fmt.Errorf("sort=%s returned prices out of order: %v before %v", sort, previous, current)
+			}
+		}
+	}
+
+	// 3. Strona wybrana numerem wskazuje poprzednią stronę kursorem
+	first, err := getCursorPage(router, "/albums?genre=PaginationTie&limit=3&sort=price", "")
+	if err != nil {
+		return err
+	}
+	if first.Page != 1 || first.PrevCursor != nil || first.NextCursor == nil {
+		return fmt.Errorf("first page expected page 1 with only next_cursor, got %+v", first)
+	}
+	second, err := getCursorPage(router, "/albums?genre=PaginationTie&limit=3&sort=price&page=2", "")
+	if err != nil {
+		return err
+	}
+	if second.Page != 2 || second.PrevCursor == nil || second.NextCursor == nil {
+		return fmt.Errorf("page 2 expected both cursors, got %+v", second)
+	}
+	back, err := getCursorPage(router, withCursor("/albums?genre=PaginationTie&limit=3", *second.PrevCursor), "")
+	if err != nil {
+		return err
+	}
+	if got, want := pageIDs(back, "id"), pageIDs(first, "id"); strings.Join(got, ",") != strings.Join(want, ",") {
+		return fmt.Errorf("prev_cursor of page 2 returned %v, expected page 1 %v", got, want)
+	}
+
+	// 4. Sortowanie spoza listy dozwolonych pól i niezgodne z kursorem jest odrzucane
+	if err := expectParamError(router, "/albums?sort=description", "sort", ""); err != nil {
+		return err
+	}
+	if err := expectParamError(router, withCursor("/albums?sort=title", *first.NextCursor), "sort", ""); err != nil {
+		return err
+	}
+	if err := expectParamError(router, "/albums?cursor=nieznany", "cursor", ""); err != nil {
+		return err
+	}
+
+	// 5. Limit strony jest ograniczony do 100, a niepoprawny zastępowany domyślnym
+	for query, expected := range map[string]int{"limit=1000": 100, "limit=0": 10, "limit=abc": 10} {
+		page, err := getCursorPage(router, "/albums?"+query, "")
+		if err != nil {
+			return err
+		}
+		if page.Limit != expected || len(page.Data) > expected {
+			return fmt.Errorf("GET /albums?%s expected limit %d, got %d with %d albums", query, expected, page.Limit, len(page.Data))
+		}
+	}
+
+	return nil
+}
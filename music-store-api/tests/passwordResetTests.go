@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Testy resetu hasła
+func RunPasswordResetTests(token string) error {
+	router := SetupTestRouter()
+	recorder, restoreMailer := useMailRecorder()
+	defer restoreMailer()
+
+	// 1. Zalogowany klient
+	email := "reset." + primitive.NewObjectID().Hex() + "@example.com"
+	userJSON := `{"first_name": "Reset", "last_name": "Test", "email": "` + email + `",
+    "password": "password12", "role": "customer", "is_active": true}`
+	resp := doRequest(router, "POST", "/users", userJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /users expected 201, got %d", resp.Code)
+	}
+	var createdUser struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdUser); err != nil || createdUser.ID == "" {
+		return fmt.Errorf("parsing created user failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/users/"+createdUser.ID, "", token)
+
+	oldToken, err := loginAs(router, email, "password12")
+	if err != nil {
+		return err
+	}
+
+	// 2. Odpowiedź nie zdradza, czy konto istnieje
+	unknown := "unknown." + primitive.NewObjectID().Hex() + "@example.com"
+	for _, address := range []string{unknown, email} {
+		resp = doRequest(router, "POST", "/password/forgot", `{"email": "`+address+`"}`, "")
+		if resp.Code != http.StatusAccepted {
+			return fmt.Errorf("POST /password/forgot for %s expected 202, got %d", address, resp.Code)
+		}
+	}
+	if _, sent := recorder.last(unknown); sent {
+		return fmt.Errorf("POST /password/forgot sent a message to an unknown address")
+	}
+	firstToken, err := recorder.mailedToken(email)
+	if err != nil {
+		return err
+	}
+
+	// 3. Kolejna prośba unieważnia wcześniejszy token
+	resp = doRequest(router, "POST", "/password/forgot", `{"email": "`+email+`"}`, "")
+	if resp.Code != http.StatusAccepted {
+		return fmt.Errorf("POST /password/forgot expected 202, got %d", resp.Code)
+	}
+	resetToken, err := recorder.mailedToken(email)
+	if err != nil {
+		return err
+	}
+	for _, body := range []string{
+		`{"token": "` + firstToken + `", "new_password": "password34"}`,
+		`{"token": "invalid", "new_password": "password34"}`,
+		`{"token": "` + resetToken + `", "new_password": "short"}`,
+	} {
+		resp = doRequest(router, "POST", "/password/reset", body, "")
+		if resp.Code != http.StatusBadRequest {
+			return fmt.Errorf("POST /password/reset with %s expected 400, got %d", body, resp.Code)
+		}
+	}
+
+	// 4. Token resetu jest jednorazowy
+	resetJSON := `{"token": "` + resetToken + `", "new_password": "password34"}`
+	resp = doRequest(router, "POST", "/password/reset", resetJSON, "")
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /password/reset expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "POST", "/password/reset", resetJSON, "")
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("POST /password/reset with used token expected 400, got %d", resp.Code)
+	}
+
+	// 5. Tokeny JWT sprzed resetu są odrzucane, a nowe hasło działa
+	resp = doRequest(router, "GET", "/me", "", oldToken)
+	if resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me with token issued before reset expected 401, got %d", resp.Code)
+	}
+	if _, err := loginAs(router, email, "password12"); err == nil {
+		return fmt.Errorf("POST /login with old password succeeded after reset")
+	}
+	newToken, err := loginAs(router, email, "password34")
+	if err != nil {
+		return err
+	}
+	resp = doRequest(router, "GET", "/me", "", newToken)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /me with token issued after reset expected 200, got %d", resp.Code)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// Testy częściowej aktualizacji albumu (JSON Merge Patch i JSON Patch)
+func RunPatchTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. POST /albums z opisem i okładką
+	albumJSON := `{
+    "title": "Przed zmianą",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "description": "Opis do usunięcia",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 19.99,
+    "quantity": 7,
+    "cover_url": "https://example.com/cover.jpg"}`
+	resp := doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/albums/"+createdAlbum.ID, "", token)
+
+	// 2. Merge patch zmienia tylko tytuł, a null usuwa opis
+	resp = doPatch(router, "/albums/"+createdAlbum.ID, "application/merge-patch+json",
+		`{"title": "Po zmianie", "description": null}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /albums/:id (merge patch) expected 200, got %d", resp.Code)
+	}
+	var album struct {
+		Title       string  `json:"title"`
+		Description *string `json:"description"`
+		Price       float64 `json:"price"`
+		Quantity    int     `json:"quantity"`
+		CoverURL    string  `json:"cover_url"`
+	}
+	resp = doRequest(router, "GET", "/albums/"+createdAlbum.ID, "", "")
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil {
+		return fmt.Errorf("parsing album failed: %v", err)
+	}
+	if album.Title != "Po zmianie" || album.Description != nil {
+		return fmt.Errorf("merge patch expected new title and no description, got %q and %v", album.Title, album.Description)
+	}
+	if album.Price != 19.99 || album.Quantity != 7 || album.CoverURL == "" {
+		return fmt.Errorf("merge patch changed fields absent from the body")
+	}
+
+	// 3. Nieudana operacja test w JSON Patch nie zmienia albumu
+	resp = doPatch(router, "/albums/"+createdAlbum.ID, "application/json-patch+json",
+		`[{"op": "test", "path": "/title", "value": "Inny"}, {"op": "replace", "path": "/price", "value": 1}]`, token)
+	if resp.Code != http.StatusConflict {
+		return fmt.Errorf("PATCH /albums/:id (failed test op) expected 409, got %d", resp.Code)
+	}
+
+	// 4. JSON Patch zmienia cenę
+	resp = doPatch(router, "/albums/"+createdAlbum.ID, "application/json-patch+json",
+		`[{"op": "test", "path": "/title", "value": "Po zmianie"}, {"op": "replace", "path": "/price", "value": 9.99}]`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /albums/:id (JSON Patch) expected 200, got %d", resp.Code)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil {
+		return fmt.Errorf("parsing patched album failed: %v", err)
+	}
+	if album.Price != 9.99 || album.Quantity != 7 {
+		return fmt.Errorf("JSON Patch expected price 9.99 and quantity 7, got %v and %d", album.Price, album.Quantity)
+	}
+
+	// 5. Pola tylko do odczytu nie mogą być zmieniane
+	resp = doPatch(router, "/albums/"+createdAlbum.ID, "application/merge-patch+json", `{"created_at": "2001-01-01T00:00:00Z"}`, token)
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("PATCH /albums/:id (read-only field) expected 400, got %d", resp.Code)
+	}
+	return nil
+}
+
+func doPatch(router http.Handler, path, contentType, body, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("PATCH", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
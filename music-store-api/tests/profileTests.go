@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Testy profilu zalogowanego użytkownika (/me)
+func RunProfileTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Klient utworzony przez administratora
+	email := "profile." + primitive.NewObjectID().Hex() + "@example.com"
+	userJSON := `{"first_name": "Profil", "last_name": "Test", "email": "` + email + `",
+    "password": "password12", "role": "customer", "is_active": true}`
+	resp := doRequest(router, "POST", "/users", userJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /users expected 201, got %d", resp.Code)
+	}
+	var createdUser struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdUser); err != nil || createdUser.ID == "" {
+		return fmt.Errorf("parsing created user failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/users/"+createdUser.ID, "", token)
+
+	customerSession, err := startSession(router, email, "password12")
+	if err != nil {
+		return err
+	}
+	customerToken := customerSession.Token
+
+	// 2. GET /me zwraca konto z tokenu
+	resp = doRequest(router, "GET", "/me", "", "")
+	if resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me without auth expected 401, got %d", resp.Code)
+	}
+	resp = doRequest(router, "GET", "/me", "", customerToken)
+	var me struct {
+		ID       string `json:"id"`
+		Email    string `json:"email"`
+		Role     string `json:"role"`
+		Shipping *struct {
+			City string `json:"city"`
+		} `json:"shipping_details"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &me); err != nil || resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /me failed: %d %v", resp.Code, err)
+	}
+	if me.ID != createdUser.ID || me.Email != email || me.Role != "customer" {
+		return fmt.Errorf("GET /me returned wrong account: %+v", me)
+	}
+
+	// 3. PATCH /me zmienia dane profilu, ale nie rolę ani aktywność konta
+	resp = doPatch(router, "/me", "application/merge-patch+json", `{"first_name": "Zmieniony",
+    "shipping_details": {"address": "ul. Nowa 2", "city": "Gdańsk", "postal_code": "80-001", "country": "Polska", "phone_number": "+48500600700"}}`, customerToken)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /me expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "GET", "/me", "", customerToken)
+	if err := json.Unmarshal(resp.Body.Bytes(), &me); err != nil || me.Shipping == nil || me.Shipping.City != "Gdańsk" {
+		return fmt.Errorf("PATCH /me did not save shipping details: %s", resp.Body.String())
+	}
+	for _, body := range []string{`{"role": "admin"}`, `{"is_active": false}`, `{"password": "password34"}`, `{"first_name": null}`} {
+		resp = doPatch(router, "/me", "application/merge-patch+json", body, customerToken)
+		if resp.Code != http.StatusBadRequest {
+			return fmt.Errorf("PATCH /me with %s expected 400, got %d", body, resp.Code)
+		}
+	}
+
+	// 4. Zmiana hasła wymaga obecnego hasła
+	resp = doRequest(router, "POST", "/me/password", `{"current_password": "wrong-password", "new_password": "password34"}`, customerToken)
+	if resp.Code != http.StatusForbidden {
+		return fmt.Errorf("POST /me/password with wrong current password expected 403, got %d", resp.Code)
+	}
+	resp = doRequest(router, "POST", "/me/password", `{"current_password": "password12", "new_password": "short"}`, customerToken)
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("POST /me/password with short password expected 400, got %d", resp.Code)
+	}
+	resp = doRequest(router, "POST", "/me/password", `{"current_password": "password12", "new_password": "password34"}`, customerToken)
+	var changed session
+	if err := json.Unmarshal(resp.Body.Bytes(), &changed); err != nil || resp.Code != http.StatusOK || changed.RefreshToken == "" {
+		return fmt.Errorf("POST /me/password expected 200 with a new session, got %d %v", resp.Code, err)
+	}
+
+	// 5. Zmiana hasła kończy poprzednie sesje; nowa sesja z odpowiedzi działa
+	if resp = doRequest(router, "GET", "/me", "", customerToken); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me with token issued before password change expected 401, got %d", resp.Code)
+	}
+	if code, _ := refreshSession(router, customerSession.RefreshToken); code != http.StatusUnauthorized {
+		return fmt.Errorf("POST /token/refresh issued before password change expected 401, got %d", code)
+	}
+	if resp = doRequest(router, "GET", "/me", "", changed.Token); resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /me with token from password change expected 200, got %d", resp.Code)
+	}
+	if code, _ := refreshSession(router, changed.RefreshToken); code != http.StatusOK {
+		return fmt.Errorf("POST /token/refresh from password change expected 200, got %d", code)
+	}
+	if _, err := loginAs(router, email, "password12"); err == nil {
+		return fmt.Errorf("POST /login with old password succeeded after password change")
+	}
+	if _, err := loginAs(router, email, "password34"); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"music-store-api/controllers"
+	"music-store-api/mailer"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mailRecorder zapamiętuje wysłane wiadomości zamiast je wysyłać
+type mailRecorder struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (r *mailRecorder) Send(ctx context.Context, msg mailer.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+// last zwraca ostatnią wiadomość wysłaną na adres to
+func (r *mailRecorder) last(to string) (mailer.Message, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.messages) - 1; i >= 0; i-- {
+		if r.messages[i].To == to {
+			return r.messages[i], true
+		}
+	}
+	return mailer.Message{}, false
+}
+
+// failingMailer symuluje niedostępny serwer poczty
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	return errors.New("serwer poczty niedostępny")
+}
+
+// useMailRecorder podmienia mailer aplikacji na mailRecorder; zwrócona
+// funkcja przywraca poprzedni mailer
+func useMailRecorder() (*mailRecorder, func()) {
+	recorder := &mailRecorder{}
+	previous := controllers.ActiveMailer()
+	controllers.InitMailer(recorder)
+	return recorder, func() { controllers.InitMailer(previous) }
+}
+
+// Token (token=...) w treści wiadomości
+var mailedTokenPattern = regexp.MustCompile(`token=([^\s&]+)`)
+
+// mailedToken odczytuje token z ostatniej wiadomości wysłanej na adres to
+func (r *mailRecorder) mailedToken(to string) (string, error) {
+	msg, ok := r.last(to)
+	if !ok {
+		return "", fmt.Errorf("no message sent to %s", to)
+	}
+	match := mailedTokenPattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		return "", fmt.Errorf("message to %s contains no token", to)
+	}
+	return url.QueryUnescape(match[1])
+}
+
+// Testy rejestracji klienta i weryfikacji adresu e-mail
+func RunRegistrationTests(token string) error {
+	router := SetupTestRouter()
+	recorder, restoreMailer := useMailRecorder()
+	defer restoreMailer()
+
+	email := "register." + primitive.NewObjectID().Hex() + "@example.com"
+	credentials := `{"email": "` + email + `", "password": "password12"}`
+
+	// 1. POST /register ignoruje rolę i aktywność podane przez użytkownika
+	registerJSON := `{"first_name": "Nowy", "last_name": "Klient", "email": " ` + email + ` ",
+    "password": "password12", "role": "admin", "is_active": true}`
+	resp := doRequest(router, "POST", "/register", registerJSON, "")
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /register expected 201, got %d", resp.Code)
+	}
+	var created struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil || created.ID == "" {
+		return fmt.Errorf("parsing registered user failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/users/"+created.ID, "", token)
+
+	resp = doRequest(router, "GET", "/users/"+created.ID, "", token)
+	var user struct {
+		Role     string `json:"role"`
+		IsActive bool   `json:"is_active"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &user); err != nil || resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /users/:id for registered user failed: %d %v", resp.Code, err)
+	}
+	if user.Role != "customer" || user.IsActive {
+		return fmt.Errorf("registered user expected inactive customer, got role %q, active %v", user.Role, user.IsActive)
+	}
+
+	// 2. Konto nieaktywne nie może się zalogować
+	resp = doRequest(router, "POST", "/login", credentials, "")
+	if resp.Code != http.StatusForbidden {
+		return fmt.Errorf("POST /login before verification expected 403, got %d", resp.Code)
+	}
+
+	// 3. Zajęty adres i niepoprawne dane są odrzucane
+	resp = doRequest(router, "POST", "/register", registerJSON, "")
+	if resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /register with taken email expected 409, got %d", resp.Code)
+	}
+	for _, body := range []string{
+		`{"first_name": "A", "last_name": "B", "email": "Klient <x@example.com>", "password": "password12"}`,
+		`{"first_name": "A", "last_name": "B", "email": "short.password@example.com", "password": "short"}`,
+		`{"first_name": "", "last_name": "B", "email": "no.name@example.com", "password": "password12"}`,
+	} {
+		resp = doRequest(router, "POST", "/register", body, "")
+		if resp.Code != http.StatusBadRequest {
+			return fmt.Errorf("POST /register with %s expected 400, got %d", body, resp.Code)
+		}
+	}
+
+	// 4. Link aktywacyjny jest jednorazowy
+	verificationToken, err := recorder.mailedToken(email)
+	if err != nil {
+		return err
+	}
+	resp = doRequest(router, "GET", "/verify?token=invalid", "", "")
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("GET /verify with invalid token expected 400, got %d", resp.Code)
+	}
+	resp = doRequest(router, "GET", "/verify?token="+url.QueryEscape(verificationToken), "", "")
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /verify expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "GET", "/verify?token="+url.QueryEscape(verificationToken), "", "")
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("GET /verify with used token expected 400, got %d", resp.Code)
+	}
+
+	// 5. Po weryfikacji klient loguje się z rolą customer, także adresem
+	// zapisanym innymi literami
+	resp = doRequest(router, "POST", "/login", credentials, "")
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /login after verification expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "POST", "/login", `{"email": "`+strings.ToUpper(email)+`", "password": "password12"}`, "")
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /login with upper-case email expected 200, got %d", resp.Code)
+	}
+
+	// 6. Adres jest unikalny bez względu na wielkość liter, również przy
+	// tworzeniu konta przez administratora i w koszu
+	userJSON := `{"first_name": "Drugi", "last_name": "Klient", "email": "` + strings.ToUpper(email) + `",
+    "password": "password12", "role": "customer", "is_active": true}`
+	if resp = doRequest(router, "POST", "/users", userJSON, token); resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /users with taken email expected 409, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "DELETE", "/users/"+created.ID, "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /users/:id expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "POST", "/register", registerJSON, ""); resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /register with email of a trashed user expected 409, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "POST", "/users", userJSON, token); resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /users with email of a trashed user expected 409, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "POST", "/users/"+created.ID+"/restore", "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /users/:id/restore expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "POST", "/login", credentials, ""); resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /login after restore expected 200, got %d", resp.Code)
+	}
+
+	// 7. Z równoległych żądań o ten sam adres powstaje dokładnie jedno konto
+	parallelEmail := "parallel." + primitive.NewObjectID().Hex() + "@example.com"
+	parallelJSON := `{"first_name": "Równoległy", "last_name": "Klient", "email": "` + parallelEmail + `",
+    "password": "password12", "role": "customer", "is_active": true}`
+	var wg sync.WaitGroup
+	codes := make([]int, concurrentOrders)
+	bodies := make([][]byte, concurrentOrders)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp := doRequest(router, "POST", "/users", parallelJSON, token)
+			codes[i] = resp.Code
+			bodies[i] = resp.Body.Bytes()
+		}(i)
+	}
+	wg.Wait()
+	createdCount := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			createdCount++
+			var parallel struct {
+				ID string `json:"_id"`
+			}
+			if err := json.Unmarshal(bodies[i], &parallel); err != nil {
+				return fmt.Errorf("parsing created user failed: %v", err)
+			}
+			defer doRequest(router, "DELETE", "/users/"+parallel.ID, "", token)
+		case http.StatusConflict:
+		default:
+			return fmt.Errorf("concurrent POST /users returned unexpected status %d", code)
+		}
+	}
+	if createdCount != 1 {
+		return fmt.Errorf("concurrent POST /users with one email expected 1 created user, got %d", createdCount)
+	}
+
+	// 8. Nieudana wysyłka linku aktywacyjnego nie blokuje adresu przy ponownej rejestracji
+	failedEmail := "failed.mail." + primitive.NewObjectID().Hex() + "@example.com"
+	failedJSON := `{"first_name": "Nowy", "last_name": "Klient", "email": "` + failedEmail + `", "password": "password12"}`
+	controllers.InitMailer(failingMailer{})
+	resp = doRequest(router, "POST", "/register", failedJSON, "")
+	controllers.InitMailer(recorder)
+	if resp.Code != http.StatusInternalServerError {
+		return fmt.Errorf("POST /register with failing mailer expected 500, got %d", resp.Code)
+	}
+	resp = doRequest(router, "POST", "/register", failedJSON, "")
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /register after failed mail expected 201, got %d", resp.Code)
+	}
+	var retried struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &retried); err != nil || retried.ID == "" {
+		return fmt.Errorf("parsing registered user failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/users/"+retried.ID, "", token)
+	return nil
+}
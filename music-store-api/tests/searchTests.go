@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// searchPage jest odpowiedzią wyszukiwania albumów
+type searchPage struct {
+	Query string `json:"query"`
+	Page  int    `json:"page"`
+	Total int64  `json:"total"`
+	Data  []struct {
+		ID    string  `json:"id"`
+		Title string  `json:"title"`
+		Score float64 `json:"score"`
+	} `json:"data"`
+}
+
+func searchAlbums(router http.Handler, query string) (searchPage, error) {
+	var page searchPage
+	resp := doRequest(router, "GET", "/albums/search?"+query, "", "")
+	if resp.Code != http.StatusOK {
+		return page, fmt.Errorf("GET /albums/search?%s expected 200, got %d", query, resp.Code)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &page); err != nil {
+		return page, fmt.Errorf("parsing GET /albums/search?%s failed: %v", query, err)
+	}
+	return page, nil
+}
+
+// titles zwraca tytuły znalezionych albumów w kolejności wyników
+func (p searchPage) titles() []string {
+	titles := make([]string, 0, len(p.Data))
+	for _, album := range p.Data {
+		titles = append(titles, album.Title)
+	}
+	return titles
+}
+
+// Testy wyszukiwania pełnotekstowego albumów
+func RunSearchTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Albumy z szukanym słowem w różnych polach
+	albums := map[string]string{
+		"Zzyzx Łódź": `{"title": "Zzyzx Łódź", "artist": "Search Test Artist", "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z", "price": 10, "quantity": 1}`,
+		"Opisany": `{"title": "Opisany", "artist": "Search Test Artist", "genre": "Test", "description": "Nagrany w zzyzx.",
+    "release_date": "2000-01-01T00:00:00Z", "price": 10, "quantity": 1}`,
+		"Z utworem": `{"title": "Z utworem", "artist": "Search Test Artist", "genre": "Test", "tracks": ["Zzyzx Road"],
+    "release_date": "2000-01-01T00:00:00Z", "price": 10, "quantity": 1}`,
+		"Nocą": `{"title": "Nocą", "artist": "Lodz Search Kwartet", "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z", "price": 10, "quantity": 1}`,
+	}
+	ids := map[string]string{}
+	for title, albumJSON := range albums {
+		id, err := createAlbum(router, albumJSON, token)
+		if err != nil {
+			return err
+		}
+		ids[title] = id
+		defer doRequest(router, "DELETE", "/albums/"+id, "", token)
+	}
+
+	// 2. Wyniki uporządkowane według wagi pola: tytuł, utwory, opis
+	page, err := searchAlbums(router, "q=ZZYZX")
+	if err != nil {
+		return err
+	}
+	expected := []string{"Zzyzx Łódź", "Z utworem", "Opisany"}
+	if page.Total != 3 || !slices.Equal(page.titles(), expected) {
+		return fmt.Errorf("search for zzyzx expected %v, got %v (total %d)", expected, page.titles(), page.Total)
+	}
+	for i, album := range page.Data {
+		if album.Score <= 0 || (i > 0 && album.Score > page.Data[i-1].Score) {
+			return fmt.Errorf("search results expected positive, non-increasing scores, got %+v", page.Data)
+		}
+	}
+
+	// 3. Wyszukiwanie pomija znaki diakrytyczne w zapytaniu i w albumach
+	for _, query := range []string{"Łódź", "lodz"} {
+		page, err := searchAlbums(router, "q="+url.QueryEscape(query))
+		if err != nil {
+			return err
+		}
+		titles := page.titles()
+		slices.Sort(titles)
+		if page.Total != 2 || strings.Join(titles, ",") != "Nocą,Zzyzx Łódź" {
+			return fmt.Errorf("search for %q expected Nocą and Zzyzx Łódź, got %v", query, titles)
+		}
+	}
+
+	// 4. Stronicowanie wyników
+	page, err = searchAlbums(router, "q=zzyzx&limit=1&page=2")
+	if err != nil {
+		return err
+	}
+	if page.Page != 2 || page.Total != 3 || !slices.Equal(page.titles(), []string{"Z utworem"}) {
+		return fmt.Errorf("search page 2 of 1 expected Z utworem, got %v (page %d, total %d)", page.titles(), page.Page, page.Total)
+	}
+
+	// 5. Usunięte albumy nie są wyszukiwane, a zapytanie jest wymagane
+	if resp := doRequest(router, "DELETE", "/albums/"+ids["Opisany"], "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /albums/:id expected 200, got %d", resp.Code)
+	}
+	if page, err = searchAlbums(router, "q=zzyzx"); err != nil {
+		return err
+	}
+	if page.Total != 2 || slices.Contains(page.titles(), "Opisany") {
+		return fmt.Errorf("search expected to skip deleted album, got %v", page.titles())
+	}
+	if resp := doRequest(router, "GET", "/albums/search?q=%20", "", ""); resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("GET /albums/search without query expected 400, got %d", resp.Code)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// session zawiera tokeny zwracane przez /login i /token/refresh
+type session struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// startSession loguje użytkownika i zwraca tokeny jego nowej sesji
+func startSession(router http.Handler, email, password string) (session, error) {
+	var s session
+	resp := doRequest(router, "POST", "/login", `{"email": "`+email+`", "password": "`+password+`"}`, "")
+	if err := json.Unmarshal(resp.Body.Bytes(), &s); err != nil || resp.Code != http.StatusOK || s.RefreshToken == "" {
+		return s, fmt.Errorf("POST /login as %s expected 200 with refresh token, got %d %v", email, resp.Code, err)
+	}
+	return s, nil
+}
+
+// refreshSession wymienia token odświeżania i zwraca kod odpowiedzi oraz nowe tokeny
+func refreshSession(router http.Handler, refreshToken string) (int, session) {
+	var s session
+	resp := doRequest(router, "POST", "/token/refresh", `{"refresh_token": "`+refreshToken+`"}`, "")
+	json.Unmarshal(resp.Body.Bytes(), &s)
+	return resp.Code, s
+}
+
+// Testy odświeżania sesji, wykrywania ponownego użycia i wylogowania
+func RunSessionTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. Klient z sesją
+	email := "session." + primitive.NewObjectID().Hex() + "@example.com"
+	userJSON := `{"first_name": "Sesja", "last_name": "Test", "email": "` + email + `",
+    "password": "password12", "role": "customer", "is_active": true}`
+	resp := doRequest(router, "POST", "/users", userJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /users expected 201, got %d", resp.Code)
+	}
+	var createdUser struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdUser); err != nil || createdUser.ID == "" {
+		return fmt.Errorf("parsing created user failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/users/"+createdUser.ID, "", token)
+
+	first, err := startSession(router, email, "password12")
+	if err != nil {
+		return err
+	}
+
+	// 2. Odświeżenie wymienia oba tokeny
+	code, second := refreshSession(router, first.RefreshToken)
+	if code != http.StatusOK || second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		return fmt.Errorf("POST /token/refresh expected 200 with a new refresh token, got %d", code)
+	}
+	if resp = doRequest(router, "GET", "/me", "", second.Token); resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /me with refreshed token expected 200, got %d", resp.Code)
+	}
+
+	// 3. Ponowne użycie wymienionego tokenu unieważnia całą sesję
+	if code, _ = refreshSession(router, first.RefreshToken); code != http.StatusUnauthorized {
+		return fmt.Errorf("POST /token/refresh with reused token expected 401, got %d", code)
+	}
+	if code, _ = refreshSession(router, second.RefreshToken); code != http.StatusUnauthorized {
+		return fmt.Errorf("POST /token/refresh after reuse detection expected 401, got %d", code)
+	}
+
+	// 4. Wylogowanie unieważnia token dostępu i sesję
+	current, err := startSession(router, email, "password12")
+	if err != nil {
+		return err
+	}
+	if resp = doRequest(router, "POST", "/logout", "", current.Token); resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /logout expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", "/me", "", current.Token); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me after logout expected 401, got %d", resp.Code)
+	}
+	if code, _ = refreshSession(router, current.RefreshToken); code != http.StatusUnauthorized {
+		return fmt.Errorf("POST /token/refresh after logout expected 401, got %d", code)
+	}
+
+	// 5. Zmiana roli unieważnia tokeny sesji; nowe logowanie daje token z nową rolą
+	current, err = startSession(router, email, "password12")
+	if err != nil {
+		return err
+	}
+	resp = doPatch(router, "/users/"+createdUser.ID, "application/merge-patch+json", `{"role": "employee"}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /users/:id role expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", "/me", "", current.Token); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me with token of previous role expected 401, got %d", resp.Code)
+	}
+	if code, _ = refreshSession(router, current.RefreshToken); code != http.StatusUnauthorized {
+		return fmt.Errorf("POST /token/refresh after role change expected 401, got %d", code)
+	}
+	if current, err = startSession(router, email, "password12"); err != nil {
+		return err
+	}
+	resp = doRequest(router, "GET", "/me", "", current.Token)
+	var me struct {
+		Role string `json:"role"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &me); err != nil || resp.Code != http.StatusOK || me.Role != "employee" {
+		return fmt.Errorf("GET /me after new login expected role employee, got %d %q", resp.Code, me.Role)
+	}
+
+	// 6. Dezaktywacja konta unieważnia token dostępu i tokeny odświeżania
+	resp = doPatch(router, "/users/"+createdUser.ID, "application/merge-patch+json", `{"is_active": false}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /users/:id is_active expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", "/me", "", current.Token); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me after deactivation expected 401, got %d", resp.Code)
+	}
+	if code, _ = refreshSession(router, current.RefreshToken); code != http.StatusUnauthorized {
+		return fmt.Errorf("POST /token/refresh after deactivation expected 401, got %d", code)
+	}
+	resp = doPatch(router, "/users/"+createdUser.ID, "application/merge-patch+json", `{"is_active": true}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /users/:id is_active expected 200, got %d", resp.Code)
+	}
+
+	// 7. Zmiana hasła przez administratora unieważnia sesję
+	if current, err = startSession(router, email, "password12"); err != nil {
+		return err
+	}
+	resp = doPatch(router, "/users/"+createdUser.ID, "application/merge-patch+json", `{"password": "password13"}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /users/:id password expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", "/me", "", current.Token); resp.Code != http.StatusUnauthorized {
+		return fmt.Errorf("GET /me after password change expected 401, got %d", resp.Code)
+	}
+	if code, _ = refreshSession(router, current.RefreshToken); code != http.StatusUnauthorized {
+		return fmt.Errorf("POST /token/refresh after password change expected 401, got %d", code)
+	}
+	if current, err = startSession(router, email, "password13"); err != nil {
+		return err
+	}
+
+	// 8. Wylogowanie ze wszystkich urządzeń kończy pozostałe sesje
+	other, err := startSession(router, email, "password13")
+	if err != nil {
+		return err
+	}
+	if resp = doRequest(router, "POST", "/logout-all", "", current.Token); resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /logout-all expected 200, got %d", resp.Code)
+	}
+	for _, s := range []session{current, other} {
+		if resp = doRequest(router, "GET", "/me", "", s.Token); resp.Code != http.StatusUnauthorized {
+			return fmt.Errorf("GET /me after logout-all expected 401, got %d", resp.Code)
+		}
+		if code, _ = refreshSession(router, s.RefreshToken); code != http.StatusUnauthorized {
+			return fmt.Errorf("POST /token/refresh after logout-all expected 401, got %d", code)
+		}
+	}
+	if _, err := startSession(router, email, "password13"); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Testy repozytoriów: nieudany import nie może usunąć zapisanych danych
+func RunStoreTests(token string) error {
+	return expectAtomicReplaceAll(store.NewMemoryStores())
+}
+
+// expectAtomicReplaceAll sprawdza, że ReplaceAll zakończony błędem
+// pozostawia zapisane albumy bez zmian
+func expectAtomicReplaceAll(stores store.Stores) error {
+	ctx := context.Background()
+
+	album := models.Album{ID: primitive.NewObjectID(), Title: "Zapisany", Artist: "Test Artist", Price: 10}
+	if err := stores.Albums.Create(ctx, album); err != nil {
+		return err
+	}
+
+	duplicateID := primitive.NewObjectID()
+	imports := map[string][]models.Album{
+		"duplicate IDs": {
+			{ID: duplicateID, Title: "Import 1", Artist: "Test Artist"},
+			{ID: duplicateID, Title: "Import 2", Artist: "Test Artist"},
+		},
+		"duplicate SKUs": {
+			{ID: primitive.NewObjectID(), Title: "Import 1", Artist: "Test Artist", Variants: []models.Variant{{ID: primitive.NewObjectID(), Format: models.FormatCD, SKU: "IMPORT-SKU"}}},
+			{ID: primitive.NewObjectID(), Title: "Import 2", Artist: "Test Artist", Variants: []models.Variant{{ID: primitive.NewObjectID(), Format: models.FormatCD, SKU: "IMPORT-SKU"}}},
+		},
+	}
+	for name, albums := range imports {
+		if err := stores.Albums.ReplaceAll(ctx, albums); err == nil {
+			return fmt.Errorf("ReplaceAll with %s expected an error", name)
+		}
+		if _, err := stores.Albums.GetByID(ctx, album.ID); err != nil {
+			return fmt.Errorf("album expected to survive a failed ReplaceAll with %s, got %v", name, err)
+		}
+		for _, imported := range albums {
+			if _, err := stores.Albums.GetByID(ctx, imported.ID); err == nil {
+				return fmt.Errorf("failed ReplaceAll with %s expected to store nothing, found %s", name, imported.Title)
+			}
+		}
+	}
+	return nil
+}
@@ -28,6 +28,174 @@ func RunTestsHandler(c *gin.Context) {
 		return
 	}
 
+	// Testy repozytoriów
+	err = RunStoreTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy rezerwacji stanu magazynowego
+	err = RunStockReservationTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy wyceny zamówień
+	err = RunOrderPricingTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy zmian statusu zamówień
+	err = RunOrderStatusTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy zamówień klienta
+	err = RunMyOrderTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy koszyka
+	err = RunCartTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy stronicowania i sortowania list
+	err = RunPaginationTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy koperty i filtrów list
+	err = RunListTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy częściowej aktualizacji
+	err = RunPatchTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy wersjonowania i żądań warunkowych
+	err = RunVersionTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy listy utworów
+	err = RunTrackTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy wykonawców
+	err = RunArtistTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy wersji albumów
+	err = RunVariantTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy okładek albumów
+	err = RunCoverTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy wyszukiwania albumów
+	err = RunSearchTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy filtrów listy albumów
+	err = RunFilterTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy facetów listy albumów
+	err = RunFacetTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy kosza
+	err = RunTrashTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy reguł spójności przy usuwaniu
+	err = RunIntegrityTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy rejestracji i weryfikacji adresu e-mail
+	err = RunRegistrationTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy profilu zalogowanego użytkownika
+	err = RunProfileTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy resetu hasła
+	err = RunPasswordResetTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy odświeżania sesji i wylogowania
+	err = RunSessionTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Testy kluczy publicznych i weryfikacji tokenów
+	err = RunJWKSTests(token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"result": "success"})
 }
 
@@ -226,16 +394,39 @@ func SetupTestRouter() *gin.Engine {
 	r := gin.Default()
 
 	r.GET("/run-tests", RunTestsHandler)
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS)
 	r.POST("/login", controllers.Login)
+	r.POST("/register", controllers.Register)
+	r.GET("/verify", controllers.VerifyEmail)
+	r.POST("/password/forgot", controllers.ForgotPassword)
+	r.POST("/password/reset", controllers.ResetPassword)
+	r.POST("/token/refresh", controllers.RefreshToken)
+	r.POST("/logout", middleware.AuthMiddleware(), controllers.Logout)
+	r.POST("/logout-all", middleware.AuthMiddleware(), controllers.LogoutAll)
 
 	albumRoutes := r.Group("/albums")
 	albumRoutes.GET("", controllers.GetAlbums)
+	albumRoutes.GET("/search", controllers.SearchAlbums)
 	albumRoutes.GET("/:id", controllers.GetAlbumByID)
+	albumRoutes.GET("/:id/cover", controllers.GetAlbumCover)
 	albumRoutes.Use(middleware.AuthMiddleware())
 	{
 		albumRoutes.POST("", middleware.RoleMiddleware("employee", "admin"), controllers.CreateAlbum)
 		albumRoutes.PATCH("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateAlbum)
+		albumRoutes.POST("/:id/cover", middleware.RoleMiddleware("employee", "admin"), controllers.UploadAlbumCover)
 		albumRoutes.DELETE("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.DeleteAlbum)
+		albumRoutes.POST("/:id/restore", middleware.RoleMiddleware("admin"), controllers.RestoreAlbum)
+	}
+
+	artistRoutes := r.Group("/artists")
+	artistRoutes.GET("", controllers.GetArtists)
+	artistRoutes.GET("/:id", controllers.GetArtistByID)
+	artistRoutes.GET("/:id/albums", controllers.GetArtistAlbums)
+	artistRoutes.Use(middleware.AuthMiddleware())
+	{
+		artistRoutes.POST("", middleware.RoleMiddleware("employee", "admin"), controllers.CreateArtist)
+		artistRoutes.PATCH("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateArtist)
+		artistRoutes.DELETE("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.DeleteArtist)
 	}
 
 	userRoutes := r.Group("/users")
@@ -246,6 +437,59 @@ func SetupTestRouter() *gin.Engine {
 		userRoutes.POST("", middleware.RoleMiddleware("admin"), controllers.CreateUser)
 		userRoutes.PATCH("/:id", middleware.RoleMiddleware("admin"), controllers.UpdateUser)
 		userRoutes.DELETE("/:id", middleware.RoleMiddleware("admin"), controllers.DeleteUser)
+		userRoutes.POST("/:id/restore", middleware.RoleMiddleware("admin"), controllers.RestoreUser)
+	}
+
+	meRoutes := r.Group("/me")
+	meRoutes.Use(middleware.AuthMiddleware())
+	{
+		meRoutes.GET("", controllers.GetMe)
+		meRoutes.PATCH("", controllers.UpdateMe)
+		meRoutes.POST("/password", controllers.ChangeMyPassword)
+	}
+
+	orderRoutes := r.Group("/orders")
+	orderRoutes.Use(middleware.AuthMiddleware())
+	{
+		orderRoutes.GET("/", middleware.RoleMiddleware("employee", "admin"), controllers.GetOrders)
+		orderRoutes.GET("/me", controllers.GetMyOrders)
+		orderRoutes.GET("/me/:id", controllers.GetMyOrderByID)
+		orderRoutes.GET("/user/:userID", middleware.RoleMiddleware("employee", "admin"), controllers.GetOrdersByUserID)
+		orderRoutes.POST("/me/:id/cancel", controllers.CancelMyOrder)
+		orderRoutes.GET("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.GetOrderByID)
+		orderRoutes.PUT("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateOrder)
+		orderRoutes.POST("/", middleware.RoleMiddleware("customer", "employee", "admin"), controllers.CreateOrder)
+		orderRoutes.DELETE("/:id", middleware.RoleMiddleware("admin"), controllers.DeleteOrder)
+		orderRoutes.POST("/:id/restore", middleware.RoleMiddleware("admin"), controllers.RestoreOrder)
+		orderRoutes.PATCH("/:id/status", middleware.RoleMiddleware("employee", "admin"), controllers.UpdateOrderStatus)
+		orderRoutes.GET("/:id/history", middleware.RoleMiddleware("employee", "admin"), controllers.GetOrderHistory)
+	}
+
+	cartRoutes := r.Group("/cart")
+	cartRoutes.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("customer", "employee", "admin"))
+	{
+		cartRoutes.GET("", controllers.GetCart)
+		cartRoutes.POST("/items", controllers.AddCartItem)
+		cartRoutes.PUT("/items/:albumID", controllers.UpdateCartItem)
+		cartRoutes.POST("/checkout", controllers.Checkout)
+	}
+
+	reviewRoutes := r.Group("/reviews")
+	reviewRoutes.GET("/album/:albumID", controllers.GetReviewsByAlbumID)
+	reviewRoutes.GET("/user/:userID", controllers.GetReviewsByUserID)
+	reviewRoutes.GET("/:id", controllers.GetReviewByID)
+	reviewRoutes.GET("", controllers.GetReviews)
+	reviewRoutes.Use(middleware.AuthMiddleware())
+	{
+		reviewRoutes.POST("", middleware.RoleMiddleware("customer", "employee", "admin"), controllers.CreateReview)
+		reviewRoutes.DELETE("/:id", middleware.RoleMiddleware("employee", "admin"), controllers.DeleteReview)
+		reviewRoutes.POST("/:id/restore", middleware.RoleMiddleware("admin"), controllers.RestoreReview)
+	}
+
+	trashRoutes := r.Group("/trash")
+	trashRoutes.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("admin"))
+	{
+		trashRoutes.GET("/:resource", controllers.GetTrash)
 	}
 
 	return r
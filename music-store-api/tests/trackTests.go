@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Testy listy utworów albumu (nowy format i dawna lista tytułów)
+func RunTrackTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. POST /albums z dawną listą tytułów
+	albumJSON := `{
+    "title": "Stary format",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "tracks": ["Pierwszy", "Drugi"],
+    "price": 15,
+    "quantity": 3}`
+	resp := doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums with legacy tracks expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/albums/"+createdAlbum.ID, "", token)
+
+	// 2. Tytuły zamieniane są na utwory z kolejnymi pozycjami na pierwszej płycie
+	var album struct {
+		Tracks []struct {
+			Position int    `json:"position"`
+			Disc     int    `json:"disc"`
+			Title    string `json:"title"`
+		} `json:"tracks"`
+		TotalDuration int `json:"total_duration"`
+	}
+	resp = doRequest(router, "GET", "/albums/"+createdAlbum.ID, "", "")
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil {
+		return fmt.Errorf("parsing album failed: %v", err)
+	}
+	if len(album.Tracks) != 2 || album.Tracks[1].Title != "Drugi" || album.Tracks[1].Position != 2 || album.Tracks[1].Disc != 1 {
+		return fmt.Errorf("legacy tracks were not upgraded: %+v", album.Tracks)
+	}
+
+	// 3. Zmiana utworów przelicza łączny czas trwania
+	resp = doPatch(router, "/albums/"+createdAlbum.ID, "application/merge-patch+json",
+		`{"tracks": [{"title": "Pierwszy", "duration": 200}, {"title": "Drugi", "disc": 2, "duration": 95, "explicit": true}]}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /albums/:id (tracks) expected 200, got %d", resp.Code)
+	}
+	resp = doRequest(router, "GET", "/albums/"+createdAlbum.ID, "", "")
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil {
+		return fmt.Errorf("parsing album failed: %v", err)
+	}
+	if album.TotalDuration != 295 || album.Tracks[1].Disc != 2 || album.Tracks[1].Position != 1 {
+		return fmt.Errorf("expected total_duration 295 and second track on disc 2, got %d and %+v", album.TotalDuration, album.Tracks)
+	}
+
+	// 4. Powtórzona pozycja na płycie jest odrzucana
+	resp = doPatch(router, "/albums/"+createdAlbum.ID, "application/merge-patch+json",
+		`{"tracks": [{"title": "A", "position": 1}, {"title": "B", "position": 1}]}`, token)
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("PATCH /albums/:id (duplicate position) expected 400, got %d", resp.Code)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"music-store-api/controllers"
+)
+
+// purgeTrash trwale usuwa całą zawartość kosza, tak jak okresowe czyszczenie
+// po upływie trash.retention_days
+func purgeTrash() error {
+	return controllers.PurgeTrash(context.Background(), time.Now())
+}
+
+// Testy miękkiego usuwania, kosza i przywracania
+func RunTrashTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. POST /albums
+	albumJSON := `{
+    "title": "Album do kosza",
+    "artist": "Trash Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 20,
+    "quantity": 2}`
+	resp := doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+	albumPath := "/albums/" + createdAlbum.ID
+
+	// 2. Usunięty album znika z katalogu i trafia do kosza
+	resp = doRequest(router, "DELETE", albumPath, "", token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /albums/:id expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", albumPath, "", ""); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("GET /albums/:id of trashed album expected 404, got %d", resp.Code)
+	}
+	if total, err := albumTotal(router, "artist=Trash+Test+Artist"); err != nil || total != 0 {
+		return fmt.Errorf("GET /albums expected no trashed albums, got %d (%v)", total, err)
+	}
+	if resp = doRequest(router, "DELETE", albumPath, "", token); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("DELETE /albums/:id of trashed album expected 404, got %d", resp.Code)
+	}
+	if found, err := inTrash(router, "albums", createdAlbum.ID, token); err != nil || !found {
+		return fmt.Errorf("GET /trash/albums expected the deleted album (%v)", err)
+	}
+
+	// 3. Przywrócony album wraca do katalogu i znika z kosza
+	if resp = doRequest(router, "POST", albumPath+"/restore", "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /albums/:id/restore expected 200, got %d", resp.Code)
+	}
+	defer doRequest(router, "DELETE", albumPath, "", token)
+	if resp = doRequest(router, "GET", albumPath, "", ""); resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /albums/:id of restored album expected 200, got %d", resp.Code)
+	}
+	if found, err := inTrash(router, "albums", createdAlbum.ID, token); err != nil || found {
+		return fmt.Errorf("GET /trash/albums expected no restored album (%v)", err)
+	}
+	if resp = doRequest(router, "POST", albumPath+"/restore", "", token); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("POST /albums/:id/restore of album outside trash expected 404, got %d", resp.Code)
+	}
+
+	// 4. Usunięty użytkownik jest niewidoczny do czasu przywrócenia
+	userJSON := `{"first_name": "Kosz", "last_name": "Test", "email": "trash.test@example.com",
+    "password": "password12", "role": "customer", "is_active": true}`
+	resp = doRequest(router, "POST", "/users", userJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /users expected 201, got %d", resp.Code)
+	}
+	var createdUser struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdUser); err != nil || createdUser.ID == "" {
+		return fmt.Errorf("parsing created user failed: %v", err)
+	}
+	userPath := "/users/" + createdUser.ID
+	if resp = doRequest(router, "DELETE", userPath, "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /users/:id expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", userPath, "", token); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("GET /users/:id of trashed user expected 404, got %d", resp.Code)
+	}
+	if found, err := inTrash(router, "users", createdUser.ID, token); err != nil || !found {
+		return fmt.Errorf("GET /trash/users expected the deleted user (%v)", err)
+	}
+	if resp = doRequest(router, "POST", userPath+"/restore", "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("POST /users/:id/restore expected 200, got %d", resp.Code)
+	}
+	if resp = doRequest(router, "GET", userPath, "", token); resp.Code != http.StatusOK {
+		return fmt.Errorf("GET /users/:id of restored user expected 200, got %d", resp.Code)
+	}
+	doRequest(router, "DELETE", userPath, "", token)
+
+	// 5. Nieznany zasób kosza
+	if resp = doRequest(router, "GET", "/trash/artists", "", token); resp.Code != http.StatusNotFound {
+		return fmt.Errorf("GET /trash/artists expected 404, got %d", resp.Code)
+	}
+	return nil
+}
+
+// inTrash sprawdza, czy dokument o podanym ID jest w koszu zasobu
+func inTrash(router http.Handler, resource, id, token string) (bool, error) {
+	resp := doRequest(router, "GET", "/trash/"+resource+"?sort=-deleted_at&limit=100", "", token)
+	var list struct {
+		Data []struct {
+			ID        string  `json:"id"`
+			DeletedAt *string `json:"deleted_at"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || resp.Code != http.StatusOK {
+		return false, fmt.Errorf("GET /trash/%s failed: %d %v", resource, resp.Code, err)
+	}
+	for _, doc := range list.Data {
+		if doc.ID == id && doc.DeletedAt != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
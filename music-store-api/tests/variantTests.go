@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Testy wersji albumu (formatów) w katalogu i zamówieniach
+func RunVariantTests(token string) error {
+	router := SetupTestRouter()
+	// Usunięte albumy w koszu nadal rezerwują swoje kody SKU, więc każde
+	// uruchomienie testów używa nowego kodu
+	sku := "TEST-LP-" + strings.ToUpper(primitive.NewObjectID().Hex())
+
+	// 1. POST /albums z wersją winylową i CD
+	albumJSON := `{
+    "title": "Dwa formaty",
+    "artist": "Variant Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "variants": [
+      {"format": "vinyl", "sku": "` + strings.ToLower(sku) + `", "ean": "590-1234-123457", "price": 89.99, "stock": 2, "weight": 300},
+      {"format": "CD", "price": 39.99, "stock": 5, "weight": 100}
+    ]}`
+	resp := doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums with variants expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/albums/"+createdAlbum.ID, "", token)
+
+	// 2. Cena albumu to najniższa cena wersji, a liczba sztuk – suma stanów
+	album, err := getVariantAlbum(router, createdAlbum.ID)
+	if err != nil {
+		return err
+	}
+	if album.Price != 39.99 || album.Quantity != 7 || len(album.Variants) != 2 {
+		return fmt.Errorf("expected price 39.99, quantity 7 and 2 variants, got %v, %d and %d", album.Price, album.Quantity, len(album.Variants))
+	}
+	vinyl := album.Variants[0]
+	if vinyl.SKU != sku || vinyl.EAN != "5901234123457" || album.Variants[1].Format != "cd" || album.Variants[1].SKU == "" {
+		return fmt.Errorf("variants were not normalized: %+v", album.Variants)
+	}
+
+	// 3. Filtr format z in_stock
+	for query, expected := range map[string]int64{
+		"format=vinyl&in_stock=true": 1,
+		"format=cassette,digital":    0,
+	} {
+		resp = doRequest(router, "GET", "/albums?artist=Variant+Test+Artist&"+query, "", "")
+		var list struct {
+			Total int64 `json:"total"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || resp.Code != http.StatusOK {
+			return fmt.Errorf("GET /albums?%s failed: %d %v", query, resp.Code, err)
+		}
+		if list.Total != expected {
+			return fmt.Errorf("GET /albums?%s expected %d albums, got %d", query, expected, list.Total)
+		}
+	}
+	resp = doRequest(router, "GET", "/albums?format=minidisc", "", "")
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("GET /albums?format=minidisc expected 400, got %d", resp.Code)
+	}
+
+	// 4. Zamówienie albumu z kilkoma wersjami wymaga variant_id
+	resp = doRequest(router, "POST", "/orders/", `{"items":[{"album_id":"`+createdAlbum.ID+`","quantity":1}]}`, token)
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("POST /orders without variant_id expected 400, got %d", resp.Code)
+	}
+
+	// 5. Zamówienie wersji winylowej zmniejsza tylko jej stan
+	orderJSON := `{"items":[{"album_id":"` + createdAlbum.ID + `","variant_id":"` + vinyl.ID + `","quantity":2}]}`
+	resp = doRequest(router, "POST", "/orders/", orderJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /orders with variant_id expected 201, got %d", resp.Code)
+	}
+	var order struct {
+		ID    string `json:"id"`
+		Items []struct {
+			VariantID string  `json:"variant_id"`
+			SKU       string  `json:"sku"`
+			Price     float64 `json:"price"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &order); err != nil || len(order.Items) != 1 {
+		return fmt.Errorf("parsing created order failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/orders/"+order.ID, "", token)
+	if item := order.Items[0]; item.VariantID != vinyl.ID || item.SKU != sku || item.Price != 89.99 {
+		return fmt.Errorf("order item does not reference the vinyl variant: %+v", item)
+	}
+	if err := expectAlbumQuantity(router, createdAlbum.ID, 5); err != nil {
+		return err
+	}
+	resp = doRequest(router, "POST", "/orders/", orderJSON, token)
+	if resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /orders for sold out variant expected 409, got %d", resp.Code)
+	}
+
+	// 6. Anulowanie zwraca sztuki do wersji winylowej
+	resp = doRequest(router, "PATCH", "/orders/"+order.ID+"/status", `{"status":"cancelled"}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /orders/:id/status expected 200, got %d", resp.Code)
+	}
+	album, err = getVariantAlbum(router, createdAlbum.ID)
+	if err != nil {
+		return err
+	}
+	if album.Variants[0].Stock != 2 || album.Quantity != 7 {
+		return fmt.Errorf("expected vinyl stock 2 and quantity 7 after cancellation, got %d and %d", album.Variants[0].Stock, album.Quantity)
+	}
+
+	// 7. Kod SKU innego albumu, niepoprawny EAN i cena albumu z kilkoma wersjami są odrzucane
+	resp = doRequest(router, "POST", "/albums", `{"title": "Kopia", "artist": "Variant Test Artist", "genre": "Test",
+    "variants": [{"format": "vinyl", "sku": "`+sku+`", "price": 10, "stock": 1}]}`, token)
+	if resp.Code != http.StatusConflict {
+		return fmt.Errorf("POST /albums with duplicate SKU expected 409, got %d", resp.Code)
+	}
+	resp = doPatch(router, "/albums/"+createdAlbum.ID, "application/json-patch+json",
+		`[{"op": "replace", "path": "/variants/0/ean", "value": "5901234123458"}]`, token)
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("PATCH /albums/:id with invalid EAN expected 400, got %d", resp.Code)
+	}
+	resp = doPatch(router, "/albums/"+createdAlbum.ID, "application/merge-patch+json", `{"price": 9.99}`, token)
+	if resp.Code != http.StatusBadRequest {
+		return fmt.Errorf("PATCH /albums/:id price with several variants expected 400, got %d", resp.Code)
+	}
+	return nil
+}
+
+type variantAlbum struct {
+	Price    float64 `json:"price"`
+	Quantity int     `json:"quantity"`
+	Variants []struct {
+		ID     string `json:"id"`
+		Format string `json:"format"`
+		SKU    string `json:"sku"`
+		EAN    string `json:"ean"`
+		Stock  int    `json:"stock"`
+	} `json:"variants"`
+}
+
+func getVariantAlbum(router http.Handler, albumID string) (variantAlbum, error) {
+	var album variantAlbum
+	resp := doRequest(router, "GET", "/albums/"+albumID, "", "")
+	if resp.Code != http.StatusOK {
+		return album, fmt.Errorf("GET /albums/:id expected 200, got %d", resp.Code)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil {
+		return album, fmt.Errorf("parsing album failed: %v", err)
+	}
+	return album, nil
+}
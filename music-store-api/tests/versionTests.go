@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"music-store-api/models"
+	"music-store-api/store"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Testy wersjonowania albumu (ETag, If-None-Match, If-Match)
+func RunVersionTests(token string) error {
+	router := SetupTestRouter()
+
+	// 1. POST /albums
+	albumJSON := `{
+    "title": "Wersjonowany",
+    "artist": "Test Artist",
+    "genre": "Test",
+    "release_date": "2000-01-01T00:00:00Z",
+    "price": 15,
+    "quantity": 3}`
+	resp := doRequest(router, "POST", "/albums", albumJSON, token)
+	if resp.Code != http.StatusCreated {
+		return fmt.Errorf("POST /albums expected 201, got %d", resp.Code)
+	}
+	var createdAlbum struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &createdAlbum); err != nil || createdAlbum.ID == "" {
+		return fmt.Errorf("parsing created album failed: %v", err)
+	}
+	defer doRequest(router, "DELETE", "/albums/"+createdAlbum.ID, "", token)
+
+	// 2. GET zwraca ETag, a warunkowy GET z tym znacznikiem zwraca 304
+	resp = doRequest(router, "GET", "/albums/"+createdAlbum.ID, "", "")
+	tag := resp.Header().Get("ETag")
+	if resp.Code != http.StatusOK || tag == "" {
+		return fmt.Errorf("GET /albums/:id expected 200 with ETag, got %d and %q", resp.Code, tag)
+	}
+	resp = doConditional(router, "GET", "/albums/"+createdAlbum.ID, "If-None-Match", tag, "", "")
+	if resp.Code != http.StatusNotModified {
+		return fmt.Errorf("GET /albums/:id with If-None-Match expected 304, got %d", resp.Code)
+	}
+
+	// 3. Pierwsza zmiana z aktualnym ETag się udaje i zmienia ETag
+	resp = doConditional(router, "PATCH", "/albums/"+createdAlbum.ID, "If-Match", tag, `{"price": 16}`, token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("PATCH /albums/:id with current If-Match expected 200, got %d", resp.Code)
+	}
+	if resp.Header().Get("ETag") == tag {
+		return fmt.Errorf("PATCH /albums/:id expected a new ETag, got %q again", tag)
+	}
+
+	// 4. Druga zmiana z nieaktualnym ETag jest odrzucana
+	resp = doConditional(router, "PATCH", "/albums/"+createdAlbum.ID, "If-Match", tag, `{"price": 17}`, token)
+	if resp.Code != http.StatusPreconditionFailed {
+		return fmt.Errorf("PATCH /albums/:id with stale If-Match expected 412, got %d", resp.Code)
+	}
+	if err := expectAlbumPrice(router, createdAlbum.ID, 16); err != nil {
+		return err
+	}
+
+	// 5. Usunięcie z nieaktualnym ETag jest odrzucane, a z aktualnym się udaje
+	resp = doConditional(router, "DELETE", "/albums/"+createdAlbum.ID, "If-Match", tag, "", token)
+	if resp.Code != http.StatusPreconditionFailed {
+		return fmt.Errorf("DELETE /albums/:id with stale If-Match expected 412, got %d", resp.Code)
+	}
+	tag = doRequest(router, "GET", "/albums/"+createdAlbum.ID, "", "").Header().Get("ETag")
+	resp = doConditional(router, "DELETE", "/albums/"+createdAlbum.ID, "If-Match", tag, "", token)
+	if resp.Code != http.StatusOK {
+		return fmt.Errorf("DELETE /albums/:id with current If-Match expected 200, got %d", resp.Code)
+	}
+
+	// 6. Repozytorium nie usuwa dokumentu zmienionego po odczycie wersji
+	if err := expectVersionedDeletes(store.NewMemoryStores()); err != nil {
+		return err
+	}
+
+	// 7. Zmiana statusu warunkowa na wersji nie nadpisuje równoległej aktualizacji
+	return expectVersionedTransitions(store.NewMemoryStores())
+}
+
+// expectVersionedTransitions sprawdza, że TransitionStatus z wersją inną niż
+// bieżąca zwraca ErrConflict i nie zmienia statusu, a z bieżącą wersją go zmienia
+func expectVersionedTransitions(stores store.Stores) error {
+	ctx := context.Background()
+
+	order := models.Order{ID: primitive.NewObjectID(), Status: models.OrderStatusPending}
+	if err := stores.Orders.Place(ctx, order); err != nil {
+		return err
+	}
+	if err := stores.Orders.Update(ctx, order.ID, order.Version, bson.M{"shipping": models.ShippingDetails{City: "Kraków"}}); err != nil {
+		return err
+	}
+	change := models.StatusHistory{From: models.OrderStatusPending, To: models.OrderStatusProcessing}
+	if err := stores.Orders.TransitionStatus(ctx, order.ID, order.Version, change); !errors.Is(err, store.ErrConflict) {
+		return fmt.Errorf("changing order status with stale version expected ErrConflict, got %v", err)
+	}
+	current, err := stores.Orders.GetByID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if current.Status != models.OrderStatusPending {
+		return fmt.Errorf("order status expected to stay pending after a conflicting change, got %s", current.Status)
+	}
+	if err := stores.Orders.TransitionStatus(ctx, order.ID, current.Version, change); err != nil {
+		return fmt.Errorf("changing order status with current version failed: %v", err)
+	}
+	return nil
+}
+
+// expectVersionedDeletes sprawdza, że Delete z wersją inną niż bieżąca zwraca
+// ErrConflict i nie usuwa dokumentu, a z bieżącą wersją go usuwa
+func expectVersionedDeletes(stores store.Stores) error {
+	ctx := context.Background()
+
+	album := models.Album{ID: primitive.NewObjectID(), Title: "Wersjonowany", Artist: "Test Artist", Price: 15}
+	if err := stores.Albums.Create(ctx, album); err != nil {
+		return err
+	}
+	if err := stores.Albums.Update(ctx, album.ID, album.Version, bson.M{"price": 16}); err != nil {
+		return err
+	}
+	if err := stores.Albums.Delete(ctx, album.ID, album.Version); !errors.Is(err, store.ErrConflict) {
+		return fmt.Errorf("deleting album with stale version expected ErrConflict, got %v", err)
+	}
+	if err := stores.Albums.Delete(ctx, album.ID, album.Version+1); err != nil {
+		return fmt.Errorf("deleting album with current version failed: %v", err)
+	}
+
+	artist := models.Artist{ID: primitive.NewObjectID(), Name: "Wersjonowany wykonawca"}
+	if err := stores.Artists.Create(ctx, artist); err != nil {
+		return err
+	}
+	if err := stores.Artists.Delete(ctx, artist.ID, artist.Version+1); !errors.Is(err, store.ErrConflict) {
+		return fmt.Errorf("deleting artist with stale version expected ErrConflict, got %v", err)
+	}
+	if _, err := stores.Artists.GetByID(ctx, artist.ID); err != nil {
+		return fmt.Errorf("artist expected to survive a conflicting delete, got %v", err)
+	}
+	if err := stores.Artists.Delete(ctx, artist.ID, artist.Version); err != nil {
+		return fmt.Errorf("deleting artist with current version failed: %v", err)
+	}
+	return nil
+}
+
+func expectAlbumPrice(router http.Handler, albumID string, expected float64) error {
+	resp := doRequest(router, "GET", "/albums/"+albumID, "", "")
+	var album struct {
+		Price float64 `json:"price"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &album); err != nil {
+		return fmt.Errorf("parsing album failed: %v", err)
+	}
+	if album.Price != expected {
+		return fmt.Errorf("album price expected %v, got %v", expected, album.Price)
+	}
+	return nil
+}
+
+func doConditional(router http.Handler, method, path, header, tag, body, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(header, tag)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}